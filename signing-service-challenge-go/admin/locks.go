@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+)
+
+type ListLocksOutputDto struct {
+	Items []LockOutputDto `json:"items"`
+}
+
+type LockOutputDto struct {
+	// DeviceId is the device whose signature counter is locked
+	DeviceId  string    `json:"device_id"`
+	HeldSince time.Time `json:"held_since"`
+	HeldMs    float64   `json:"held_ms"`
+	Waiters   int       `json:"waiters"`
+}
+
+// Locks lists the held device locks with their waiters, the longest held first.
+func (s *Server) Locks(response http.ResponseWriter, request *http.Request) {
+	held := s.locks.Held()
+	now := time.Now()
+
+	out := ListLocksOutputDto{Items: make([]LockOutputDto, len(held))}
+	for i, lock := range held {
+		out.Items[i] = LockOutputDto{
+			DeviceId:  lock.ID.String(),
+			HeldSince: lock.Since,
+			HeldMs:    milliseconds(now.Sub(lock.Since)),
+			Waiters:   lock.Waiters,
+		}
+	}
+	api.WriteAPIResponse(response, http.StatusOK, out)
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+)
+
+type LogLevelDto struct {
+	Level string `json:"level"`
+}
+
+func (d LogLevelDto) Validate() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(d.Level)); err != nil {
+		return validation.Field("/level", errors.New("must be debug, info, warn or error"))
+	}
+	return nil
+}
+
+// GetLogLevel reports the minimum level of log lines.
+func (s *Server) GetLogLevel(response http.ResponseWriter, request *http.Request) {
+	api.WriteAPIResponse(response, http.StatusOK, LogLevelDto{Level: strings.ToLower(s.logLevel.Level().String())})
+}
+
+// PutLogLevel changes the minimum level of log lines until the process exits, e.g. to debug a running instance.
+func (s *Server) PutLogLevel(response http.ResponseWriter, request *http.Request) {
+	in, ok := api.ParseBody[LogLevelDto](response, request)
+	if !ok {
+		return
+	}
+
+	var level slog.Level
+	level.UnmarshalText([]byte(in.Level))
+	previous := s.logLevel.Level()
+	s.logLevel.Set(level)
+	domain.LoggerFromContext(request.Context()).Warn("log level changed", "previous", previous, "level", level)
+
+	api.WriteAPIResponse(response, http.StatusOK, LogLevelDto{Level: strings.ToLower(level.String())})
+}
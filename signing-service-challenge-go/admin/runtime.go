@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+)
+
+type RuntimeOutputDto struct {
+	GoVersion     string           `json:"go_version"`
+	UptimeSeconds float64          `json:"uptime_seconds"`
+	Goroutines    int              `json:"goroutines"`
+	GOMAXPROCS    int              `json:"gomaxprocs"`
+	CPUs          int              `json:"cpus"`
+	Memory        MemoryOutputDto  `json:"memory"`
+	GC            GCStatsOutputDto `json:"gc"`
+}
+
+// MemoryOutputDto holds the memory statistics in bytes
+type MemoryOutputDto struct {
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+	StackInuse  uint64 `json:"stack_inuse"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+}
+
+type GCStatsOutputDto struct {
+	Cycles       uint32  `json:"cycles"`
+	PauseTotalMs float64 `json:"pause_total_ms"`
+	// LastPauseMs is the pause of the latest cycle
+	LastPauseMs float64 `json:"last_pause_ms"`
+	// NextHeap is the heap size in bytes of the next cycle
+	NextHeap    uint64  `json:"next_heap"`
+	CPUFraction float64 `json:"cpu_fraction"`
+}
+
+// Runtime reports the goroutines, memory and garbage collection of the process.
+func (s *Server) Runtime(response http.ResponseWriter, request *http.Request) {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	api.WriteAPIResponse(response, http.StatusOK, RuntimeOutputDto{
+		GoVersion:     runtime.Version(),
+		UptimeSeconds: time.Since(s.started).Seconds(),
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		CPUs:          runtime.NumCPU(),
+		Memory: MemoryOutputDto{
+			HeapAlloc:   memory.HeapAlloc,
+			HeapInuse:   memory.HeapInuse,
+			HeapObjects: memory.HeapObjects,
+			StackInuse:  memory.StackInuse,
+			TotalAlloc:  memory.TotalAlloc,
+			Sys:         memory.Sys,
+		},
+		GC: GCStatsOutputDto{
+			Cycles:       memory.NumGC,
+			PauseTotalMs: milliseconds(time.Duration(memory.PauseTotalNs)),
+			LastPauseMs:  milliseconds(time.Duration(memory.PauseNs[(memory.NumGC+255)%256])),
+			NextHeap:     memory.NextGC,
+			CPUFraction:  memory.GCCPUFraction,
+		},
+	})
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,180 @@
+// Package admin serves diagnostics of the service on a separate listener, which shouldn't be reachable by clients:
+// profiles, runtime statistics, the log level, the held locks, the storage statistics and the metrics.
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// readHeaderTimeout bounds slow clients, there is no write timeout as CPU profiles and traces take a while
+	readHeaderTimeout = 5 * time.Second
+	// shutdownTimeout bounds waiting for running profiles on shutdown
+	shutdownTimeout = 5 * time.Second
+)
+
+// Server serves the admin endpoints, all of them require the admin credentials as HTTP basic authentication,
+// so `go tool pprof http://admin:<password>@<address>/debug/pprof/profile` works.
+type Server struct {
+	username string
+	password string
+	started  time.Time
+	logLevel *slog.LevelVar
+	locks    lock.Inspector[uuid.UUID]
+	storage  persistence.Storage
+	metrics  http.Handler
+}
+
+// Option configures the endpoints of the Server, endpoints without their dependency aren't served.
+type Option func(*Server)
+
+// WithLogLevel allows reading and changing the log level at runtime.
+func WithLogLevel(level *slog.LevelVar) Option {
+	return func(s *Server) {
+		s.logLevel = level
+	}
+}
+
+// WithLocks lists the held device locks and their waiters.
+func WithLocks(locks lock.Inspector[uuid.UUID]) Option {
+	return func(s *Server) {
+		s.locks = locks
+	}
+}
+
+// WithStorage reports the statistics of the storage.
+func WithStorage(storage persistence.Storage) Option {
+	return func(s *Server) {
+		s.storage = storage
+	}
+}
+
+// WithMetrics serves the metrics on /metrics, Prometheus scrapes them with the admin credentials.
+func WithMetrics(metrics http.Handler) Option {
+	return func(s *Server) {
+		s.metrics = metrics
+	}
+}
+
+// NewServer creates the admin server protected by the credentials.
+func NewServer(username string, password string, options ...Option) *Server {
+	s := &Server{
+		username: username,
+		password: password,
+		started:  time.Now(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Handler returns the HTTP handler with all admin routes.
+func (s *Server) Handler() http.Handler {
+	mux := chi.NewMux()
+	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		api.WriteErrorResponse(w, r, http.StatusNotFound, "route not found")
+	})
+
+	mux.Use(api.RequestID)
+	mux.Use(api.AccessLog)
+	mux.Use(s.Authenticate)
+
+	// Profiles of the process, named profiles like heap or goroutine are served by the index
+	mux.Get("/debug/pprof/*", pprof.Index)
+	mux.Get("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.Get("/debug/pprof/profile", pprof.Profile)
+	mux.Get("/debug/pprof/symbol", pprof.Symbol)
+	mux.Post("/debug/pprof/symbol", pprof.Symbol)
+	mux.Get("/debug/pprof/trace", pprof.Trace)
+
+	mux.Get("/admin/runtime", s.Runtime)
+	if s.logLevel != nil {
+		mux.Get("/admin/log-level", s.GetLogLevel)
+		mux.Put("/admin/log-level", s.PutLogLevel)
+	}
+	if s.locks != nil {
+		mux.Get("/admin/locks", s.Locks)
+	}
+	if s.storage != nil {
+		mux.Get("/admin/storage", s.Storage)
+	}
+	if s.metrics != nil {
+		mux.Get("/metrics", s.metrics.ServeHTTP)
+	}
+	return mux
+}
+
+// Authenticate is a middleware requiring the admin credentials as HTTP basic authentication.
+func (s *Server) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || !equal(username, s.username) || !equal(password, s.password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+			api.WriteErrorResponse(w, r, http.StatusUnauthorized, "admin credentials required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// equal compares in constant time, the hashes hide the length of the credentials as well
+func equal(given string, expected string) bool {
+	givenHash := sha256.Sum256([]byte(given))
+	expectedHash := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(givenHash[:], expectedHash[:]) == 1
+}
+
+// Run listens on the address and serves the admin endpoints until the context is canceled.
+func (s *Server) Run(ctx context.Context, listenAddress string) error {
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves the admin endpoints on the listener until the context is canceled, running profiles are awaited shortly.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	slog.Info("admin server listening", "port", listener.Addr().String())
+
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
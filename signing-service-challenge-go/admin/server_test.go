@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testUsername = "admin"
+	testPassword = "secret"
+)
+
+// TypedResponse wraps API responses with a data field
+type TypedResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+// request sends an authenticated request and decodes the response into out
+func request(assert *require.Assertions, handler http.Handler, method string, path string, body string, out any) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.SetBasicAuth(testUsername, testPassword)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if out != nil {
+		assert.NoError(json.Unmarshal(response.Body.Bytes(), out), response.Body.String())
+	}
+	return response
+}
+
+func TestAuthenticate(t *testing.T) {
+	assert := require.New(t)
+	handler := NewServer(testUsername, testPassword).Handler()
+
+	// Test case 1: Requests without the exact credentials are rejected
+	for _, credentials := range [][]string{nil, {testUsername, "wrong"}, {"root", testPassword}} {
+		request := httptest.NewRequest(http.MethodGet, "/admin/runtime", nil)
+		if credentials != nil {
+			request.SetBasicAuth(credentials[0], credentials[1])
+		}
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		assert.Equal(http.StatusUnauthorized, response.Code)
+		assert.Contains(response.Header().Get("WWW-Authenticate"), "Basic")
+	}
+
+	// Test case 2: Profiles require the credentials as well
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/debug/pprof/heap", nil))
+	assert.Equal(http.StatusUnauthorized, response.Code)
+}
+
+func TestRuntime(t *testing.T) {
+	assert := require.New(t)
+	handler := NewServer(testUsername, testPassword).Handler()
+
+	var out TypedResponse[RuntimeOutputDto]
+	response := request(assert, handler, http.MethodGet, "/admin/runtime", "", &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Positive(out.Data.Goroutines)
+	assert.Positive(out.Data.GOMAXPROCS)
+	assert.Positive(out.Data.Memory.HeapAlloc)
+	assert.True(strings.HasPrefix(out.Data.GoVersion, "go"))
+
+	// Test case 2: Profiles are served
+	response = request(assert, handler, http.MethodGet, "/debug/pprof/goroutine?debug=1", "", nil)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Contains(response.Body.String(), "goroutine profile")
+
+	// Test case 3: Endpoints without dependencies aren't served
+	response = request(assert, handler, http.MethodGet, "/admin/locks", "", nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestLogLevel(t *testing.T) {
+	assert := require.New(t)
+	level := new(slog.LevelVar)
+	handler := NewServer(testUsername, testPassword, WithLogLevel(level)).Handler()
+
+	var out TypedResponse[LogLevelDto]
+	response := request(assert, handler, http.MethodGet, "/admin/log-level", "", &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("info", out.Data.Level)
+
+	// Test case 2: The level is changed at runtime
+	response = request(assert, handler, http.MethodPut, "/admin/log-level", `{"level":"debug"}`, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("debug", out.Data.Level)
+	assert.Equal(slog.LevelDebug, level.Level())
+
+	// Test case 3: Unknown levels are rejected
+	response = request(assert, handler, http.MethodPut, "/admin/log-level", `{"level":"verbose"}`, nil)
+	assert.Equal(http.StatusBadRequest, response.Code)
+	assert.Equal(slog.LevelDebug, level.Level())
+}
+
+func TestLocks(t *testing.T) {
+	assert := require.New(t)
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	handler := NewServer(testUsername, testPassword, WithLocks(locker.(lock.Inspector[uuid.UUID]))).Handler()
+
+	deviceId := uuid.New()
+	held, err := locker.Acquire(context.Background(), deviceId)
+	assert.NoError(err)
+
+	// a second caller waits for the lock until it is released
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error)
+	go func() {
+		_, err := locker.Acquire(ctx, deviceId)
+		waiting <- err
+	}()
+
+	var out TypedResponse[ListLocksOutputDto]
+	assert.Eventually(func() bool {
+		request(assert, handler, http.MethodGet, "/admin/locks", "", &out)
+		return len(out.Data.Items) == 1 && out.Data.Items[0].Waiters == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(deviceId.String(), out.Data.Items[0].DeviceId)
+	assert.GreaterOrEqual(out.Data.Items[0].HeldMs, 0.0)
+
+	// Test case 2: Waiters giving up and released locks are removed
+	cancel()
+	assert.ErrorIs(<-waiting, context.Canceled)
+	held.Unlock()
+	out = TypedResponse[ListLocksOutputDto]{}
+	request(assert, handler, http.MethodGet, "/admin/locks", "", &out)
+	assert.Empty(out.Data.Items)
+}
+
+func TestMetrics(t *testing.T) {
+	assert := require.New(t)
+	handler := NewServer(testUsername, testPassword, WithMetrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("signing_service_devices 1\n"))
+	}))).Handler()
+
+	// Test case 1: The metrics require the credentials
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(http.StatusUnauthorized, response.Code)
+
+	response = request(assert, handler, http.MethodGet, "/metrics", "", nil)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Contains(response.Body.String(), "signing_service_devices 1")
+}
+
+func TestStorage(t *testing.T) {
+	assert := require.New(t)
+	storage := persistence.NewMemoryStorage()
+	handler := NewServer(testUsername, testPassword, WithStorage(persistence.Instrument(storage, func(ctx context.Context, _ string, _ string) (context.Context, func(error)) {
+		return ctx, func(error) {}
+	}))).Handler()
+
+	ctx := domain.WithOrganization(context.Background(), domain.DefaultOrganizationId)
+	deviceId := uuid.New()
+	assert.NoError(storage.Devices().Create(ctx, &domain.Device{Id: deviceId, OrganizationId: domain.DefaultOrganizationId, SigningAlgorithm: domain.SigningAlgorithmEcc}))
+	for counter := range 3 {
+		assert.NoError(storage.Signatures().Create(ctx, &domain.Signature{DeviceId: deviceId, Counter: counter + 1}))
+	}
+
+	var out TypedResponse[StorageOutputDto]
+	response := request(assert, handler, http.MethodGet, "/admin/storage", "", &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("memory", out.Data.Backend)
+	assert.Equal(1, out.Data.Records["devices"])
+	assert.Equal(3, out.Data.Records["signatures"])
+	// the default organization always exists
+	assert.Equal(1, out.Data.Records["organizations"])
+}
@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+type StorageOutputDto struct {
+	Backend string         `json:"backend"`
+	Records map[string]int `json:"records"`
+}
+
+// Storage reports the number of records of each repository.
+func (s *Server) Storage(response http.ResponseWriter, request *http.Request) {
+	stats, err := s.storage.Stats(request.Context())
+	if err != nil {
+		domain.LoggerFromContext(request.Context()).Error("reading storage stats", "error", err)
+		api.WriteInternalError(response, request)
+		return
+	}
+	api.WriteAPIResponse(response, http.StatusOK, StorageOutputDto{Backend: stats.Backend, Records: stats.Records})
+}
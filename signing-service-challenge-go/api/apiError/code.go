@@ -0,0 +1,88 @@
+package apiError
+
+import "net/http"
+
+// Code identifies the kind of an error, clients should rely on it instead of the messages
+type Code string
+
+const (
+	CodeInvalidRequest        Code = "invalid_request"
+	CodeValidationFailed      Code = "validation_failed"
+	CodeUnauthorized          Code = "unauthorized"
+	CodeForbidden             Code = "forbidden"
+	CodeNotFound              Code = "not_found"
+	CodeMethodNotAllowed      Code = "method_not_allowed"
+	CodeConflict              Code = "conflict"
+	CodePayloadTooLarge       Code = "payload_too_large"
+	CodeUnprocessable         Code = "unprocessable"
+	CodeRateLimited           Code = "rate_limited"
+	CodeInternal              Code = "internal_error"
+	CodeUnavailable           Code = "service_unavailable"
+	CodeDeviceNotFound        Code = "device_not_found"
+	CodeDeviceConflict        Code = "device_conflict"
+	CodeDeviceDisabled        Code = "device_disabled"
+	CodeDeviceLockExpired     Code = "device_lock_expired"
+	CodeCertificateNotAllowed Code = "certificate_not_allowed"
+	CodeIdempotencyKeyReused  Code = "idempotency_key_reused"
+	CodeQuotaExceeded         Code = "quota_exceeded"
+	CodeOrganizationNotFound  Code = "organization_not_found"
+	CodeOrganizationConflict  Code = "organization_conflict"
+	CodeAPIKeyNotFound        Code = "api_key_not_found"
+	CodeInvalidAPIKey         Code = "invalid_api_key"
+	CodeWebhookNotFound       Code = "webhook_not_found"
+)
+
+// Codes lists all codes, e.g. for documentation
+var Codes = []Code{
+	CodeInvalidRequest,
+	CodeValidationFailed,
+	CodeUnauthorized,
+	CodeForbidden,
+	CodeNotFound,
+	CodeMethodNotAllowed,
+	CodeConflict,
+	CodePayloadTooLarge,
+	CodeUnprocessable,
+	CodeRateLimited,
+	CodeInternal,
+	CodeUnavailable,
+	CodeDeviceNotFound,
+	CodeDeviceConflict,
+	CodeDeviceDisabled,
+	CodeDeviceLockExpired,
+	CodeCertificateNotAllowed,
+	CodeIdempotencyKeyReused,
+	CodeQuotaExceeded,
+	CodeOrganizationNotFound,
+	CodeOrganizationConflict,
+	CodeAPIKeyNotFound,
+	CodeInvalidAPIKey,
+	CodeWebhookNotFound,
+}
+
+// DefaultCode returns the generic code of an HTTP status code
+func DefaultCode(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
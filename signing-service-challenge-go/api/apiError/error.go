@@ -1,29 +1,118 @@
 package apiError
 
 import (
+	"errors"
+	"net/http"
 	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
 )
 
 type Error struct {
 	code     int
+	kind     Code
 	messages []string
+	fields   []FieldError
+	header   http.Header
+}
+
+// FieldError describes why a single field of a request was rejected
+type FieldError struct {
+	// Pointer is the JSON pointer (RFC 6901) of the field in the request body, e.g. /metadata/region
+	Pointer string
+	Detail  string
 }
 
+// New creates an error with the HTTP status code and the default error code of the status,
+// see [WithCode] for more specific codes.
 func New(code int, message string, additional ...string) error {
 	return Error{
 		code:     code,
+		kind:     DefaultCode(code),
 		messages: append([]string{message}, additional...),
 	}
 }
 
+// Validation creates a 400 error for a request failing validation.
+// The fields of [validation.Errors] are kept, so clients can tell which fields were rejected.
+func Validation(err error) error {
+	apiErr := Error{
+		code:     http.StatusBadRequest,
+		kind:     CodeValidationFailed,
+		messages: []string{"validation failed", err.Error()},
+	}
+	var fieldErrs validation.Errors
+	var fieldErr validation.FieldError
+	switch {
+	case errors.As(err, &fieldErrs):
+	case errors.As(err, &fieldErr):
+		fieldErrs = validation.Errors{fieldErr}
+	}
+	for _, fieldErr := range fieldErrs {
+		apiErr.fields = append(apiErr.fields, FieldError{Pointer: fieldErr.Pointer, Detail: fieldErr.Message})
+	}
+	return apiErr
+}
+
+// WithCode replaces the machine-readable code of err.
+// Errors which aren't an [Error] are returned unchanged.
+func WithCode(err error, kind Code) error {
+	var apiErr Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	apiErr.kind = kind
+	return apiErr
+}
+
+// WithFields adds field-level details to err, e.g. the fields failing validation.
+// Errors which aren't an [Error] are returned unchanged.
+func WithFields(err error, fields ...FieldError) error {
+	var apiErr Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	apiErr.fields = append(apiErr.fields[:len(apiErr.fields):len(apiErr.fields)], fields...)
+	return apiErr
+}
+
+// WithHeader adds a header to the response written for err, e.g. Retry-After.
+// Errors which aren't an [Error] are returned unchanged.
+func WithHeader(err error, key string, value string) error {
+	var apiErr Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	apiErr.header = apiErr.header.Clone()
+	if apiErr.header == nil {
+		apiErr.header = make(http.Header)
+	}
+	apiErr.header.Set(key, value)
+	return apiErr
+}
+
 func (e Error) Error() string {
 	return strings.Join(e.messages, "\n")
 }
 
+// Code returns the HTTP status code of the error
 func (e Error) Code() int {
 	return e.code
 }
 
+// Kind returns the machine-readable code of the error
+func (e Error) Kind() Code {
+	return e.kind
+}
+
 func (e Error) Messages() []string {
 	return e.messages
 }
+
+func (e Error) Fields() []FieldError {
+	return e.fields
+}
+
+func (e Error) Header() http.Header {
+	return e.header
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/apiKeyManager"
+)
+
+type APIKeyHandler struct {
+	apiKeys *apiKeyManager.Handler
+	// enabled turns on authentication, without it all requests are allowed
+	enabled bool
+}
+
+func NewAPIKeyHandler(
+	apiKeys *apiKeyManager.Handler,
+	enabled bool,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeys: apiKeys,
+		enabled: enabled,
+	}
+}
+
+// Authenticate is a middleware verifying the API key presented as bearer token in the Authorization header.
+func (a *APIKeyHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := r.Context()
+
+		secret, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || secret == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			WriteErrorResponse(w, r, http.StatusUnauthorized, "missing api key")
+			return
+		}
+
+		key, err := a.apiKeys.Authenticate(ctx, secret)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			WriteError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithAPIKey(ctx, key)))
+	})
+}
+
+// RequireScope returns a middleware rejecting requests whose API key doesn't grant the scope.
+func (a *APIKeyHandler) RequireScope(scope domain.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !a.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, ok := domain.APIKeyFromContext(r.Context())
+			if !ok || !key.HasScope(scope) {
+				domain.LoggerFromContext(r.Context()).Error("missing scope", "scope", scope)
+				WriteErrorResponse(w, r, http.StatusForbidden, "api key is missing scope "+string(scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
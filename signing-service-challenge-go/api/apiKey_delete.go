@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Delete revokes the API key, it is kept in storage so it can still be listed.
+func (a *APIKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	keyId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	if err := a.apiKeys.RevokeAPIKey(ctx, organizationId, keyId); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusOK, nil)
+}
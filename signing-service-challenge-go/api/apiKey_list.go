@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+)
+
+func (a *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	keys, err := a.apiKeys.ListAPIKeys(ctx, organizationId)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	var out ListAPIKeyOutputDto
+	for _, key := range keys {
+		out.Items = append(out.Items, NewGetAPIKeyOutputDto(key))
+	}
+
+	WriteAPIResponse(w, http.StatusOK, out)
+}
+
+type ListAPIKeyOutputDto struct {
+	Items []GetAPIKeyOutputDto `json:"items"`
+}
+
+type GetAPIKeyOutputDto struct {
+	Id             string               `json:"id"`
+	OrganizationId string               `json:"organization_id"`
+	Name           string               `json:"name"`
+	Prefix         string               `json:"prefix"`
+	Scopes         []domain.Scope       `json:"scopes"`
+	CreatedAt      time.Time            `json:"created_at"`
+	RevokedAt      null.Null[time.Time] `json:"revoked_at,omitzero"`
+}
+
+// NewGetAPIKeyOutputDto maps a [domain.APIKey] to its public representation, without the hash of the secret.
+func NewGetAPIKeyOutputDto(key *domain.APIKey) GetAPIKeyOutputDto {
+	out := GetAPIKeyOutputDto{
+		Id:             key.Id.String(),
+		OrganizationId: key.OrganizationId.String(),
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Scopes:         key.Scopes,
+		CreatedAt:      key.CreatedAt,
+	}
+	if key.RevokedAt.Valid {
+		out.RevokedAt = null.New(key.RevokedAt.V)
+	}
+	return out
+}
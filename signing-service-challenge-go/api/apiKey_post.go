@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/apiKeyManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+)
+
+type PostAPIKeyInputDto struct {
+	Name   string         `json:"name"`
+	Scopes []domain.Scope `json:"scopes"`
+}
+
+func (d PostAPIKeyInputDto) Validate() error {
+	var v validation.Validator
+	v.Check("/name", validation.Label(d.Name))
+	v.Assert(len(d.Scopes) > 0, "/scopes", "at least one scope is required")
+	for i, scope := range d.Scopes {
+		v.Check(validation.Pointer("scopes", i), scope.Validate())
+	}
+	return v.Err()
+}
+
+func (a *APIKeyHandler) Post(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dto, success := ParseBody[PostAPIKeyInputDto](w, r)
+	if !success {
+		return
+	}
+
+	// keys are created for the organization the request is scoped to
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	key, err := a.apiKeys.CreateAPIKey(ctx, apiKeyManager.NewAPIKey{
+		OrganizationId: organizationId,
+		Name:           dto.Name,
+		Scopes:         dto.Scopes,
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusCreated, PostAPIKeyOutputDto{
+		GetAPIKeyOutputDto: NewGetAPIKeyOutputDto(key.APIKey),
+		Secret:             key.Secret,
+	})
+}
+
+// PostAPIKeyOutputDto is the only response containing the secret, it can't be retrieved afterwards.
+type PostAPIKeyOutputDto struct {
+	GetAPIKeyOutputDto
+	Secret string `json:"secret"`
+}
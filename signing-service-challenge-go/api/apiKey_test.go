@@ -0,0 +1,267 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testBootstrapKey = "sk_bootstrap"
+
+// bearer is a helper function to build the Authorization header for an API key
+func bearer(secret string) http.Header {
+	return http.Header{"Authorization": []string{"Bearer " + secret}}
+}
+
+// createAPIKey is a helper function to create a new API key for testing
+func createAPIKey(
+	assert *require.Assertions,
+	api http.Handler,
+	header http.Header,
+	scopes ...domain.Scope,
+) PostAPIKeyOutputDto {
+	var out TypedResponse[PostAPIKeyOutputDto]
+	response := makeRequestWithHeader(
+		assert,
+		header,
+		PostAPIKeyInputDto{
+			Name:   "test",
+			Scopes: scopes,
+		},
+		http.MethodPost,
+		"/api/v0/api-key",
+		api,
+		&out,
+	)
+	assert.Equal(http.StatusCreated, response.Code)
+
+	return out.Data
+}
+
+// TestAuthentication verifies that requests without a valid API key are rejected
+// This test covers missing, unknown and revoked keys
+func TestAuthentication(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithAuthentication(testBootstrapKey)).mux()
+
+	// Test case 1: Health checks don't need authentication
+	{
+		response := makeRequest(assert, nil, http.MethodGet, "/api/v0/health", api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+
+	// Test case 2: Missing and unknown keys are rejected
+	for _, header := range []http.Header{nil, bearer("sk_unknown"), {"Authorization": []string{testBootstrapKey}}} {
+		var out Problem
+		response := makeRequestWithHeader(assert, header, nil, http.MethodGet, "/api/v0/device", api, &out)
+		assert.Equal(http.StatusUnauthorized, response.Code)
+		assert.Equal("Bearer", response.Header().Get("WWW-Authenticate"))
+	}
+
+	// Test case 3: Created keys can be used, the secret isn't listed
+	key := createAPIKey(assert, api, bearer(testBootstrapKey), domain.ScopeDeviceRead)
+	assert.Equal(key.Prefix, key.Secret[:len(key.Prefix)])
+	{
+		response := makeRequestWithHeader(assert, bearer(key.Secret), nil, http.MethodGet, "/api/v0/device", api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	{
+		var out TypedResponse[ListAPIKeyOutputDto]
+		response := makeRequestWithHeader(assert, bearer(testBootstrapKey), nil, http.MethodGet, "/api/v0/api-key", api, &out)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Len(out.Data.Items, 1)
+		assert.Equal(key.Id, out.Data.Items[0].Id)
+		assert.False(out.Data.Items[0].RevokedAt.Filled())
+	}
+
+	// Test case 4: Revoked keys are rejected
+	{
+		response := makeRequestWithHeader(
+			assert,
+			bearer(testBootstrapKey),
+			nil,
+			http.MethodDelete,
+			fmt.Sprintf("/api/v0/api-key/%s", key.Id),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	{
+		response := makeRequestWithHeader(assert, bearer(key.Secret), nil, http.MethodGet, "/api/v0/device", api, nil)
+		assert.Equal(http.StatusUnauthorized, response.Code)
+	}
+}
+
+// TestAuthorizationScopes verifies that every route requires the matching scope
+func TestAuthorizationScopes(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithAuthentication(testBootstrapKey)).mux()
+
+	readKey := createAPIKey(assert, api, bearer(testBootstrapKey), domain.ScopeDeviceRead)
+	writeKey := createAPIKey(assert, api, bearer(testBootstrapKey), domain.ScopeDeviceWrite)
+	signKey := createAPIKey(assert, api, bearer(testBootstrapKey), domain.ScopeDeviceSign)
+	adminKey := createAPIKey(assert, api, bearer(testBootstrapKey), domain.ScopeAdmin)
+
+	var device TypedResponse[PostDeviceOutputDto]
+	{
+		response := makeRequestWithHeader(
+			assert,
+			bearer(writeKey.Secret),
+			PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&device,
+		)
+		assert.Equal(http.StatusCreated, response.Code)
+	}
+	devicePath := fmt.Sprintf("/api/v0/device/%s", device.Data.Id)
+
+	type request struct {
+		method string
+		path   string
+		body   any
+		scope  domain.Scope
+	}
+	requests := []request{
+		{http.MethodGet, "/api/v0/device", nil, domain.ScopeDeviceRead},
+		{http.MethodGet, devicePath, nil, domain.ScopeDeviceRead},
+		{http.MethodPut, devicePath + "/sign", PutDeviceSignInputDto{Data: "foo"}, domain.ScopeDeviceSign},
+		{http.MethodPatch, devicePath, PatchDeviceInputDto{}, domain.ScopeDeviceWrite},
+		{http.MethodGet, "/api/v0/organization", nil, domain.ScopePlatform},
+		{http.MethodGet, "/api/v0/api-key", nil, domain.ScopeAdmin},
+	}
+	keys := map[domain.Scope]string{
+		domain.ScopeDeviceRead:  readKey.Secret,
+		domain.ScopeDeviceWrite: writeKey.Secret,
+		domain.ScopeDeviceSign:  signKey.Secret,
+		domain.ScopeAdmin:       adminKey.Secret,
+		domain.ScopePlatform:    testBootstrapKey,
+	}
+
+	for _, req := range requests {
+		for scope, secret := range keys {
+			response := makeRequestWithHeader(assert, bearer(secret), req.body, req.method, req.path, api, nil)
+			granted := scope == req.scope || scope == domain.ScopePlatform || (scope == domain.ScopeAdmin && req.scope != domain.ScopePlatform)
+			if granted {
+				assert.Equal(http.StatusOK, response.Code, "%s %s with %s", req.method, req.path, scope)
+			} else {
+				assert.Equal(http.StatusForbidden, response.Code, "%s %s with %s", req.method, req.path, scope)
+			}
+		}
+	}
+}
+
+// TestAuthorizationOrganization verifies that API keys are bound to their organization
+func TestAuthorizationOrganization(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithAuthentication(testBootstrapKey)).mux()
+
+	var organization TypedResponse[GetOrganizationOutputDto]
+	makeRequestWithHeader(
+		assert,
+		bearer(testBootstrapKey),
+		PostOrganizationInputDto{Name: "foo"},
+		http.MethodPost,
+		"/api/v0/organization",
+		api,
+		&organization,
+	)
+
+	// the bootstrap key creates keys on behalf of other organizations
+	adminHeader := bearer(testBootstrapKey)
+	adminHeader.Set(OrganizationHeader, organization.Data.Id)
+	key := createAPIKey(assert, api, adminHeader, domain.ScopeDeviceWrite)
+	assert.Equal(organization.Data.Id, key.OrganizationId)
+
+	// Test case 1: Devices are created in the organization of the key
+	var device TypedResponse[PostDeviceOutputDto]
+	{
+		response := makeRequestWithHeader(
+			assert,
+			bearer(key.Secret),
+			PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&device,
+		)
+		assert.Equal(http.StatusCreated, response.Code)
+	}
+	{
+		var out TypedResponse[ListDeviceOutputDto]
+		makeRequestWithHeader(assert, adminHeader, nil, http.MethodGet, "/api/v0/device", api, &out)
+		assert.Len(out.Data.Items, 1)
+		assert.Equal(device.Data.Id, out.Data.Items[0].Id)
+	}
+
+	// Test case 2: Keys can't switch to another organization
+	{
+		header := bearer(key.Secret)
+		header.Set(OrganizationHeader, domain.DefaultOrganizationId.String())
+		response := makeRequestWithHeader(
+			assert,
+			header,
+			PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusForbidden, response.Code)
+	}
+	{
+		// naming the own organization is allowed
+		header := bearer(key.Secret)
+		header.Set(OrganizationHeader, organization.Data.Id)
+		response := makeRequestWithHeader(
+			assert,
+			header,
+			nil,
+			http.MethodDelete,
+			fmt.Sprintf("/api/v0/device/%s", device.Data.Id),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+
+	// Test case 3: Admin keys of an organization are bound to it as well
+	tenantAdmin := bearer(createAPIKey(assert, api, adminHeader, domain.ScopeAdmin).Secret)
+	{
+		header := tenantAdmin.Clone()
+		header.Set(OrganizationHeader, domain.DefaultOrganizationId.String())
+		response := makeRequestWithHeader(assert, header, PostAPIKeyInputDto{Name: "foreign", Scopes: []domain.Scope{domain.ScopeAdmin}}, http.MethodPost, "/api/v0/api-key", api, nil)
+		assert.Equal(http.StatusForbidden, response.Code)
+		response = makeRequestWithHeader(assert, header, nil, http.MethodGet, "/api/v0/device", api, nil)
+		assert.Equal(http.StatusForbidden, response.Code)
+	}
+	{
+		response := makeRequestWithHeader(assert, tenantAdmin, nil, http.MethodGet, "/api/v0/organization", api, nil)
+		assert.Equal(http.StatusForbidden, response.Code)
+		response = makeRequestWithHeader(assert, tenantAdmin, nil, http.MethodDelete, "/api/v0/organization/"+organization.Data.Id, api, nil)
+		assert.Equal(http.StatusForbidden, response.Code)
+	}
+
+	// Test case 4: The platform scope can't be granted to API keys
+	{
+		response := makeRequestWithHeader(assert, tenantAdmin, PostAPIKeyInputDto{Name: "platform", Scopes: []domain.Scope{domain.ScopePlatform}}, http.MethodPost, "/api/v0/api-key", api, nil)
+		assert.Equal(http.StatusBadRequest, response.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSignatureLimit = 100
+	maxSignatureLimit     = 500
+)
+
+// ListSignatures returns the signature log of a device after the given counter, oldest first.
+func (d *DeviceHandler) ListSignatures(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	limit := defaultSignatureLimit
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSignatureLimit {
+			WriteError(w, r, apiError.Validation(errors.New("limit must be between 1 and "+strconv.Itoa(maxSignatureLimit))))
+			return
+		}
+	}
+	var after int
+	if value := query.Get("after"); value != "" {
+		after, err = strconv.Atoi(value)
+		if err != nil || after < 0 {
+			WriteError(w, r, apiError.Validation(errors.New("after can not be negative")))
+			return
+		}
+	}
+
+	signatures, err := d.devices.ListSignatures(ctx, deviceId, after, limit)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	out := ListDeviceSignatureOutputDto{
+		Items: []GetDeviceSignatureOutputDto{},
+	}
+	for _, signature := range signatures {
+		out.Items = append(out.Items, NewGetDeviceSignatureOutputDto(signature))
+	}
+
+	WriteAPIResponse(w, http.StatusOK, out)
+}
+
+type ListDeviceSignatureOutputDto struct {
+	Items []GetDeviceSignatureOutputDto `json:"items"`
+}
+
+type GetDeviceSignatureOutputDto struct {
+	Counter       int       `json:"counter"`
+	Signature     string    `json:"signature"`
+	SignedData    string    `json:"signed_data"`
+	Data          string    `json:"data"`
+	LastSignature string    `json:"last_signature"`
+	KeyVersion    int       `json:"key_version"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewGetDeviceSignatureOutputDto maps a [domain.Signature] to its public representation.
+func NewGetDeviceSignatureOutputDto(signature *domain.Signature) GetDeviceSignatureOutputDto {
+	return GetDeviceSignatureOutputDto{
+		Counter:       signature.Counter,
+		Signature:     signature.Signature,
+		SignedData:    signature.SignedData(),
+		Data:          signature.Data,
+		LastSignature: signature.LastSignature,
+		KeyVersion:    signature.KeyVersion,
+		CreatedAt:     signature.CreatedAt,
+	}
+}
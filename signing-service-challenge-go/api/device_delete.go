@@ -1,9 +1,9 @@
 package api
 
 import (
-	"log/slog"
 	"net/http"
 
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -13,22 +13,22 @@ func (d *DeviceHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		slog.Error("invalid uuid", "error", err)
-		WriteErrorResponse(w, http.StatusBadRequest, "invalid uuid", err.Error())
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
 		return
 	}
 
 	// lock here to ensure we don't delete the device while signing is in progress
 	lock, err := d.locker.Acquire(ctx, deviceId)
 	if err != nil {
-		slog.Error("unable to acquire lock", "error", err)
-		WriteInternalError(w)
+		domain.LoggerFromContext(r.Context()).Error("unable to acquire lock", "error", err)
+		WriteInternalError(w, r)
 		return
 	}
 	defer lock.Unlock()
 
 	if err := d.devices.DeleteDevice(ctx, deviceId); err != nil {
-		WriteError(w, err)
+		WriteError(w, r, err)
 		return
 	}
 
@@ -1,8 +1,8 @@
 package api
 
 import (
-	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
@@ -15,34 +15,52 @@ func (d *DeviceHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		slog.Error("invalid uuid", "error", err)
-		WriteErrorResponse(w, http.StatusBadRequest, "invalid uuid", err.Error())
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
 		return
 	}
 
 	device, err := d.devices.GetDevice(ctx, deviceId)
 	if err != nil {
-		WriteError(w, err)
+		WriteError(w, r, err)
 		return
 	}
 
+	WriteAPIResponse(w, http.StatusOK, NewGetDeviceOutputDto(device))
+}
+
+type GetDeviceOutputDto struct {
+	Id                    string                  `json:"id"`
+	SigningAlgorithm      domain.SigningAlgorithm `json:"signing_algorithm"`
+	Label                 null.Null[string]       `json:"label,omitzero"`
+	Status                domain.DeviceStatus     `json:"status"`
+	Metadata              map[string]string       `json:"metadata,omitempty"`
+	Tags                  []string                `json:"tags,omitempty"`
+	ClientCertificates    []string                `json:"client_certificates,omitempty"`
+	PublicKeys            []string                `json:"public_keys"`
+	SignatureCounter      int                     `json:"signature_counter"`
+	MonthlySignatureQuota null.Null[int]          `json:"monthly_signature_quota,omitzero"`
+	MonthlySignatureUsage int                     `json:"monthly_signature_usage"`
+}
+
+// NewGetDeviceOutputDto maps a [domain.Device] to its public representation.
+func NewGetDeviceOutputDto(device *domain.Device) GetDeviceOutputDto {
 	out := GetDeviceOutputDto{
-		Id:               device.Id.String(),
-		SigningAlgorithm: device.SigningAlgorithm,
-		PublicKeys:       device.PublicKeys,
-		SignatureCounter: device.SignatureCounter,
+		Id:                    device.Id.String(),
+		SigningAlgorithm:      device.SigningAlgorithm,
+		Status:                device.Status,
+		Metadata:              device.Metadata,
+		Tags:                  device.Tags,
+		ClientCertificates:    device.ClientCertificates,
+		PublicKeys:            device.PublicKeys,
+		SignatureCounter:      device.SignatureCounter,
+		MonthlySignatureUsage: device.MonthlyUsage(time.Now()),
 	}
 	if device.Label.Valid {
 		out.Label = null.New(device.Label.V)
 	}
-
-	WriteAPIResponse(w, http.StatusOK, out)
-}
-
-type GetDeviceOutputDto struct {
-	Id               string                  `json:"id"`
-	SigningAlgorithm domain.SigningAlgorithm `json:"signing_algorithm"`
-	Label            null.Null[string]       `json:"label,omitzero"`
-	PublicKeys       []string                `json:"public_keys"`
-	SignatureCounter int                     `json:"signature_counter"`
+	if device.MonthlySignatureQuota.Valid {
+		out.MonthlySignatureQuota = null.New(device.MonthlySignatureQuota.V)
+	}
+	return out
 }
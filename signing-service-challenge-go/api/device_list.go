@@ -1,33 +1,43 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
-	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+const (
+	// metadataQueryPrefix marks query parameters filtering on device metadata, e.g. meta.store=berlin-1
+	metadataQueryPrefix = "meta."
+
+	// maxDeviceLimit is the largest page of devices, all devices are returned without a limit
+	maxDeviceLimit = 500
 )
 
 func (d *DeviceHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	devices, err := d.devices.ListDevices(ctx)
+	filter, err := parseDeviceFilter(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, apiError.Validation(err))
+		return
+	}
+
+	devices, err := d.devices.ListDevices(ctx, filter)
 	if err != nil {
-		WriteError(w, err)
+		WriteError(w, r, err)
 		return
 	}
 
 	var out ListDeviceOutputDto
 	for _, device := range devices {
-		singleDevice := GetDeviceOutputDto{
-			Id:               device.Id.String(),
-			SigningAlgorithm: device.SigningAlgorithm,
-			PublicKeys:       device.PublicKeys,
-			SignatureCounter: device.SignatureCounter,
-		}
-		if device.Label.Valid {
-			singleDevice.Label = null.New(device.Label.V)
-		}
-
-		out.Items = append(out.Items, singleDevice)
+		out.Items = append(out.Items, NewGetDeviceOutputDto(device))
 	}
 
 	WriteAPIResponse(w, http.StatusOK, out)
@@ -36,3 +46,44 @@ func (d *DeviceHandler) List(w http.ResponseWriter, r *http.Request) {
 type ListDeviceOutputDto struct {
 	Items []GetDeviceOutputDto `json:"items"`
 }
+
+// parseDeviceFilter reads the tag and metadata filters and the page from the query,
+// a device has to carry all the given tags and metadata entries to match.
+func parseDeviceFilter(query url.Values) (domain.DeviceFilter, error) {
+	var filter domain.DeviceFilter
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeviceLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxDeviceLimit)
+		}
+		filter.Limit = limit
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return filter, errors.New("offset can not be negative")
+		}
+		filter.Offset = offset
+	}
+
+	filter.Tags = query["tag"]
+	for key, values := range query {
+		metadataKey, found := strings.CutPrefix(key, metadataQueryPrefix)
+		if !found {
+			continue
+		}
+		if len(values) != 1 {
+			return filter, fmt.Errorf("metadata filter %q can only be given once", metadataKey)
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]string)
+		}
+		filter.Metadata[metadataKey] = values[0]
+	}
+
+	return filter, errors.Join(
+		domain.ValidateTags(filter.Tags),
+		domain.ValidateMetadata(filter.Metadata),
+	)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PatchDeviceInputDto follows JSON Merge Patch semantics, absent fields are left untouched,
+// fields set to null are cleared.
+type PatchDeviceInputDto struct {
+	Label                 null.Patch[string]                       `json:"label,omitzero"`
+	Metadata              null.Patch[map[string]null.Null[string]] `json:"metadata,omitzero"`
+	Tags                  null.Patch[[]string]                     `json:"tags,omitzero"`
+	Status                null.Patch[domain.DeviceStatus]          `json:"status,omitzero"`
+	ClientCertificates    null.Patch[[]string]                     `json:"client_certificates,omitzero"`
+	MonthlySignatureQuota null.Patch[int]                          `json:"monthly_signature_quota,omitzero"`
+}
+
+func (d PatchDeviceInputDto) Validate() error {
+	var v validation.Validator
+	if label, filled := d.Label.Value(); filled {
+		v.Check("/label", validation.Label(label))
+	}
+	if changes, filled := d.Metadata.Value(); filled {
+		// entries set to null are removed, only the remaining values have to be valid
+		metadata := make(map[string]string, len(changes))
+		for key, value := range changes {
+			metadata[key] = value.Some()
+		}
+		v.Check("/metadata", domain.ValidateMetadata(metadata))
+	}
+	if tags, filled := d.Tags.Value(); filled {
+		v.Check("/tags", domain.ValidateTags(tags))
+	}
+	if fingerprints, filled := d.ClientCertificates.Value(); filled {
+		_, err := domain.NormalizeFingerprints(fingerprints)
+		v.Check("/client_certificates", err)
+	}
+	if quota, filled := d.MonthlySignatureQuota.Value(); filled {
+		v.Assert(quota >= 0, "/monthly_signature_quota", "monthly signature quota can not be negative")
+	}
+	if d.Status.Present() {
+		status, err := d.Status.Expect("status can not be null")
+		if err == nil {
+			err = status.Validate()
+		}
+		v.Check("/status", err)
+	}
+	return v.Err()
+}
+
+func (d *DeviceHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dto, success := ParseBody[PatchDeviceInputDto](w, r)
+	if !success {
+		return
+	}
+
+	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	// lock here so the patch doesn't overwrite a signature counter incremented concurrently
+	lock, err := d.locker.Acquire(ctx, deviceId)
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("unable to acquire lock", "error", err)
+		WriteInternalError(w, r)
+		return
+	}
+	defer lock.Unlock()
+
+	device, err := d.devices.UpdateDevice(ctx, deviceId, deviceManager.DevicePatch{
+		Label:                 dto.Label,
+		Metadata:              dto.Metadata,
+		Tags:                  dto.Tags,
+		Status:                dto.Status,
+		ClientCertificates:    dto.ClientCertificates,
+		MonthlySignatureQuota: dto.MonthlySignatureQuota,
+	}, lock.Token())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusOK, NewGetDeviceOutputDto(device))
+}
@@ -1,13 +1,12 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
-	"github.com/google/uuid"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
 )
 
 type PostDeviceInputDto struct {
@@ -15,55 +14,79 @@ type PostDeviceInputDto struct {
 	Id               null.Null[string]       `json:"id,omitzero"`
 	SigningAlgorithm domain.SigningAlgorithm `json:"signing_algorithm"`
 	Label            null.Null[string]       `json:"label,omitzero"`
+	Metadata         map[string]string       `json:"metadata,omitempty"`
+	Tags             []string                `json:"tags,omitempty"`
+	// SHA-256 fingerprints of the client certificates allowed to sign with the device
+	ClientCertificates    []string       `json:"client_certificates,omitempty"`
+	MonthlySignatureQuota null.Null[int] `json:"monthly_signature_quota,omitzero"`
 }
 
 func (d PostDeviceInputDto) Validate() error {
-	var validationErr error
+	var v validation.Validator
 	if id, filled := d.Id.Value(); filled {
-		validationErr = errors.Join(
-			validationErr,
-			uuid.Validate(id),
-		)
+		v.Check("/id", domain.ValidateDeviceId(id))
 	}
-	validationErr = errors.Join(
-		validationErr,
-		d.SigningAlgorithm.Validate(),
-	)
-	return validationErr
+	v.Check("/signing_algorithm", d.SigningAlgorithm.Validate())
+	if label, filled := d.Label.Value(); filled {
+		v.Check("/label", validation.Label(label))
+	}
+	v.Check("/metadata", domain.ValidateMetadata(d.Metadata))
+	v.Check("/tags", domain.ValidateTags(d.Tags))
+	if _, err := domain.NormalizeFingerprints(d.ClientCertificates); err != nil {
+		v.Check("/client_certificates", err)
+	}
+	if quota, filled := d.MonthlySignatureQuota.Value(); filled {
+		v.Assert(quota >= 0, "/monthly_signature_quota", "monthly signature quota can not be negative")
+	}
+	return v.Err()
 }
 
 func (d *DeviceHandler) Post(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	dto, success := ParseBody[PostDeviceInputDto](ctx, w, r.Body)
+	dto, success := ParseBody[PostDeviceInputDto](w, r)
 	if !success {
 		return
 	}
 
 	newDevice, err := d.devices.CreateDevice(ctx, deviceManager.NewDevice{
-		Id:               dto.Id,
-		Label:            dto.Label,
-		SigningAlgorithm: dto.SigningAlgorithm,
+		Id:                    dto.Id,
+		Label:                 dto.Label,
+		Metadata:              dto.Metadata,
+		Tags:                  dto.Tags,
+		ClientCertificates:    dto.ClientCertificates,
+		MonthlySignatureQuota: dto.MonthlySignatureQuota,
+		SigningAlgorithm:      dto.SigningAlgorithm,
 	})
 	if err != nil {
-		WriteError(w, err)
+		WriteError(w, r, err)
 		return
 	}
 
 	out := PostDeviceOutputDto{
-		Id:               newDevice.Id.String(),
-		SigningAlgorithm: newDevice.SigningAlgorithm,
-		PublicKeys:       newDevice.PublicKeys,
+		Id:                 newDevice.Id.String(),
+		SigningAlgorithm:   newDevice.SigningAlgorithm,
+		Metadata:           newDevice.Metadata,
+		Tags:               newDevice.Tags,
+		ClientCertificates: newDevice.ClientCertificates,
+		PublicKeys:         newDevice.PublicKeys,
 	}
 	if newDevice.Label.Valid {
 		out.Label = null.New(newDevice.Label.V)
 	}
+	if newDevice.MonthlySignatureQuota.Valid {
+		out.MonthlySignatureQuota = null.New(newDevice.MonthlySignatureQuota.V)
+	}
 
 	WriteAPIResponse(w, http.StatusCreated, out)
 }
 
 type PostDeviceOutputDto struct {
-	Id               string                  `json:"id"`
-	SigningAlgorithm domain.SigningAlgorithm `json:"signing_algorithm"`
-	Label            null.Null[string]       `json:"label,omitzero"`
-	PublicKeys       []string                `json:"public_keys"`
+	Id                    string                  `json:"id"`
+	SigningAlgorithm      domain.SigningAlgorithm `json:"signing_algorithm"`
+	Label                 null.Null[string]       `json:"label,omitzero"`
+	Metadata              map[string]string       `json:"metadata,omitempty"`
+	Tags                  []string                `json:"tags,omitempty"`
+	ClientCertificates    []string                `json:"client_certificates,omitempty"`
+	MonthlySignatureQuota null.Null[int]          `json:"monthly_signature_quota,omitzero"`
+	PublicKeys            []string                `json:"public_keys"`
 }
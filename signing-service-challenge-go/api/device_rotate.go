@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RotateKey replaces the signing key of the device, the new public key is appended to the public keys.
+func (d *DeviceHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	// lock here so no signature is created with the old key after the rotation
+	lock, err := d.locker.Acquire(ctx, deviceId)
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("unable to acquire lock", "error", err)
+		WriteInternalError(w, r)
+		return
+	}
+	defer lock.Unlock()
+
+	device, err := d.devices.RotateKey(ctx, deviceId, lock.Token())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusOK, NewGetDeviceOutputDto(device))
+}
@@ -1,25 +1,41 @@
 package api
 
 import (
+	"errors"
 	"fmt"
-	"log/slog"
 	"net/http"
 
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+const (
+	// IdempotencyKeyHeader allows clients to safely retry signing requests
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses which were stored by an earlier request with the same idempotency key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// maxSignDataSize limits the data signed at once in bytes, the signature log keeps every signed data
+	maxSignDataSize = 256 << 10
+)
+
 type PutDeviceSignInputDto struct {
 	Data string `json:"data"`
 }
 
 func (d PutDeviceSignInputDto) Validate() error {
-	return nil
+	var v validation.Validator
+	v.Check("/data", validation.MaxSize(d.Data, maxSignDataSize))
+	return v.Err()
 }
 
 func (d *DeviceHandler) Sign(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	dto, success := ParseBody[PutDeviceSignInputDto](ctx, w, r.Body)
+	dto, success := ParseBody[PutDeviceSignInputDto](w, r)
 	if !success {
 		return
 	}
@@ -31,8 +47,14 @@ func (d *DeviceHandler) Sign(w http.ResponseWriter, r *http.Request) {
 
 	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		slog.Error("invalid uuid", "error", err)
-		WriteErrorResponse(w, http.StatusBadRequest, "invalid uuid", err.Error())
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	idempotencyKey, err := parseIdempotencyKey(r.Header)
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid idempotency key", err.Error())
 		return
 	}
 
@@ -41,18 +63,22 @@ func (d *DeviceHandler) Sign(w http.ResponseWriter, r *http.Request) {
 	// signing could be done without a lock, incrementing the sign counter with a channel.
 	lock, err := d.locker.Acquire(ctx, deviceId)
 	if err != nil {
-		slog.Error("unable to acquire lock", "error", err)
-		WriteInternalError(w)
+		domain.LoggerFromContext(r.Context()).Error("unable to acquire lock", "error", err)
+		WriteInternalError(w, r)
 		return
 	}
 	defer lock.Unlock()
 
-	signedData, err := d.devices.SignData(ctx, deviceId, dto.Data)
+	signedData, err := d.devices.SignData(ctx, deviceId, dto.Data, idempotencyKey, lock.Token())
 	if err != nil {
-		WriteError(w, err)
+		WriteError(w, r, err)
 		return
 	}
 
+	if signedData.Replayed {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+	}
+
 	WriteAPIResponse(w, http.StatusOK,
 		PutDeviceSignOutputDto{
 			Signature: signedData.Signature,
@@ -60,7 +86,7 @@ func (d *DeviceHandler) Sign(w http.ResponseWriter, r *http.Request) {
 			// Change the order of parts, placing the last signature before the data to be signed, for better parsing.
 			// The data could contain underscores, but we know only 2 are part of formatting,
 			// therefore, others must be part of the data.
-			SignedData: fmt.Sprintf("%d_%s_%s", signedData.SignatureCounter, signedData.LastSignature, signedData.Data),
+			SignedData: signedData.SecuredData(),
 		},
 	)
 }
@@ -69,3 +95,19 @@ type PutDeviceSignOutputDto struct {
 	Signature  string `json:"signature"`
 	SignedData string `json:"signed_data"`
 }
+
+func parseIdempotencyKey(header http.Header) (null.Null[string], error) {
+	key := header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return null.Empty[string](), nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return null.Empty[string](), fmt.Errorf("idempotency key can be at most %d characters long", maxIdempotencyKeyLength)
+	}
+	for _, c := range key {
+		if c < 0x21 || c > 0x7e {
+			return null.Empty[string](), errors.New("idempotency key must only contain visible ascii characters")
+		}
+	}
+	return null.New(key), nil
+}
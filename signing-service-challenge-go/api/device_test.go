@@ -3,6 +3,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	stdcrypto "crypto"
 	"crypto/ecdsa"
 	"crypto/rand"
@@ -20,8 +21,11 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
@@ -42,6 +46,19 @@ func makeRequest(
 	urlPath string,
 	handle http.Handler,
 	outputDto any,
+) *httptest.ResponseRecorder {
+	return makeRequestWithHeader(assert, nil, inputDto, method, urlPath, handle, outputDto)
+}
+
+// makeRequestWithHeader is like makeRequest, but additionally sends the given headers
+func makeRequestWithHeader(
+	assert *require.Assertions,
+	header http.Header,
+	inputDto any,
+	method string,
+	urlPath string,
+	handle http.Handler,
+	outputDto any,
 ) *httptest.ResponseRecorder {
 	buf := bytes.NewBuffer(nil)
 	if inputDto != nil {
@@ -50,8 +67,13 @@ func makeRequest(
 		assert.NoError(err)
 	}
 
+	// the path is escaped, a query is appended as is
+	urlPath, query, hasQuery := strings.Cut(urlPath, "?")
 	url, err := url.JoinPath("http://localhost/", urlPath)
 	assert.NoError(err)
+	if hasQuery {
+		url += "?" + query
+	}
 
 	var req *http.Request
 	if inputDto != nil {
@@ -59,9 +81,18 @@ func makeRequest(
 	} else {
 		req = httptest.NewRequest(method, url, nil)
 	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
 	res := httptest.NewRecorder()
 	handle.ServeHTTP(res, req)
 
+	// every response has to match the OpenAPI document, so it can't drift from the handlers
+	err = specification().ValidateResponse(method, req.URL.Path, res.Code, res.Header(), res.Body.Bytes())
+	assert.NoError(err)
+
 	if outputDto != nil && res.Body.Len() > 0 {
 		err := json.NewDecoder(res.Body).Decode(&outputDto)
 		assert.NoError(err)
@@ -158,6 +189,98 @@ func TestPostDevice(t *testing.T) {
 	assert.Greater(len(out.Data.PublicKeys), 0)
 }
 
+// TestPostDeviceCryptoPolicy verifies that devices are only created with the algorithms and key sizes of the policy
+func TestPostDeviceCryptoPolicy(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithCryptoPolicy(deviceManager.CryptoPolicy{
+		Algorithms: []domain.SigningAlgorithm{domain.SigningAlgorithmRsa},
+		RSAKeySize: 2048,
+	})).mux()
+
+	// Test case 1: RSA keys are generated with the size of the policy
+	device := createDevice(assert, api, domain.SigningAlgorithmRsa)
+	block, _ := pem.Decode([]byte(device.PublicKeys[0]))
+	assert.NotNil(block)
+	publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+	assert.NoError(err)
+	assert.Equal(2048, publicKey.N.BitLen())
+
+	// Test case 2: Algorithms outside of the policy are rejected
+	var problem Problem
+	response := makeRequest(
+		assert,
+		PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+		http.MethodPost,
+		"/api/v0/device",
+		api,
+		&problem,
+	)
+	assert.Equal(http.StatusBadRequest, response.Code)
+	assert.Equal(apiError.CodeValidationFailed, problem.Code)
+	assert.Equal("/signing_algorithm", problem.Errors[0].Pointer)
+}
+
+// fencedLocker hands out locks with the token set by the test, like replicas whose locks expire
+type fencedLocker struct {
+	lock.Locker[uuid.UUID]
+	token uint64
+}
+
+func (l *fencedLocker) Acquire(ctx context.Context, id uuid.UUID) (lock.Lock, error) {
+	acquired, err := l.Locker.Acquire(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return fencedLock{acquired, l.token}, nil
+}
+
+type fencedLock struct {
+	lock.Lock
+	token uint64
+}
+
+func (l fencedLock) Token() uint64 {
+	return l.token
+}
+
+// TestSignStaleFencingToken verifies that writes of a holder whose lock expired meanwhile are rejected
+func TestSignStaleFencingToken(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := &fencedLocker{Locker: lock.NewMemoryLocker[uuid.UUID](), token: 5}
+	api := NewServer(storage, locker).mux()
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	signPath := fmt.Sprintf("/api/v0/device/%s/sign", device.Id)
+
+	// Test case 1: The current holder signs
+	response := makeRequest(assert, PutDeviceSignInputDto{Data: "first"}, http.MethodPut, signPath, api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+
+	// Test case 2: A holder with an older token is rejected and the counter is kept
+	locker.token = 4
+	var problem Problem
+	response = makeRequest(assert, PutDeviceSignInputDto{Data: "stale"}, http.MethodPut, signPath, api, &problem)
+	assert.Equal(http.StatusConflict, response.Code)
+	assert.Equal(apiError.CodeDeviceLockExpired, problem.Code)
+
+	response = makeRequest(assert, PatchDeviceInputDto{Label: null.Set("stale")}, http.MethodPatch, "/api/v0/device/"+device.Id, api, nil)
+	assert.Equal(http.StatusConflict, response.Code)
+
+	var out TypedResponse[GetDeviceOutputDto]
+	makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+device.Id, api, &out)
+	assert.Equal(1, out.Data.SignatureCounter)
+	assert.False(out.Data.Label.Filled())
+
+	// Test case 3: Later holders sign again
+	locker.token = 6
+	response = makeRequest(assert, PutDeviceSignInputDto{Data: "second"}, http.MethodPut, signPath, api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+}
+
 // TestPostDeviceBadRequest verifies that invalid device creation requests are rejected
 // This test covers multiple invalid scenarios to ensure proper input validation
 func TestPostDeviceBadRequest(t *testing.T) {
@@ -170,7 +293,7 @@ func TestPostDeviceBadRequest(t *testing.T) {
 	// Test case 1: Completely empty request body (nil input)
 	// Should return 400 Bad Request because no data was provided
 	{
-		var out ErrorResponse
+		var out Problem
 		response := makeRequest(
 			assert,
 			nil,
@@ -180,12 +303,13 @@ func TestPostDeviceBadRequest(t *testing.T) {
 			&out,
 		)
 		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
 	}
 
 	// Test case 2: Empty DTO with no required fields
 	// Should return 400 Bad Request because SigningAlgorithm is required
 	{
-		var out ErrorResponse
+		var out Problem
 		response := makeRequest(
 			assert,
 			PostDeviceInputDto{},
@@ -195,12 +319,13 @@ func TestPostDeviceBadRequest(t *testing.T) {
 			&out,
 		)
 		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
 	}
 
 	// Test case 3: An invalid ID format provided
 	// Should return 400 Bad Request because ID should be auto-generated, not provided
 	{
-		var out ErrorResponse
+		var out Problem
 		response := makeRequest(
 			assert,
 			PostDeviceInputDto{
@@ -213,12 +338,13 @@ func TestPostDeviceBadRequest(t *testing.T) {
 			&out,
 		)
 		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
 	}
 
 	// Test case 4: Invalid signing algorithm
 	// Should return 400 Bad Request because "foo" is not a valid signing algorithm
 	{
-		var out ErrorResponse
+		var out Problem
 		response := makeRequest(
 			assert,
 			PostDeviceInputDto{
@@ -230,8 +356,86 @@ func TestPostDeviceBadRequest(t *testing.T) {
 			&out,
 		)
 		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
+	}
+
+	// Test case 5: All invalid fields are reported with a pointer to the field
+	{
+		var out Problem
+		response := makeRequest(
+			assert,
+			PostDeviceInputDto{
+				SigningAlgorithm:   "foo",
+				Label:              null.New("line\nbreak"),
+				Metadata:           map[string]string{"Invalid Key": "foo"},
+				Tags:               []string{"valid", "NOT VALID"},
+				ClientCertificates: []string{"foo"},
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal("validation failed", out.Detail)
+		assert.Equal([]ProblemFieldError{
+			{Pointer: "/client_certificates/0", Detail: "certificate fingerprint must be a hex encoded sha-256 hash"},
+			{Pointer: "/label", Detail: "must only contain printable characters"},
+			{Pointer: "/metadata/Invalid Key", Detail: `metadata key "Invalid Key" invalid value`},
+			{Pointer: "/signing_algorithm", Detail: "signing algorithm invalid value"},
+			{Pointer: "/tags/1", Detail: `tag "NOT VALID" invalid value`},
+		}, out.Errors)
+	}
+
+	// Test case 6: Unknown fields are rejected
+	{
+		var out Problem
+		response := makeRequest(
+			assert,
+			map[string]any{"signing_algorithm": "ECC", "algorithm": "RSA"},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal([]ProblemFieldError{{Pointer: "/algorithm", Detail: "unknown field"}}, out.Errors)
+	}
+
+	// Test case 7: Trailing data and oversized bodies are rejected
+	for body, status := range map[string]int{
+		`{"signing_algorithm": "ECC"} {"signing_algorithm": "RSA"}`: http.StatusBadRequest,
+		`{"label": "` + strings.Repeat("a", maxBodySize) + `"}`:     http.StatusRequestEntityTooLarge,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/api/v0/device", strings.NewReader(body))
+		res := httptest.NewRecorder()
+		api.ServeHTTP(res, req)
+		assert.Equal(status, res.Code)
+		assert.NoError(specification().ValidateResponse(http.MethodPost, "/api/v0/device", res.Code, res.Header(), res.Body.Bytes()))
+	}
+
+	// Test case 8: The nil uuid is reserved and can't be chosen as device id
+	{
+		var out Problem
+		response := makeRequest(
+			assert,
+			PostDeviceInputDto{
+				Id:               null.New(uuid.Nil.String()),
+				SigningAlgorithm: domain.SigningAlgorithmEcc,
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal([]ProblemFieldError{{Pointer: "/id", Detail: "device id must not be the nil uuid"}}, out.Errors)
 	}
 
+	// nothing was created by the rejected requests
+	var list TypedResponse[ListDeviceOutputDto]
+	makeRequest(assert, nil, http.MethodGet, "/api/v0/device", api, &list)
+	assert.Empty(list.Data.Items)
 }
 
 // TestSignBasic verifies that basic signing functionality works correctly
@@ -485,7 +689,7 @@ func TestSignBadRequest(t *testing.T) {
 	api := NewServer(storage, locker).mux()
 
 	// Attempt to sign with an invalid UUID format
-	var out ErrorResponse
+	var out Problem
 	signResponse := makeRequest(
 		assert,
 		PutDeviceSignInputDto{
@@ -499,6 +703,7 @@ func TestSignBadRequest(t *testing.T) {
 
 	// Should return 400 Bad Request due to invalid UUID format
 	assert.Equal(http.StatusBadRequest, signResponse.Code)
+	assert.Equal(apiError.CodeInvalidRequest, out.Code)
 }
 
 // TestSignNotFound verifies that signing with non-existent device returns 404
@@ -511,7 +716,7 @@ func TestSignNotFound(t *testing.T) {
 	api := NewServer(storage, locker).mux()
 
 	// Attempt to sign with a valid UUID format but non-existent device
-	var out ErrorResponse
+	var out Problem
 	signResponse := makeRequest(
 		assert,
 		PutDeviceSignInputDto{
@@ -525,6 +730,7 @@ func TestSignNotFound(t *testing.T) {
 
 	// Should return 404 Not Found because the device doesn't exist in storage
 	assert.Equal(http.StatusNotFound, signResponse.Code)
+	assert.Equal(apiError.CodeDeviceNotFound, out.Code)
 }
 
 // TestGetDevice verifies that retrieving a device returns correct information
@@ -608,6 +814,41 @@ func TestListDevice(t *testing.T) {
 	assert.Equal(device3.Id, items[2].Id) // Third device created
 }
 
+// TestListDevicePagination verifies that devices can be listed in pages
+func TestListDevicePagination(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	var created []string
+	for range 5 {
+		created = append(created, createDevice(assert, api, domain.SigningAlgorithmEcc).Id)
+	}
+
+	// Test case 1: Pages follow the order of creation without overlapping
+	var listed []string
+	for offset := 0; ; offset += 2 {
+		var out TypedResponse[ListDeviceOutputDto]
+		response := makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/device?limit=2&offset=%d", offset), api, &out)
+		assert.Equal(http.StatusOK, response.Code)
+		for _, item := range out.Data.Items {
+			listed = append(listed, item.Id)
+		}
+		if len(out.Data.Items) < 2 {
+			break
+		}
+	}
+	assert.Equal(created, listed)
+
+	// Test case 2: Invalid pages are rejected
+	for _, query := range []string{"limit=0", "limit=501", "limit=x", "offset=-1"} {
+		response := makeRequest(assert, nil, http.MethodGet, "/api/v0/device?"+query, api, nil)
+		assert.Equal(http.StatusBadRequest, response.Code, query)
+	}
+}
+
 // TestDeleteDevice verifies that deleting devices works correctly
 // This test covers both deleting non-existent devices and actual device deletion
 func TestDeleteDevice(t *testing.T) {
@@ -664,3 +905,558 @@ func TestDeleteDevice(t *testing.T) {
 	)
 	assert.Equal(http.StatusNotFound, getResponse.Code) // Device should no longer exist
 }
+
+// TestDeleteDeviceRecreate verifies that a device recreated with the id of a deleted one starts a new signature log
+func TestDeleteDeviceRecreate(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	deviceId := uuid.NewString()
+	create := func() {
+		response := makeRequest(assert, PostDeviceInputDto{Id: null.New(deviceId), SigningAlgorithm: domain.SigningAlgorithmEcc}, http.MethodPost, "/api/v0/device", api, nil)
+		assert.Equal(http.StatusCreated, response.Code)
+	}
+	sign := func() {
+		var out TypedResponse[PutDeviceSignOutputDto]
+		response := makeRequest(assert, PutDeviceSignInputDto{Data: "lorem ipsum"}, http.MethodPut, "/api/v0/device/"+deviceId+"/sign", api, &out)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.True(strings.HasPrefix(out.Data.SignedData, "1_"))
+	}
+
+	create()
+	sign()
+	response := makeRequest(assert, nil, http.MethodDelete, "/api/v0/device/"+deviceId, api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+
+	// Test case 1: The log of the deleted device isn't listed for the new one
+	create()
+	var out TypedResponse[ListDeviceSignatureOutputDto]
+	response = makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+deviceId+"/signature", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Empty(out.Data.Items)
+
+	// Test case 2: The new device signs starting with counter 1
+	sign()
+	response = makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+deviceId+"/signature", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Len(out.Data.Items, 1)
+}
+
+// TestPatchDevice verifies that mutable device attributes follow JSON Merge Patch semantics
+// This test covers setting, merging and clearing fields as well as disabling a device
+func TestPatchDevice(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	device := createDevice(
+		assert,
+		api,
+		domain.SigningAlgorithmEcc,
+	)
+	devicePath := fmt.Sprintf("/api/v0/device/%s", device.Id)
+
+	// Test case 1: Set label and metadata
+	{
+		var out TypedResponse[GetDeviceOutputDto]
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Label: null.Set("fooSigner"),
+				Metadata: null.Set(map[string]null.Null[string]{
+					"store":    null.New("berlin-1"),
+					"register": null.New("3"),
+				}),
+			},
+			http.MethodPatch,
+			devicePath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal("fooSigner", out.Data.Label.Some())
+		assert.Equal(domain.DeviceStatusActive, out.Data.Status)
+		assert.Equal(map[string]string{"store": "berlin-1", "register": "3"}, out.Data.Metadata)
+	}
+
+	// Test case 2: Absent label is left untouched, null metadata entries are removed
+	{
+		var out TypedResponse[GetDeviceOutputDto]
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Metadata: null.Set(map[string]null.Null[string]{
+					"register": null.Empty[string](),
+				}),
+			},
+			http.MethodPatch,
+			devicePath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal("fooSigner", out.Data.Label.Some())
+		assert.Equal(map[string]string{"store": "berlin-1"}, out.Data.Metadata)
+	}
+
+	// Test case 3: Label and metadata explicitly set to null are cleared
+	{
+		var out TypedResponse[GetDeviceOutputDto]
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Label:    null.Clear[string](),
+				Metadata: null.Clear[map[string]null.Null[string]](),
+			},
+			http.MethodPatch,
+			devicePath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.False(out.Data.Label.Filled())
+		assert.Empty(out.Data.Metadata)
+	}
+
+	// Test case 4: Status can not be cleared or set to an unknown value
+	for _, status := range []null.Patch[domain.DeviceStatus]{
+		null.Clear[domain.DeviceStatus](),
+		null.Set(domain.DeviceStatus("foo")),
+	} {
+		var out Problem
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Status: status,
+			},
+			http.MethodPatch,
+			devicePath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
+	}
+
+	// Test case 5: A disabled device rejects signing requests
+	{
+		var out TypedResponse[GetDeviceOutputDto]
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Status: null.Set(domain.DeviceStatusDisabled),
+			},
+			http.MethodPatch,
+			devicePath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal(domain.DeviceStatusDisabled, out.Data.Status)
+
+		signResponse := makeRequest(
+			assert,
+			PutDeviceSignInputDto{
+				Data: "foo",
+			},
+			http.MethodPut,
+			fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusConflict, signResponse.Code)
+	}
+
+	// Test case 6: Patching a non-existent device
+	{
+		var out Problem
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Label: null.Set("bar"),
+			},
+			http.MethodPatch,
+			"/api/v0/device/993d8948-cb1b-4ce8-98f8-f8b866578faf",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusNotFound, response.Code)
+		assert.Equal(apiError.CodeDeviceNotFound, out.Code)
+	}
+}
+
+// TestListDeviceFilter verifies that devices can be filtered by tags and metadata
+// This test also ensures the filters follow changes made through PATCH
+func TestListDeviceFilter(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	createTaggedDevice := func(metadata map[string]string, tags ...string) PostDeviceOutputDto {
+		var out TypedResponse[PostDeviceOutputDto]
+		response := makeRequest(
+			assert,
+			PostDeviceInputDto{
+				SigningAlgorithm: domain.SigningAlgorithmEcc,
+				Metadata:         metadata,
+				Tags:             tags,
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusCreated, response.Code)
+		return out.Data
+	}
+	listDeviceIds := func(query string) []string {
+		// makeRequest escapes the path, so the query is passed to the request directly
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/api/v0/device?"+query, nil)
+		res := httptest.NewRecorder()
+		api.ServeHTTP(res, req)
+		assert.Equal(http.StatusOK, res.Code)
+
+		var out TypedResponse[ListDeviceOutputDto]
+		assert.NoError(json.NewDecoder(res.Body).Decode(&out))
+
+		var ids []string
+		for _, item := range out.Data.Items {
+			ids = append(ids, item.Id)
+		}
+		return ids
+	}
+
+	device1 := createTaggedDevice(map[string]string{"store": "berlin-1", "env": "prod"}, "pos", "fiscal")
+	device2 := createTaggedDevice(map[string]string{"store": "berlin-2", "env": "prod"}, "pos", "pos")
+	device3 := createTaggedDevice(map[string]string{"store": "berlin-1", "env": "test"})
+
+	assert.Equal([]string{"fiscal", "pos"}, device1.Tags)
+	assert.Equal([]string{"pos"}, device2.Tags) // Duplicate tags are collapsed
+
+	assert.Equal([]string{device1.Id, device2.Id}, listDeviceIds("tag=pos"))
+	assert.Equal([]string{device1.Id}, listDeviceIds("tag=pos&tag=fiscal"))
+	assert.Equal([]string{device1.Id, device3.Id}, listDeviceIds("meta.store=berlin-1"))
+	assert.Equal([]string{device1.Id}, listDeviceIds("meta.store=berlin-1&tag=pos"))
+	assert.Empty(listDeviceIds("meta.store=munich"))
+
+	// Patching tags and metadata is reflected by the filters
+	{
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{
+				Tags: null.Set([]string{"pos"}),
+				Metadata: null.Set(map[string]null.Null[string]{
+					"store": null.New("berlin-1"),
+				}),
+			},
+			http.MethodPatch,
+			fmt.Sprintf("/api/v0/device/%s", device2.Id),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	assert.Equal([]string{device1.Id, device2.Id, device3.Id}, listDeviceIds("meta.store=berlin-1"))
+	assert.Equal([]string{device1.Id, device2.Id}, listDeviceIds("meta.store=berlin-1&meta.env=prod&tag=pos"))
+
+	// Deleted devices are removed from the filters
+	{
+		response := makeRequest(
+			assert,
+			nil,
+			http.MethodDelete,
+			fmt.Sprintf("/api/v0/device/%s", device1.Id),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	assert.Equal([]string{device2.Id}, listDeviceIds("tag=pos"))
+
+	// Invalid filters and metadata are rejected
+	{
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/api/v0/device?tag=NOT%20VALID", nil)
+		res := httptest.NewRecorder()
+		api.ServeHTTP(res, req)
+		assert.Equal(http.StatusBadRequest, res.Code)
+	}
+	{
+		var out Problem
+		response := makeRequest(
+			assert,
+			PostDeviceInputDto{
+				SigningAlgorithm: domain.SigningAlgorithmEcc,
+				Metadata:         map[string]string{"Invalid Key": "foo"},
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
+	}
+}
+
+// TestSignIdempotent verifies that retried signing requests with the same Idempotency-Key are not signed twice
+// This test covers replays, reuse of a key for different data and expiry of stored results
+func TestSignIdempotent(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	device := createDevice(
+		assert,
+		api,
+		domain.SigningAlgorithmEcc,
+	)
+	signPath := fmt.Sprintf("/api/v0/device/%s/sign", device.Id)
+	idempotencyKey := func(key string) http.Header {
+		return http.Header{IdempotencyKeyHeader: []string{key}}
+	}
+
+	// Test case 1: A retry with the same key and data returns the original signature
+	var firstSignDto TypedResponse[PutDeviceSignOutputDto]
+	{
+		response := makeRequestWithHeader(
+			assert,
+			idempotencyKey("retry-1"),
+			PutDeviceSignInputDto{Data: "lorem ipsum"},
+			http.MethodPut,
+			signPath,
+			api,
+			&firstSignDto,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Empty(response.Header().Get(IdempotentReplayedHeader))
+	}
+	{
+		var signDto TypedResponse[PutDeviceSignOutputDto]
+		response := makeRequestWithHeader(
+			assert,
+			idempotencyKey("retry-1"),
+			PutDeviceSignInputDto{Data: "lorem ipsum"},
+			http.MethodPut,
+			signPath,
+			api,
+			&signDto,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal("true", response.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(firstSignDto.Data, signDto.Data)
+	}
+
+	// Test case 2: Reusing the key for different data is rejected
+	{
+		var out Problem
+		response := makeRequestWithHeader(
+			assert,
+			idempotencyKey("retry-1"),
+			PutDeviceSignInputDto{Data: "dolor sit amet"},
+			http.MethodPut,
+			signPath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusUnprocessableEntity, response.Code)
+		assert.Equal(apiError.CodeIdempotencyKeyReused, out.Code)
+	}
+
+	// Test case 3: A different key creates a new signature
+	{
+		var signDto TypedResponse[PutDeviceSignOutputDto]
+		response := makeRequestWithHeader(
+			assert,
+			idempotencyKey("retry-2"),
+			PutDeviceSignInputDto{Data: "lorem ipsum"},
+			http.MethodPut,
+			signPath,
+			api,
+			&signDto,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.True(strings.HasPrefix(signDto.Data.SignedData, "2_"))
+	}
+
+	// Test case 4: Keys must be visible ascii characters
+	{
+		var out Problem
+		response := makeRequestWithHeader(
+			assert,
+			idempotencyKey("not valid"),
+			PutDeviceSignInputDto{Data: "lorem ipsum"},
+			http.MethodPut,
+			signPath,
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeInvalidRequest, out.Code)
+	}
+
+	var out TypedResponse[GetDeviceOutputDto]
+	getResponse := makeRequest(
+		assert,
+		nil,
+		http.MethodGet,
+		fmt.Sprintf("/api/v0/device/%s", device.Id),
+		api,
+		&out,
+	)
+	assert.Equal(http.StatusOK, getResponse.Code)
+	assert.Equal(2, out.Data.SignatureCounter) // Replays don't advance the counter
+}
+
+// TestSignIdempotentExpired verifies that stored results are no longer replayed after the configured TTL
+func TestSignIdempotentExpired(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithIdempotencyTTL(time.Nanosecond)).mux()
+
+	device := createDevice(
+		assert,
+		api,
+		domain.SigningAlgorithmEcc,
+	)
+
+	for _, expectedCounter := range []string{"1", "2"} {
+		var signDto TypedResponse[PutDeviceSignOutputDto]
+		response := makeRequestWithHeader(
+			assert,
+			http.Header{IdempotencyKeyHeader: []string{"retry-1"}},
+			PutDeviceSignInputDto{Data: "lorem ipsum"},
+			http.MethodPut,
+			fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+			api,
+			&signDto,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal(expectedCounter, strings.SplitN(signDto.Data.SignedData, "_", 2)[0])
+	}
+}
+
+// TestSignIdempotentDeletedDevice verifies that a device recreated with the id of a deleted one doesn't replay its signatures
+func TestSignIdempotentDeletedDevice(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	deviceId := uuid.NewString()
+	sign := func() *httptest.ResponseRecorder {
+		response := makeRequest(assert, PostDeviceInputDto{Id: null.New(deviceId), SigningAlgorithm: domain.SigningAlgorithmEcc}, http.MethodPost, "/api/v0/device", api, nil)
+		assert.Equal(http.StatusCreated, response.Code)
+		response = makeRequestWithHeader(
+			assert,
+			http.Header{IdempotencyKeyHeader: []string{"retry-1"}},
+			PutDeviceSignInputDto{Data: "lorem ipsum"},
+			http.MethodPut,
+			"/api/v0/device/"+deviceId+"/sign",
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+		return response
+	}
+
+	sign()
+	response := makeRequest(assert, nil, http.MethodDelete, "/api/v0/device/"+deviceId, api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+
+	response = sign()
+	assert.Empty(response.Header().Get(IdempotentReplayedHeader))
+}
+
+// TestSweepIdempotency verifies that expired idempotency records are removed in the background
+func TestSweepIdempotency(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	server := NewServer(storage, locker, WithIdempotencyTTL(time.Nanosecond))
+	api := server.mux()
+
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	response := makeRequestWithHeader(
+		assert,
+		http.Header{IdempotencyKeyHeader: []string{"retry-1"}},
+		PutDeviceSignInputDto{Data: "lorem ipsum"},
+		http.MethodPut,
+		fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+		api,
+		nil,
+	)
+	assert.Equal(http.StatusOK, response.Code)
+
+	records := func() int {
+		stats, err := storage.Stats(context.Background())
+		assert.NoError(err)
+		return stats.Records["idempotency"]
+	}
+	assert.Equal(1, records())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.DeviceManager().SweepIdempotency(ctx, time.Millisecond)
+	assert.Eventually(func() bool { return records() == 0 }, time.Second, time.Millisecond)
+}
+
+// TestListSignatures verifies that the signature log of a device can be read page by page
+func TestListSignatures(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	var signed []PutDeviceSignOutputDto
+	for _, data := range []string{"a", "b", "c"} {
+		var out TypedResponse[PutDeviceSignOutputDto]
+		response := makeRequest(assert, PutDeviceSignInputDto{Data: data}, http.MethodPut, "/api/v0/device/"+device.Id+"/sign", api, &out)
+		assert.Equal(http.StatusOK, response.Code)
+		signed = append(signed, out.Data)
+	}
+
+	// Test case 1: The log matches the signing responses
+	var out TypedResponse[ListDeviceSignatureOutputDto]
+	response := makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+device.Id+"/signature", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Len(out.Data.Items, 3)
+	for i, signature := range out.Data.Items {
+		assert.Equal(i+1, signature.Counter)
+		assert.Equal(signed[i].Signature, signature.Signature)
+		assert.Equal(signed[i].SignedData, signature.SignedData)
+		assert.Equal(1, signature.KeyVersion)
+	}
+	deviceId := uuid.MustParse(device.Id)
+	assert.Equal(base64.StdEncoding.EncodeToString(deviceId[:]), out.Data.Items[0].LastSignature)
+	assert.Equal(out.Data.Items[0].Signature, out.Data.Items[1].LastSignature)
+
+	// Test case 2: Pages continue after a counter
+	out = TypedResponse[ListDeviceSignatureOutputDto]{}
+	response = makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+device.Id+"/signature?after=1&limit=1", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Len(out.Data.Items, 1)
+	assert.Equal(2, out.Data.Items[0].Counter)
+
+	// Test case 3: Unknown devices and invalid pages are rejected
+	response = makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+uuid.NewString()+"/signature", api, nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+	for _, query := range []string{"limit=0", "limit=501", "after=-1"} {
+		response = makeRequest(assert, nil, http.MethodGet, "/api/v0/device/"+device.Id+"/signature?"+query, api, nil)
+		assert.Equal(http.StatusBadRequest, response.Code, query)
+	}
+}
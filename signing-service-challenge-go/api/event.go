@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/eventbus"
+)
+
+// eventKeepAlive is the interval of comments sent on idle streams, so proxies don't close the connection
+const eventKeepAlive = 15 * time.Second
+
+type EventHandler struct {
+	bus     *eventbus.Bus
+	devices *deviceManager.Handler
+	// closed ends all open streams when the server shuts down, they would keep it from draining otherwise
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewEventHandler(
+	bus *eventbus.Bus,
+	devices *deviceManager.Handler,
+) *EventHandler {
+	return &EventHandler{
+		bus:     bus,
+		devices: devices,
+		closed:  make(chan struct{}),
+	}
+}
+
+// Close ends all open event streams on shutdown. Event ids are sequences of this process and restart at 1,
+// so the Last-Event-ID only resumes a stream on the same process, clients of another instance start a new stream.
+func (e *EventHandler) Close() {
+	e.closeOnce.Do(func() { close(e.closed) })
+}
+
+// EventOutputDto is the data of a server-sent event, it matches the payload of webhook deliveries
+type EventOutputDto struct {
+	Id             string           `json:"id"`
+	Type           domain.EventType `json:"type"`
+	OrganizationId string           `json:"organization_id"`
+	DeviceId       string           `json:"device_id"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Data           json.RawMessage  `json:"data"`
+}
+
+// NewEventOutputDto maps a [domain.Event] to its public representation.
+func NewEventOutputDto(event *domain.Event) EventOutputDto {
+	return EventOutputDto{
+		Id:             event.Id.String(),
+		Type:           event.Type,
+		OrganizationId: event.OrganizationId.String(),
+		DeviceId:       event.DeviceId.String(),
+		CreatedAt:      event.CreatedAt,
+		Data:           event.Data,
+	}
+}
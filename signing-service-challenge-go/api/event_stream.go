@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/eventbus"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// LastEventIdHeader is sent by clients reconnecting to an event stream, see the SSE specification
+const LastEventIdHeader = "Last-Event-ID"
+
+// Stream sends the events of all devices of the organization as server-sent events.
+func (e *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	organizationId, _ := domain.OrganizationFromContext(r.Context())
+
+	e.stream(w, r, func(event *domain.Event) bool {
+		return event.OrganizationId == organizationId
+	})
+}
+
+// StreamDevice sends the events of a single device as server-sent events.
+func (e *EventHandler) StreamDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	// the device has to be visible to the client, events of other organizations must not leak
+	device, err := e.devices.GetDevice(ctx, deviceId)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	e.stream(w, r, func(event *domain.Event) bool {
+		return event.OrganizationId == device.OrganizationId && event.DeviceId == device.Id
+	})
+}
+
+// stream writes matching events until the client disconnects.
+// Events after the Last-Event-ID are replayed first, as far as they are still kept by the bus of this process.
+func (e *EventHandler) stream(w http.ResponseWriter, r *http.Request, match func(event *domain.Event) bool) {
+	ctx := r.Context()
+
+	var lastEventId uint64
+	if value := r.Header.Get(LastEventIdHeader); value != "" {
+		var err error
+		lastEventId, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, "invalid "+LastEventIdHeader, err.Error())
+			return
+		}
+	}
+
+	subscription, backlog := e.bus.Subscribe(lastEventId, match)
+	defer subscription.Close()
+
+	// streams are exempt from the read and write timeouts of the server, the keep-alive detects dead clients
+	controller := http.NewResponseController(w)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for _, message := range backlog {
+		if err := writeEvent(w, message); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		domain.LoggerFromContext(r.Context()).Error("event stream not supported", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.closed:
+			return
+		case message, open := <-subscription.Messages():
+			if !open {
+				// the client fell behind, it reconnects and resumes with the last id it received
+				return
+			}
+			if err := writeEvent(w, message); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes a message in the server-sent event format, its sequence is used as id to resume from
+func writeEvent(w http.ResponseWriter, message eventbus.Message) error {
+	data, err := json.Marshal(NewEventOutputDto(message.Event))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.Sequence, message.Event.Type, data)
+	return err
+}
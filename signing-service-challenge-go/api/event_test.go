@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// sentEvent is a server-sent event as read from a stream
+type sentEvent struct {
+	Id    string
+	Event string
+	Data  EventOutputDto
+}
+
+// eventStream is a helper to connect to an event stream of the server
+type eventStream struct {
+	response *http.Response
+	reader   *bufio.Reader
+	cancel   context.CancelFunc
+}
+
+func openEventStream(
+	assert *require.Assertions,
+	server *httptest.Server,
+	urlPath string,
+	header http.Header,
+) *eventStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+urlPath, nil)
+	assert.NoError(err)
+	for key, values := range header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	response, err := server.Client().Do(request)
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, response.StatusCode)
+	assert.Equal("text/event-stream", response.Header.Get("Content-Type"))
+
+	return &eventStream{
+		response: response,
+		reader:   bufio.NewReader(response.Body),
+		cancel:   cancel,
+	}
+}
+
+// next reads the next event from the stream, skipping comments
+func (s *eventStream) next(assert *require.Assertions) sentEvent {
+	var event sentEvent
+	for {
+		line, err := s.reader.ReadString('\n')
+		assert.NoError(err)
+		line = strings.TrimSuffix(line, "\n")
+
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "":
+			if event.Id != "" {
+				return event
+			}
+		case "id":
+			event.Id = value
+		case "event":
+			event.Event = value
+		case "data":
+			assert.NoError(json.Unmarshal([]byte(value), &event.Data))
+		}
+	}
+}
+
+func (s *eventStream) close() {
+	s.cancel()
+	s.response.Body.Close()
+}
+
+// TestEventStream verifies that device events are streamed to connected clients
+// This test also covers resuming a stream with the Last-Event-ID header
+func TestEventStream(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	all := openEventStream(assert, server, "/api/v0/events", nil)
+	defer all.close()
+
+	// Test case 1: Events are streamed in the order they happened
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	makeRequest(assert, PutDeviceSignInputDto{Data: "foo"}, http.MethodPut, fmt.Sprintf("/api/v0/device/%s/sign", device.Id), api, nil)
+
+	created := all.next(assert)
+	assert.Equal("1", created.Id)
+	assert.Equal(string(domain.EventDeviceCreated), created.Event)
+	assert.Equal(domain.EventDeviceCreated, created.Data.Type)
+	assert.Equal(device.Id, created.Data.DeviceId)
+
+	signed := all.next(assert)
+	assert.Equal("2", signed.Id)
+	assert.Equal(domain.EventSignatureCreated, signed.Data.Type)
+	assert.JSONEq(fmt.Sprintf(`{"device_id":%q,"signature_counter":1,"signature":%q}`, device.Id, func() string {
+		var data domain.SignatureCreatedEventData
+		assert.NoError(json.Unmarshal(signed.Data.Data, &data))
+		return data.Signature
+	}()), string(signed.Data.Data))
+
+	// Test case 2: The device stream only contains events of the device
+	single := openEventStream(assert, server, fmt.Sprintf("/api/v0/device/%s/events", device.Id), nil)
+	defer single.close()
+
+	other := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	makeRequest(assert, PutDeviceSignInputDto{Data: "bar"}, http.MethodPut, fmt.Sprintf("/api/v0/device/%s/sign", device.Id), api, nil)
+
+	event := single.next(assert)
+	assert.Equal("4", event.Id)
+	assert.Equal(device.Id, event.Data.DeviceId)
+	assert.Equal(other.Id, all.next(assert).Data.DeviceId)
+	assert.Equal("4", all.next(assert).Id)
+
+	// Test case 3: Reconnecting with Last-Event-ID replays missed events first
+	resumed := openEventStream(assert, server, "/api/v0/events", http.Header{LastEventIdHeader: []string{"2"}})
+	defer resumed.close()
+	assert.Equal("3", resumed.next(assert).Id)
+	assert.Equal("4", resumed.next(assert).Id)
+
+	resumedDevice := openEventStream(assert, server, fmt.Sprintf("/api/v0/device/%s/events", device.Id), http.Header{LastEventIdHeader: []string{"1"}})
+	defer resumedDevice.close()
+	assert.Equal("2", resumedDevice.next(assert).Id)
+	assert.Equal("4", resumedDevice.next(assert).Id)
+
+	// Test case 4: Deleting the device ends with device.deleted
+	makeRequest(assert, nil, http.MethodDelete, fmt.Sprintf("/api/v0/device/%s", device.Id), api, nil)
+	assert.Equal(domain.EventDeviceDeleted, single.next(assert).Data.Type)
+	assert.Equal(domain.EventDeviceDeleted, resumed.next(assert).Data.Type)
+
+	// Test case 5: Invalid requests are rejected before the stream starts
+	response := makeRequestWithHeader(assert, http.Header{LastEventIdHeader: []string{"abc"}}, nil, http.MethodGet, "/api/v0/events", api, nil)
+	assert.Equal(http.StatusBadRequest, response.Code)
+	response = makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/device/%s/events", uuid.New()), api, nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+}
+
+// TestEventStreamOrganization verifies that streams only contain events of the client's organization
+func TestEventStreamOrganization(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	organization := createOrganization(assert, api, "other")
+	header := http.Header{OrganizationHeader: []string{organization.Id}}
+
+	stream := openEventStream(assert, server, "/api/v0/events", nil)
+	defer stream.close()
+
+	var device TypedResponse[PostDeviceOutputDto]
+	response := makeRequestWithHeader(
+		assert,
+		header,
+		PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+		http.MethodPost,
+		"/api/v0/device",
+		api,
+		&device,
+	)
+	assert.Equal(http.StatusCreated, response.Code)
+	own := createDevice(assert, api, domain.SigningAlgorithmEcc)
+
+	// the event of the other organization is skipped
+	assert.Equal(own.Id, stream.next(assert).Data.DeviceId)
+
+	// devices of other organizations can't be streamed
+	response = makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/device/%s/events", device.Data.Id), api, nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+
+	// resuming doesn't replay events of other organizations either
+	resumed := openEventStream(assert, server, "/api/v0/events", http.Header{LastEventIdHeader: []string{"0"}})
+	defer resumed.close()
+	resumedOther := openEventStream(assert, server, "/api/v0/events", http.Header{
+		LastEventIdHeader:  []string{"1"},
+		OrganizationHeader: []string{organization.Id},
+	})
+	defer resumedOther.close()
+	makeRequest(assert, PutDeviceSignInputDto{Data: "foo"}, http.MethodPut, fmt.Sprintf("/api/v0/device/%s/sign", own.Id), api, nil)
+	assert.Equal("3", resumed.next(assert).Id)
+	assert.Equal(uuid.Nil.String(), stream.next(assert).Data.OrganizationId)
+
+	makeRequestWithHeader(assert, header, PutDeviceSignInputDto{Data: "foo"}, http.MethodPut, fmt.Sprintf("/api/v0/device/%s/sign", device.Data.Id), api, nil)
+	assert.Equal("4", resumedOther.next(assert).Id)
+}
@@ -1,23 +1,86 @@
 package api
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/health"
+)
+
+const (
+	// livenessPath tells whether the process works, it doesn't check any dependencies
+	livenessPath = "/livez"
+	// readinessPath tells whether the service can serve requests, see [WithHealthCheck]
+	readinessPath = "/readyz"
+)
 
 type HealthResponse struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
+	Status  health.Status `json:"status"`
+	Version string        `json:"version"`
+	// Checks holds the result of every check by name, liveness doesn't run any checks
+	Checks map[string]HealthCheckOutputDto `json:"checks,omitempty"`
+}
+
+type HealthCheckOutputDto struct {
+	Status health.Status `json:"status"`
+	// Critical checks fail the service, others only degrade it
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// WithHealthCheck adds a check to the readiness of the server, next to the checks of storage, locks and keys.
+// A check named like one of those replaces it.
+func WithHealthCheck(name string, check health.CheckFunc, options ...health.Option) Option {
+	return func(c *config) {
+		c.healthChecks = append(c.healthChecks, func(registry *health.Registry) {
+			registry.Register(name, check, options...)
+		})
+	}
 }
 
 // Health evaluates the health of the service and writes a standardized response.
 func (s *Server) Health(response http.ResponseWriter, request *http.Request) {
 	if request.Method != http.MethodGet {
-		WriteErrorResponse(response, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		WriteErrorResponse(response, request, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
 		return
 	}
+	s.Readiness(response, request)
+}
 
-	health := HealthResponse{
-		Status:  "pass",
+// Liveness responds as long as the server handles requests, so a supervisor only restarts a stuck process.
+func (s *Server) Liveness(response http.ResponseWriter, request *http.Request) {
+	WriteAPIResponse(response, http.StatusOK, HealthResponse{
+		Status:  health.StatusPass,
 		Version: "v0",
+	})
+}
+
+// Readiness runs all health checks. A degraded service is still ready, a failed one or one shutting down is not.
+func (s *Server) Readiness(response http.ResponseWriter, request *http.Request) {
+	report := s.health.Run(request.Context())
+	if s.draining.Load() {
+		report.Status = health.StatusFail
+		report.Checks["shutdown"] = health.Result{Status: health.StatusFail, Critical: true, Error: "server is shutting down"}
 	}
 
-	WriteAPIResponse(response, http.StatusOK, health)
+	output := HealthResponse{
+		Status:  report.Status,
+		Version: "v0",
+		Checks:  make(map[string]HealthCheckOutputDto, len(report.Checks)),
+	}
+	for name, result := range report.Checks {
+		output.Checks[name] = HealthCheckOutputDto{
+			Status:    result.Status,
+			Critical:  result.Critical,
+			LatencyMs: float64(result.Latency) / float64(time.Millisecond),
+			Error:     result.Error,
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status == health.StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+	WriteAPIResponse(response, status, output)
 }
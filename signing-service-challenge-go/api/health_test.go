@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/health"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// unhealthyStorage is a storage whose health check fails
+type unhealthyStorage struct {
+	persistence.Storage
+}
+
+func (unhealthyStorage) Health(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// unavailableLocker is a locker whose locks can't be acquired
+type unavailableLocker struct {
+	lock.Locker[uuid.UUID]
+}
+
+func (unavailableLocker) Acquire(context.Context, uuid.UUID) (lock.Lock, error) {
+	return nil, errors.New("lock unavailable")
+}
+
+// TestLiveness verifies that liveness passes without running any checks
+func TestLiveness(t *testing.T) {
+	assert := require.New(t)
+
+	server := NewServer(unhealthyStorage{persistence.NewMemoryStorage()}, lock.NewMemoryLocker[uuid.UUID]())
+	api := server.mux()
+
+	var out TypedResponse[HealthResponse]
+	response := makeRequest(assert, nil, http.MethodGet, "/livez", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal(health.StatusPass, out.Data.Status)
+	assert.Empty(out.Data.Checks)
+}
+
+// TestReadiness verifies that readiness reports every check and fails on critical failures only
+func TestReadiness(t *testing.T) {
+	assert := require.New(t)
+
+	// Test case 1: All dependencies pass
+	server := NewServer(persistence.NewMemoryStorage(), lock.NewMemoryLocker[uuid.UUID]())
+	api := server.mux()
+
+	var out TypedResponse[HealthResponse]
+	response := makeRequest(assert, nil, http.MethodGet, "/readyz", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal(health.StatusPass, out.Data.Status)
+	assert.Len(out.Data.Checks, 3)
+	for _, name := range []string{"storage", "lock", "keys"} {
+		assert.Equal(health.StatusPass, out.Data.Checks[name].Status, name)
+		assert.True(out.Data.Checks[name].Critical, name)
+	}
+
+	// Test case 2: A failing non-critical check degrades the service, it stays ready
+	server = NewServer(
+		persistence.NewMemoryStorage(),
+		lock.NewMemoryLocker[uuid.UUID](),
+		WithHealthCheck("disk", func(context.Context) error { return errors.New("disk full") }, health.NonCritical()),
+	)
+	api = server.mux()
+
+	out = TypedResponse[HealthResponse]{}
+	response = makeRequest(assert, nil, http.MethodGet, "/readyz", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal(health.StatusWarn, out.Data.Status)
+	assert.Equal(HealthCheckOutputDto{Status: health.StatusWarn, Error: "disk full", LatencyMs: out.Data.Checks["disk"].LatencyMs}, out.Data.Checks["disk"])
+
+	// Test case 3: Failing storage makes the service unavailable
+	server = NewServer(unhealthyStorage{persistence.NewMemoryStorage()}, lock.NewMemoryLocker[uuid.UUID]())
+	api = server.mux()
+
+	for _, path := range []string{"/readyz", "/api/v0/health"} {
+		out = TypedResponse[HealthResponse]{}
+		response = makeRequest(assert, nil, http.MethodGet, path, api, &out)
+		assert.Equal(http.StatusServiceUnavailable, response.Code, path)
+		assert.Equal(health.StatusFail, out.Data.Status, path)
+		assert.Equal(health.StatusFail, out.Data.Checks["storage"].Status, path)
+		assert.Equal("connection refused", out.Data.Checks["storage"].Error, path)
+		assert.Equal(health.StatusPass, out.Data.Checks["keys"].Status, path)
+	}
+
+	// Test case 4: The lock check can be replaced, e.g. to probe the locker before it is instrumented
+	server = NewServer(
+		persistence.NewMemoryStorage(),
+		unavailableLocker{},
+		WithHealthCheck("lock", health.Locker(lock.NewMemoryLocker[uuid.UUID](), uuid.Nil)),
+	)
+	api = server.mux()
+
+	out = TypedResponse[HealthResponse]{}
+	response = makeRequest(assert, nil, http.MethodGet, "/readyz", api, &out)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Len(out.Data.Checks, 3)
+	assert.Equal(health.StatusPass, out.Data.Checks["lock"].Status)
+}
+
+// TestReadinessWhileDraining verifies that a server shutting down is not ready anymore, but still alive
+func TestReadinessWhileDraining(t *testing.T) {
+	assert := require.New(t)
+
+	server := NewServer(persistence.NewMemoryStorage(), lock.NewMemoryLocker[uuid.UUID]())
+	api := server.mux()
+	server.draining.Store(true)
+
+	var out TypedResponse[HealthResponse]
+	response := makeRequest(assert, nil, http.MethodGet, "/readyz", api, &out)
+	assert.Equal(http.StatusServiceUnavailable, response.Code)
+	assert.Equal(health.StatusFail, out.Data.Status)
+	assert.Equal(health.StatusFail, out.Data.Checks["shutdown"].Status)
+
+	response = makeRequest(assert, nil, http.MethodGet, "/livez", api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+}
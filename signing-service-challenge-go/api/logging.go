@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader correlates a request with its log lines, it is taken from the client or generated
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+type requestIDContextKey struct{}
+
+// RequestIDFromContext returns the id of the request the context belongs to
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIDContextKey{}).(string)
+	return requestId, ok
+}
+
+// RequestID is a middleware assigning every request an id, which is returned in the X-Request-ID header
+// and logged with every line logged on behalf of the request. Ids sent by clients are kept if they are valid.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestId) {
+			requestId = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestId)
+
+		ctx := context.WithValue(r.Context(), requestIDContextKey{}, requestId)
+		ctx = domain.WithLogAttrs(ctx, "request_id", requestId)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts ids of printable ASCII characters, so they can't forge log lines or headers
+func validRequestID(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range []byte(requestId) {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// accessLogContextKey refers to the client of the access log line, which is only known once inner middleware authenticated it
+type accessLogContextKey struct{}
+
+// AccessLog is a middleware logging every request once the response is written,
+// with the status code, the number of bytes written and the duration
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		var client string
+		// the wrapper passes flushes on, so event streams keep working
+		recorder := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), accessLogContextKey{}, &client)))
+
+		status := recorder.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attributes := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", recorder.BytesWritten()),
+			slog.Duration("duration", time.Since(started)),
+		}
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+			attributes = append(attributes, slog.String("route", routeContext.RoutePattern()))
+		}
+		if client != "" {
+			attributes = append(attributes, slog.String("client", client))
+		}
+		domain.LoggerFromContext(r.Context()).LogAttrs(r.Context(), level, "request completed", attributes...)
+	})
+}
+
+// LogClient is a middleware adding the authenticated client to the logger and the access log line of the request,
+// it has to run after the client was authenticated
+func LogClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if client := domain.ClientIdentity(r.Context()); client != "" {
+			if accessLogClient, ok := r.Context().Value(accessLogContextKey{}).(*string); ok {
+				*accessLogClient = client
+			}
+			r = r.WithContext(domain.WithLogAttrs(r.Context(), "client", client))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
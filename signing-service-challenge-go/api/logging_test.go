@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// withLogger is a helper function serving requests with a logger writing json lines to the buffer
+func withLogger(api http.Handler, logged *bytes.Buffer) http.Handler {
+	logger := slog.New(slog.NewJSONHandler(logged, nil))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.ServeHTTP(w, r.WithContext(domain.WithLogger(r.Context(), logger)))
+	})
+}
+
+// logLines is a helper function decoding the json lines logged with the message
+func logLines(assert *require.Assertions, logged *bytes.Buffer, message string) []map[string]any {
+	var lines []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(logged.Bytes()))
+	for scanner.Scan() {
+		var line map[string]any
+		assert.NoError(json.Unmarshal(scanner.Bytes(), &line))
+		if line["msg"] == message {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestRequestID(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	// Test case 1: A request id of the client is kept
+	response := makeRequestWithHeader(assert, http.Header{RequestIDHeader: {"client-id-1"}}, nil, http.MethodGet, "/api/v0/health", api, nil)
+	assert.Equal("client-id-1", response.Header().Get(RequestIDHeader))
+
+	// Test case 2: A request id is generated if the client didn't send one
+	response = makeRequest(assert, nil, http.MethodGet, "/api/v0/health", api, nil)
+	_, err := uuid.Parse(response.Header().Get(RequestIDHeader))
+	assert.NoError(err)
+
+	// Test case 3: Invalid request ids are replaced
+	for _, requestId := range []string{"with space", strings.Repeat("a", maxRequestIDLength+1), "line\nbreak"} {
+		response = makeRequestWithHeader(assert, http.Header{RequestIDHeader: {requestId}}, nil, http.MethodGet, "/api/v0/health", api, nil)
+		_, err := uuid.Parse(response.Header().Get(RequestIDHeader))
+		assert.NoError(err)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	logged := new(bytes.Buffer)
+	api := withLogger(NewServer(storage, locker, WithAuthentication(testBootstrapKey)).mux(), logged)
+
+	var device TypedResponse[PostDeviceOutputDto]
+	response := makeRequestWithHeader(
+		assert,
+		http.Header{"Authorization": {"Bearer " + testBootstrapKey}, RequestIDHeader: {"create-device"}},
+		PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+		http.MethodPost,
+		"/api/v0/device",
+		api,
+		&device,
+	)
+	assert.Equal(http.StatusCreated, response.Code)
+
+	// Test case 1: The request is logged once it is completed
+	lines := logLines(assert, logged, "request completed")
+	assert.Len(lines, 1)
+	assert.Equal("create-device", lines[0]["request_id"])
+	assert.Equal(http.MethodPost, lines[0]["method"])
+	assert.Equal("/api/v0/device", lines[0]["route"])
+	assert.EqualValues(http.StatusCreated, lines[0]["status"])
+	assert.Greater(lines[0]["bytes"], 0.0)
+	assert.Contains(lines[0], "duration")
+	assert.True(strings.HasPrefix(lines[0]["client"].(string), "api-key:"))
+
+	// Test case 2: Lines logged by the device service name the request, the client and the device
+	unknownDevice := uuid.New()
+	response = makeRequestWithHeader(
+		assert,
+		http.Header{"Authorization": {"Bearer " + testBootstrapKey}, RequestIDHeader: {"sign-unknown"}},
+		PutDeviceSignInputDto{Data: "lorem ipsum"},
+		http.MethodPut,
+		fmt.Sprintf("/api/v0/device/%s/sign", unknownDevice),
+		api,
+		nil,
+	)
+	assert.Equal(http.StatusNotFound, response.Code)
+
+	lines = logLines(assert, logged, "failed fetching device")
+	assert.Len(lines, 1)
+	assert.Equal("sign-unknown", lines[0]["request_id"])
+	assert.Equal(unknownDevice.String(), lines[0]["device_id"])
+	assert.True(strings.HasPrefix(lines[0]["client"].(string), "api-key:"))
+
+	// Test case 3: Lines logged by the other services name the request and the client as well
+	response = makeRequestWithHeader(
+		assert,
+		http.Header{"Authorization": {"Bearer " + testBootstrapKey}, RequestIDHeader: {"get-unknown-webhook"}},
+		nil,
+		http.MethodGet,
+		fmt.Sprintf("/api/v0/webhook/%s", uuid.New()),
+		api,
+		nil,
+	)
+	assert.Equal(http.StatusNotFound, response.Code)
+
+	lines = logLines(assert, logged, "failed fetching webhook")
+	assert.Len(lines, 1)
+	assert.Equal("get-unknown-webhook", lines[0]["request_id"])
+	assert.True(strings.HasPrefix(lines[0]["client"].(string), "api-key:"))
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/metrics"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetrics verifies that requests, signatures and key generations show up in the metrics
+func TestMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	m := metrics.New()
+	m.CollectDevices(storage)
+	locker := metrics.InstrumentLocker(lock.NewMemoryLocker[uuid.UUID](), m)
+	api := NewServer(persistence.Instrument(storage, m.ObserveStorage), locker, WithMetrics(m)).mux()
+
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	response := makeRequest(
+		assert,
+		PutDeviceSignInputDto{Data: "lorem ipsum"},
+		http.MethodPut,
+		fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+		api,
+		nil,
+	)
+	assert.Equal(http.StatusOK, response.Code)
+
+	// Test case 1: The metrics are only served with the endpoint enabled
+	response = makeRequest(assert, nil, http.MethodGet, "/metrics", api, nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+
+	api = NewServer(persistence.Instrument(storage, m.ObserveStorage), locker, WithMetrics(m), WithMetricsEndpoint()).mux()
+	response = makeRequest(assert, nil, http.MethodGet, "/metrics", api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+
+	body := response.Body.String()
+	// Test case 2: Requests are labeled by route pattern and status
+	assert.Contains(body, `signing_service_http_requests_total{method="POST",route="/api/v0/device",status="201"} 1`)
+	assert.Contains(body, `signing_service_http_requests_total{method="PUT",route="/api/v0/device/{id}/sign",status="200"} 1`)
+	// Test case 3: Signing, key generation and lock waits are measured
+	assert.Contains(body, `signing_service_signing_duration_seconds_count{algorithm="ECC"} 1`)
+	assert.Contains(body, `signing_service_key_generation_duration_seconds_count{algorithm="ECC"} 1`)
+	assert.Contains(body, `signing_service_lock_wait_duration_seconds_count{result="acquired"}`)
+	// Test case 4: Devices are counted by algorithm
+	assert.Contains(body, `signing_service_devices{algorithm="ECC"} 1`)
+	assert.Contains(body, `signing_service_devices{algorithm="RSA"} 0`)
+}
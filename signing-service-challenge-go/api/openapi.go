@@ -0,0 +1,366 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/health"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/openapi"
+	"github.com/go-chi/chi/v5"
+)
+
+// openAPIPath serves the OpenAPI document of the server
+const openAPIPath = "/api/v0/openapi.json"
+
+// bearerScheme names the security scheme of API keys in the OpenAPI document
+const bearerScheme = "apiKey"
+
+var pathParameterPattern = regexp.MustCompile(`{([^}]+)}`)
+
+// operation documents a route, its method and path are taken from the routes registered in [Server.mux]
+type operation struct {
+	id      string
+	summary string
+	tag     string
+	// scope required from the API key, empty for public routes
+	scope domain.Scope
+	// organization marks routes scoped to the organization of the request, see [OrganizationHandler.Scope]
+	organization bool
+	input        reflect.Type
+	status       int
+	// data of successful responses, nil for responses without a body
+	output reflect.Type
+	// body of successful responses, which are not wrapped into [Response]
+	raw *openapi.Schema
+	// media type of raw, json if empty
+	mediaType string
+	// headers of successful responses
+	headers map[string]*openapi.Header
+	// further successful status codes, responded without a body
+	empty []int
+	// status code of failures responded with the output instead of an error, e.g. failed health checks
+	failure    int
+	parameters []*openapi.Parameter
+	// stream marks routes responding with server-sent events
+	stream bool
+}
+
+// operations documents all routes by "<method> <pattern>", undocumented routes are missing in the OpenAPI document
+var operations = map[string]operation{
+	"GET /api/v0/health": {
+		id: "getHealth", summary: "Get the health of the service, like the readiness", tag: "service",
+		status: http.StatusOK, output: reflect.TypeFor[HealthResponse](), failure: http.StatusServiceUnavailable,
+	},
+	"GET " + livenessPath: {
+		id: "getLiveness", summary: "Check whether the process is alive", tag: "service",
+		status: http.StatusOK, output: reflect.TypeFor[HealthResponse](),
+	},
+	"GET " + readinessPath: {
+		id: "getReadiness", summary: "Check whether the service and its dependencies can serve requests", tag: "service",
+		status: http.StatusOK, output: reflect.TypeFor[HealthResponse](), failure: http.StatusServiceUnavailable,
+	},
+	"GET " + openAPIPath: {
+		id: "getOpenAPI", summary: "Get the OpenAPI description of the API", tag: "service",
+		status: http.StatusOK, raw: &openapi.Schema{Type: openapi.Types{openapi.TypeObject}},
+	},
+	"GET " + metricsPath: {
+		id: "getMetrics", summary: "Get the metrics of the service in the Prometheus format", tag: "service",
+		status: http.StatusOK, raw: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}, mediaType: "text/plain",
+	},
+
+	"POST /api/v0/organization": {
+		id: "createOrganization", summary: "Create a new organization", tag: "organization", scope: domain.ScopePlatform,
+		input: reflect.TypeFor[PostOrganizationInputDto](), status: http.StatusCreated, output: reflect.TypeFor[GetOrganizationOutputDto](),
+	},
+	"GET /api/v0/organization": {
+		id: "listOrganizations", summary: "List all organizations", tag: "organization", scope: domain.ScopePlatform,
+		status: http.StatusOK, output: reflect.TypeFor[ListOrganizationOutputDto](),
+	},
+	"GET /api/v0/organization/{id}": {
+		id: "getOrganization", summary: "Get a specific organization", tag: "organization", scope: domain.ScopePlatform,
+		status: http.StatusOK, output: reflect.TypeFor[GetOrganizationOutputDto](),
+	},
+	"DELETE /api/v0/organization/{id}": {
+		id: "deleteOrganization", summary: "Delete an organization without devices", tag: "organization", scope: domain.ScopePlatform,
+		status: http.StatusOK,
+	},
+
+	"POST /api/v0/api-key": {
+		id: "createAPIKey", summary: "Create a new API key", tag: "api-key", scope: domain.ScopeAdmin, organization: true,
+		input: reflect.TypeFor[PostAPIKeyInputDto](), status: http.StatusCreated, output: reflect.TypeFor[PostAPIKeyOutputDto](),
+	},
+	"GET /api/v0/api-key": {
+		id: "listAPIKeys", summary: "List all API keys", tag: "api-key", scope: domain.ScopeAdmin, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[ListAPIKeyOutputDto](),
+	},
+	"DELETE /api/v0/api-key/{id}": {
+		id: "revokeAPIKey", summary: "Revoke an API key", tag: "api-key", scope: domain.ScopeAdmin, organization: true,
+		status: http.StatusOK,
+	},
+
+	"POST /api/v0/webhook": {
+		id: "createWebhook", summary: "Subscribe an endpoint to events", tag: "webhook", scope: domain.ScopeAdmin, organization: true,
+		input: reflect.TypeFor[PostWebhookInputDto](), status: http.StatusCreated, output: reflect.TypeFor[PostWebhookOutputDto](),
+	},
+	"GET /api/v0/webhook": {
+		id: "listWebhooks", summary: "List all webhooks", tag: "webhook", scope: domain.ScopeAdmin, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[ListWebhookOutputDto](),
+	},
+	"GET /api/v0/webhook/{id}": {
+		id: "getWebhook", summary: "Get a specific webhook", tag: "webhook", scope: domain.ScopeAdmin, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[GetWebhookOutputDto](),
+	},
+	"DELETE /api/v0/webhook/{id}": {
+		id: "deleteWebhook", summary: "Unsubscribe an endpoint", tag: "webhook", scope: domain.ScopeAdmin, organization: true,
+		status: http.StatusOK,
+	},
+	"GET /api/v0/webhook/{id}/delivery": {
+		id: "listWebhookDeliveries", summary: "Get the delivery log of a webhook", tag: "webhook", scope: domain.ScopeAdmin, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[ListWebhookDeliveryOutputDto](),
+		parameters: []*openapi.Parameter{{
+			Name: "limit", In: openapi.InQuery, Description: "maximum number of deliveries, newest first",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: bound(1), Maximum: bound(maxDeliveryLimit)},
+		}},
+	},
+
+	"POST /api/v0/device": {
+		id: "createDevice", summary: "Create a new device", tag: "device", scope: domain.ScopeDeviceWrite, organization: true,
+		input: reflect.TypeFor[PostDeviceInputDto](), status: http.StatusCreated, output: reflect.TypeFor[PostDeviceOutputDto](),
+	},
+	"GET /api/v0/device": {
+		id: "listDevices", summary: "List all devices", tag: "device", scope: domain.ScopeDeviceRead, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[ListDeviceOutputDto](),
+		parameters: []*openapi.Parameter{{
+			Name: "tag", In: openapi.InQuery, Description: "only devices carrying all given tags, can be repeated",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeArray}, Items: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+		}, {
+			Name: "limit", In: openapi.InQuery, Description: "maximum number of devices, all devices without a limit",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: bound(1), Maximum: bound(maxDeviceLimit)},
+		}, {
+			Name: "offset", In: openapi.InQuery, Description: "number of devices to skip, in the order of creation",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: bound(0)},
+		}},
+	},
+	"GET /api/v0/device/{id}": {
+		id: "getDevice", summary: "Get a specific device", tag: "device", scope: domain.ScopeDeviceRead, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[GetDeviceOutputDto](),
+	},
+	"PATCH /api/v0/device/{id}": {
+		id: "updateDevice", summary: "Update mutable attributes of a device", tag: "device", scope: domain.ScopeDeviceWrite, organization: true,
+		input: reflect.TypeFor[PatchDeviceInputDto](), status: http.StatusOK, output: reflect.TypeFor[GetDeviceOutputDto](),
+	},
+	"DELETE /api/v0/device/{id}": {
+		id: "deleteDevice", summary: "Delete a device", tag: "device", scope: domain.ScopeDeviceWrite, organization: true,
+		status: http.StatusOK,
+	},
+	"POST /api/v0/device/{id}/rotate-key": {
+		id: "rotateDeviceKey", summary: "Replace the signing key of a device", tag: "device", scope: domain.ScopeDeviceWrite, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[GetDeviceOutputDto](),
+	},
+	"PUT /api/v0/device/{id}/sign": {
+		id: "signData", summary: "Sign data with a device", tag: "device", scope: domain.ScopeDeviceSign, organization: true,
+		input: reflect.TypeFor[PutDeviceSignInputDto](), status: http.StatusOK, output: reflect.TypeFor[PutDeviceSignOutputDto](),
+		empty: []int{http.StatusNoContent},
+		headers: map[string]*openapi.Header{
+			IdempotentReplayedHeader: {
+				Description: "set if the response was stored by an earlier request with the same idempotency key",
+				Schema:      &openapi.Schema{Type: openapi.Types{openapi.TypeString}, Enum: []any{"true"}},
+			},
+		},
+		parameters: []*openapi.Parameter{{
+			Name: IdempotencyKeyHeader, In: openapi.InHeader, Description: "retries with the same key return the original signature",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}},
+		}},
+	},
+	"GET /api/v0/device/{id}/signature": {
+		id: "listDeviceSignatures", summary: "Get the signature log of a device", tag: "device", scope: domain.ScopeDeviceRead, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[ListDeviceSignatureOutputDto](),
+		parameters: []*openapi.Parameter{{
+			Name: "after", In: openapi.InQuery, Description: "only signatures with a greater counter, oldest first",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: bound(0)},
+		}, {
+			Name: "limit", In: openapi.InQuery, Description: "maximum number of signatures",
+			Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: bound(1), Maximum: bound(maxSignatureLimit)},
+		}},
+	},
+
+	"GET /api/v0/events": {
+		id: "streamEvents", summary: "Stream the events of all devices", tag: "event", scope: domain.ScopeDeviceRead, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[EventOutputDto](), stream: true,
+	},
+	"GET /api/v0/device/{id}/events": {
+		id: "streamDeviceEvents", summary: "Stream the events of a device", tag: "event", scope: domain.ScopeDeviceRead, organization: true,
+		status: http.StatusOK, output: reflect.TypeFor[EventOutputDto](), stream: true,
+	},
+}
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+// OpenAPI returns the OpenAPI document describing the routes of the server.
+func (s *Server) OpenAPI() *openapi.Document {
+	return s.openAPI()
+}
+
+// describe builds the OpenAPI document from the registered routes and the documentation in [operations]
+func (s *Server) describe() *openapi.Document {
+	document := openapi.New(openapi.Info{
+		Title:       "Signature Service",
+		Description: "Manages signature devices and signs data with them.",
+		Version:     "v0",
+	})
+
+	generator := openapi.NewGenerator(document)
+	generator.Enum(reflect.TypeFor[domain.SigningAlgorithm](), domain.SigningAlgorithmEcc, domain.SigningAlgorithmRsa)
+	generator.Enum(reflect.TypeFor[domain.DeviceStatus](), domain.DeviceStatusActive, domain.DeviceStatusDisabled)
+	generator.Enum(reflect.TypeFor[domain.Scope](), domain.ScopeDeviceRead, domain.ScopeDeviceWrite, domain.ScopeDeviceSign, domain.ScopeAdmin)
+	generator.Enum(reflect.TypeFor[domain.DeliveryStatus](), domain.DeliveryStatusPending, domain.DeliveryStatusDelivered, domain.DeliveryStatusFailed)
+	eventTypes := make([]any, 0, len(domain.EventTypes))
+	for _, eventType := range domain.EventTypes {
+		eventTypes = append(eventTypes, eventType)
+	}
+	generator.Enum(reflect.TypeFor[domain.EventType](), eventTypes...)
+	errorCodes := make([]any, 0, len(apiError.Codes))
+	for _, code := range apiError.Codes {
+		errorCodes = append(errorCodes, code)
+	}
+	generator.Enum(reflect.TypeFor[apiError.Code](), errorCodes...)
+	generator.Enum(reflect.TypeFor[health.Status](), health.StatusPass, health.StatusWarn, health.StatusFail)
+
+	if s.apiKey.enabled {
+		document.Components.SecuritySchemes[bearerScheme] = &openapi.SecurityScheme{
+			Type:        "http",
+			Scheme:      "bearer",
+			Description: "API key presented as bearer token",
+		}
+	}
+
+	// the routes are registered with chi, so errors can't occur
+	_ = chi.Walk(s.mux(), func(method string, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		documentation, found := operations[method+" "+pattern]
+		if found {
+			document.AddOperation(method, pattern, s.describeOperation(generator, pattern, documentation))
+		}
+		return nil
+	})
+	return document
+}
+
+func (s *Server) describeOperation(generator *openapi.Generator, pattern string, documentation operation) *openapi.Operation {
+	op := &openapi.Operation{
+		OperationId: documentation.id,
+		Summary:     documentation.summary,
+		Tags:        []string{documentation.tag},
+		Responses: map[string]*openapi.Response{
+			"4XX": {
+				Description: "Client error",
+				Content:     errorContent(generator),
+			},
+			"5XX": {
+				Description: "Server error",
+				Content:     errorContent(generator),
+			},
+		},
+	}
+
+	for _, match := range pathParameterPattern.FindAllStringSubmatch(pattern, -1) {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:     match[1],
+			In:       openapi.InPath,
+			Required: true,
+			Schema:   &openapi.Schema{Type: openapi.Types{openapi.TypeString}, Format: "uuid"},
+		})
+	}
+	op.Parameters = append(op.Parameters, documentation.parameters...)
+	op.Parameters = append(op.Parameters, &openapi.Parameter{
+		Name:        RequestIDHeader,
+		In:          openapi.InHeader,
+		Description: "correlates the request with the log lines of the server, returned in the response and generated if missing",
+		Schema:      &openapi.Schema{Type: openapi.Types{openapi.TypeString}},
+	})
+	if documentation.organization {
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        OrganizationHeader,
+			In:          openapi.InHeader,
+			Description: "organization to operate on, defaults to the organization of the API key, which only the bootstrap key can leave",
+			Schema:      &openapi.Schema{Type: openapi.Types{openapi.TypeString}, Format: "uuid"},
+		})
+	}
+	if documentation.scope != "" && s.apiKey.enabled {
+		op.Security = []openapi.SecurityRequirement{{bearerScheme: {string(documentation.scope)}}}
+	}
+
+	if documentation.input != nil {
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(generator.Schema(documentation.input)),
+		}
+	}
+
+	success := &openapi.Response{
+		Description: http.StatusText(documentation.status),
+		Headers:     documentation.headers,
+	}
+	switch {
+	case documentation.stream:
+		// the data of every event is a json encoded output dto
+		generator.Schema(documentation.output)
+		success.Description = "Server-sent events, the data of every event is an " + documentation.output.Name()
+		success.Content = map[string]*openapi.MediaType{
+			"text/event-stream": {Schema: &openapi.Schema{Type: openapi.Types{openapi.TypeString}}},
+		}
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name:        LastEventIdHeader,
+			In:          openapi.InHeader,
+			Description: "replay the events after this event id",
+			Schema:      &openapi.Schema{Type: openapi.Types{openapi.TypeInteger}, Minimum: bound(0)},
+		})
+	case documentation.output != nil:
+		success.Content = openapi.JSON(openapi.Object(
+			map[string]*openapi.Schema{"data": generator.Schema(documentation.output)},
+			"data",
+		))
+	case documentation.raw != nil && documentation.mediaType != "":
+		success.Content = map[string]*openapi.MediaType{documentation.mediaType: {Schema: documentation.raw}}
+	case documentation.raw != nil:
+		success.Content = openapi.JSON(documentation.raw)
+	}
+	op.Responses[strconv.Itoa(documentation.status)] = success
+	if documentation.failure != 0 {
+		op.Responses[strconv.Itoa(documentation.failure)] = &openapi.Response{
+			Description: http.StatusText(documentation.failure),
+			Content:     success.Content,
+		}
+	}
+	for _, status := range documentation.empty {
+		op.Responses[strconv.Itoa(status)] = &openapi.Response{Description: http.StatusText(status)}
+	}
+	return op
+}
+
+// errorContent describes error responses, problem details or the legacy format depending on the Accept header
+func errorContent(generator *openapi.Generator) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{
+		ProblemContentType: {Schema: generator.Schema(reflect.TypeFor[Problem]())},
+		"application/json": {Schema: generator.Schema(reflect.TypeFor[ErrorResponse]())},
+	}
+}
+
+// Specification writes the OpenAPI document of the server.
+func (s *Server) Specification(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.MarshalIndent(s.OpenAPI(), "", "  ")
+	if err != nil {
+		WriteInternalError(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(bytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(bytes)
+}
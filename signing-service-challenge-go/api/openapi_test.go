@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/metrics"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/openapi"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// specification is the OpenAPI document all responses of makeRequest are validated against
+var specification = sync.OnceValue(func() *openapi.Document {
+	return NewServer(persistence.NewMemoryStorage(), lock.NewMemoryLocker[uuid.UUID](), WithMetrics(metrics.New()), WithMetricsEndpoint()).OpenAPI()
+})
+
+// collectRefs returns all schema references of an encoded document
+func collectRefs(value any) []string {
+	var refs []string
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+			}
+			refs = append(refs, collectRefs(child)...)
+		}
+	case []any:
+		for _, child := range value {
+			refs = append(refs, collectRefs(child)...)
+		}
+	}
+	return refs
+}
+
+// TestOpenAPI verifies that the OpenAPI document is served and describes all routes
+func TestOpenAPI(t *testing.T) {
+	assert := require.New(t)
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	server := NewServer(storage, locker, WithAuthentication(testBootstrapKey), WithMetrics(metrics.New()), WithMetricsEndpoint())
+	api := server.mux()
+
+	// Test case 1: The document is served without authentication
+	var document map[string]any
+	response := makeRequest(assert, nil, http.MethodGet, openAPIPath, api, &document)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("application/json", response.Header().Get("Content-Type"))
+	assert.Equal(openapi.Version, document["openapi"])
+	assert.Contains(document["components"].(map[string]any)["securitySchemes"], bearerScheme)
+
+	// Test case 2: All references resolve
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	refs := collectRefs(document)
+	assert.NotEmpty(refs)
+	for _, ref := range refs {
+		name, found := strings.CutPrefix(ref, "#/components/schemas/")
+		assert.True(found, ref)
+		assert.Contains(schemas, name)
+	}
+	assert.Contains(schemas, "EventOutputDto")
+
+	// Test case 3: Every registered route is documented, every documented route is registered
+	registered := make(map[string]bool)
+	err := chi.Walk(api, func(method string, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered[method+" "+pattern] = true
+		// health is registered for every method, but only GET is documented
+		if pattern != "/api/v0/health" || method == http.MethodGet {
+			assert.Contains(operations, method+" "+pattern)
+		}
+		return nil
+	})
+	assert.NoError(err)
+	ids := make(map[string]bool)
+	for route, operation := range operations {
+		assert.True(registered[route], route)
+		assert.False(ids[operation.id], operation.id)
+		ids[operation.id] = true
+
+		method, pattern, _ := strings.Cut(route, " ")
+		documented, found := server.OpenAPI().Find(method, pattern)
+		assert.True(found, route)
+		assert.Equal(operation.id, documented.OperationId)
+		if operation.scope != "" {
+			assert.Equal([]openapi.SecurityRequirement{{bearerScheme: {string(operation.scope)}}}, documented.Security)
+		}
+	}
+
+	// Test case 4: Optional and nullable fields are described as such
+	device := document["components"].(map[string]any)["schemas"].(map[string]any)["GetDeviceOutputDto"].(map[string]any)
+	assert.ElementsMatch(
+		[]any{"id", "signing_algorithm", "status", "public_keys", "signature_counter", "monthly_signature_usage"},
+		device["required"],
+	)
+	encoded, err := json.Marshal(device["properties"].(map[string]any)["label"])
+	assert.NoError(err)
+	assert.JSONEq(`{"type":"string"}`, string(encoded))
+	encoded, err = json.Marshal(device["properties"].(map[string]any)["public_keys"])
+	assert.NoError(err)
+	assert.JSONEq(`{"type":["array","null"],"items":{"type":"string"}}`, string(encoded))
+	assert.Equal(false, device["additionalProperties"])
+}
+
+// TestOpenAPIValidation verifies that responses not matching the OpenAPI document are detected
+func TestOpenAPIValidation(t *testing.T) {
+	assert := require.New(t)
+	document := specification()
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	valid := `{"data":{"id":"` + uuid.NewString() + `","signing_algorithm":"ECC","status":"active","public_keys":["key"],"signature_counter":0,"monthly_signature_usage":0}}`
+	assert.NoError(document.ValidateResponse(http.MethodGet, "/api/v0/device/"+uuid.NewString(), http.StatusOK, header, []byte(valid)))
+
+	for name, body := range map[string]string{
+		"missing property":   `{"data":{"id":"x"}}`,
+		"unknown property":   strings.Replace(valid, `"status"`, `"unknown":1,"status"`, 1),
+		"invalid enum value": strings.Replace(valid, `"ECC"`, `"DSA"`, 1),
+		"wrong type":         strings.Replace(valid, `"signature_counter":0`, `"signature_counter":"0"`, 1),
+		"missing wrapper":    `{"id":"x"}`,
+	} {
+		err := document.ValidateResponse(http.MethodGet, "/api/v0/device/"+uuid.NewString(), http.StatusOK, header, []byte(body))
+		assert.Error(err, name)
+	}
+
+	// errors have to be documented as well
+	assert.NoError(document.ValidateResponse(http.MethodGet, "/api/v0/device", http.StatusNotFound, header, []byte(`{"errors":["not found"]}`)))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/api/v0/device", http.StatusNotFound, header, []byte(`{"error":"not found"}`)))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/api/v0/device", http.StatusOK, http.Header{"Content-Type": {"text/html"}}, []byte(`<html>`)))
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/organizationManager"
+	"github.com/google/uuid"
+)
+
+// OrganizationHeader names the organization a request operates on, requests without it use the default organization
+const OrganizationHeader = "X-Organization-Id"
+
+type OrganizationHandler struct {
+	organizations *organizationManager.Handler
+}
+
+func NewOrganizationHandler(
+	organizations *organizationManager.Handler,
+) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizations: organizations,
+	}
+}
+
+// Scope is a middleware limiting all device operations of the request to an organization.
+// Requests authenticated with an API key are limited to its organization, also with the admin scope.
+// The bootstrap key and unauthenticated requests can choose the organization with [OrganizationHeader].
+func (o *OrganizationHandler) Scope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		organizationId := domain.DefaultOrganizationId
+		if key, ok := domain.APIKeyFromContext(ctx); ok {
+			organizationId = key.OrganizationId
+		}
+
+		if header := r.Header.Get(OrganizationHeader); header != "" {
+			headerOrganizationId, err := uuid.Parse(header)
+			if err != nil {
+				domain.LoggerFromContext(r.Context()).Error("invalid organization uuid", "error", err)
+				WriteErrorResponse(w, r, http.StatusBadRequest, "invalid organization uuid", err.Error())
+				return
+			}
+
+			key, ok := domain.APIKeyFromContext(ctx)
+			if ok && !key.HasScope(domain.ScopePlatform) && headerOrganizationId != key.OrganizationId {
+				WriteErrorResponse(w, r, http.StatusForbidden, "api key is not valid for the organization")
+				return
+			}
+			organizationId = headerOrganizationId
+		}
+
+		if _, err := o.organizations.GetOrganization(ctx, organizationId); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.WithOrganization(ctx, organizationId)))
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (o *OrganizationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	organization, err := o.organizations.GetOrganization(ctx, organizationId)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusOK, NewGetOrganizationOutputDto(organization))
+}
+
+type GetOrganizationOutputDto struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewGetOrganizationOutputDto maps a [domain.Organization] to its public representation.
+func NewGetOrganizationOutputDto(organization *domain.Organization) GetOrganizationOutputDto {
+	return GetOrganizationOutputDto{
+		Id:        organization.Id.String(),
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt,
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+)
+
+func (o *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizations, err := o.organizations.ListOrganizations(ctx)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	var out ListOrganizationOutputDto
+	for _, organization := range organizations {
+		out.Items = append(out.Items, NewGetOrganizationOutputDto(organization))
+	}
+
+	WriteAPIResponse(w, http.StatusOK, out)
+}
+
+type ListOrganizationOutputDto struct {
+	Items []GetOrganizationOutputDto `json:"items"`
+}
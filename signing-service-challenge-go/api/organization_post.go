@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/organizationManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+)
+
+type PostOrganizationInputDto struct {
+	Name string `json:"name"`
+}
+
+func (d PostOrganizationInputDto) Validate() error {
+	var v validation.Validator
+	v.Check("/name", validation.Label(d.Name))
+	return v.Err()
+}
+
+func (o *OrganizationHandler) Post(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dto, success := ParseBody[PostOrganizationInputDto](w, r)
+	if !success {
+		return
+	}
+
+	organization, err := o.organizations.CreateOrganization(ctx, organizationManager.NewOrganization{
+		Name: dto.Name,
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusCreated, NewGetOrganizationOutputDto(organization))
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// createOrganization is a helper function to create a new organization for testing
+func createOrganization(
+	assert *require.Assertions,
+	api http.Handler,
+	name string,
+) GetOrganizationOutputDto {
+	var out TypedResponse[GetOrganizationOutputDto]
+	response := makeRequest(
+		assert,
+		PostOrganizationInputDto{
+			Name: name,
+		},
+		http.MethodPost,
+		"/api/v0/organization",
+		api,
+		&out,
+	)
+	assert.Equal(http.StatusCreated, response.Code)
+
+	return out.Data
+}
+
+// TestOrganizationIsolation verifies that devices of one organization are invisible to all others
+// This test covers every device operation across tenant boundaries
+func TestOrganizationIsolation(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	organization1 := createOrganization(assert, api, "foo")
+	organization2 := createOrganization(assert, api, "bar")
+	asOrganization := func(organization GetOrganizationOutputDto) http.Header {
+		return http.Header{OrganizationHeader: []string{organization.Id}}
+	}
+
+	var device TypedResponse[PostDeviceOutputDto]
+	{
+		response := makeRequestWithHeader(
+			assert,
+			asOrganization(organization1),
+			PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&device,
+		)
+		assert.Equal(http.StatusCreated, response.Code)
+	}
+	devicePath := fmt.Sprintf("/api/v0/device/%s", device.Data.Id)
+
+	// Test case 1: The owning organization can access the device
+	{
+		response := makeRequestWithHeader(assert, asOrganization(organization1), nil, http.MethodGet, devicePath, api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+
+	// Test case 2: Other organizations, including the default one, can't see the device
+	for _, header := range []http.Header{asOrganization(organization2), nil} {
+		{
+			response := makeRequestWithHeader(assert, header, nil, http.MethodGet, devicePath, api, nil)
+			assert.Equal(http.StatusNotFound, response.Code)
+		}
+		{
+			response := makeRequestWithHeader(
+				assert,
+				header,
+				PutDeviceSignInputDto{Data: "foo"},
+				http.MethodPut,
+				devicePath+"/sign",
+				api,
+				nil,
+			)
+			assert.Equal(http.StatusNotFound, response.Code)
+		}
+		{
+			response := makeRequestWithHeader(
+				assert,
+				header,
+				PatchDeviceInputDto{Label: null.Set("bar")},
+				http.MethodPatch,
+				devicePath,
+				api,
+				nil,
+			)
+			assert.Equal(http.StatusNotFound, response.Code)
+		}
+		{
+			var out TypedResponse[ListDeviceOutputDto]
+			response := makeRequestWithHeader(assert, header, nil, http.MethodGet, "/api/v0/device", api, &out)
+			assert.Equal(http.StatusOK, response.Code)
+			assert.Empty(out.Data.Items)
+		}
+		{
+			// Deletion is idempotent, but must not remove the device of another organization
+			response := makeRequestWithHeader(assert, header, nil, http.MethodDelete, devicePath, api, nil)
+			assert.Equal(http.StatusOK, response.Code)
+		}
+	}
+
+	// Test case 3: Reusing a device id of another organization is a conflict
+	{
+		response := makeRequestWithHeader(
+			assert,
+			asOrganization(organization2),
+			PostDeviceInputDto{
+				Id:               null.New(device.Data.Id),
+				SigningAlgorithm: domain.SigningAlgorithmEcc,
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusConflict, response.Code)
+	}
+
+	{
+		response := makeRequestWithHeader(assert, asOrganization(organization1), nil, http.MethodGet, devicePath, api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+
+	// Test case 4: Unknown and malformed organizations are rejected
+	{
+		response := makeRequestWithHeader(
+			assert,
+			http.Header{OrganizationHeader: []string{uuid.NewString()}},
+			nil,
+			http.MethodGet,
+			"/api/v0/device",
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusNotFound, response.Code)
+	}
+	{
+		response := makeRequestWithHeader(
+			assert,
+			http.Header{OrganizationHeader: []string{"foo"}},
+			nil,
+			http.MethodGet,
+			"/api/v0/device",
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+	}
+}
+
+// TestOrganizationManagement verifies the organization endpoints
+// This test covers listing and the restrictions on deleting organizations
+func TestOrganizationManagement(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	organization := createOrganization(assert, api, "foo")
+	organizationPath := fmt.Sprintf("/api/v0/organization/%s", organization.Id)
+
+	// Test case 1: The default organization always exists
+	{
+		var out TypedResponse[ListOrganizationOutputDto]
+		response := makeRequest(assert, nil, http.MethodGet, "/api/v0/organization", api, &out)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Len(out.Data.Items, 2)
+		assert.Equal(domain.DefaultOrganizationId.String(), out.Data.Items[0].Id)
+		assert.Equal(organization.Id, out.Data.Items[1].Id)
+	}
+	{
+		response := makeRequest(
+			assert,
+			nil,
+			http.MethodDelete,
+			fmt.Sprintf("/api/v0/organization/%s", domain.DefaultOrganizationId),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusConflict, response.Code)
+	}
+
+	// Test case 2: Organizations with devices can't be deleted
+	var device TypedResponse[PostDeviceOutputDto]
+	makeRequestWithHeader(
+		assert,
+		http.Header{OrganizationHeader: []string{organization.Id}},
+		PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+		http.MethodPost,
+		"/api/v0/device",
+		api,
+		&device,
+	)
+	{
+		response := makeRequest(assert, nil, http.MethodDelete, organizationPath, api, nil)
+		assert.Equal(http.StatusConflict, response.Code)
+	}
+
+	// Test case 3: Organizations can be deleted after their devices
+	makeRequestWithHeader(
+		assert,
+		http.Header{OrganizationHeader: []string{organization.Id}},
+		nil,
+		http.MethodDelete,
+		fmt.Sprintf("/api/v0/device/%s", device.Data.Id),
+		api,
+		nil,
+	)
+	{
+		response := makeRequest(assert, nil, http.MethodDelete, organizationPath, api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	{
+		response := makeRequest(assert, nil, http.MethodGet, organizationPath, api, nil)
+		assert.Equal(http.StatusNotFound, response.Code)
+	}
+
+	// Test case 4: Organizations need a name
+	{
+		response := makeRequest(assert, PostOrganizationInputDto{}, http.MethodPost, "/api/v0/organization", api, nil)
+		assert.Equal(http.StatusBadRequest, response.Code)
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+)
+
+// ProblemContentType is the media type of problem details, see RFC 9457
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix turns error codes into the URIs identifying problem types
+const problemTypePrefix = "urn:problem-type:signing-service:"
+
+// Problem is the error response of the API following RFC 9457, extended by the machine-readable code
+// and the fields which failed validation.
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     apiError.Code       `json:"code"`
+	Errors   []ProblemFieldError `json:"errors,omitempty"`
+}
+
+// ProblemFieldError describes a rejected field of the request body
+type ProblemFieldError struct {
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// NewProblem maps an [apiError.Error] to its problem details
+func NewProblem(r *http.Request, apiErr apiError.Error) Problem {
+	problem := Problem{
+		Type:     problemTypePrefix + string(apiErr.Kind()),
+		Title:    http.StatusText(apiErr.Code()),
+		Status:   apiErr.Code(),
+		Detail:   strings.Join(apiErr.Messages(), ": "),
+		Instance: r.URL.Path,
+		Code:     apiErr.Kind(),
+	}
+	// the fields carry the details, the messages would repeat them
+	if len(apiErr.Fields()) > 0 {
+		problem.Detail = apiErr.Messages()[0]
+	}
+	for _, field := range apiErr.Fields() {
+		problem.Errors = append(problem.Errors, ProblemFieldError{
+			Pointer: field.Pointer,
+			Detail:  field.Detail,
+		})
+	}
+	return problem
+}
+
+// ErrorFormat selects how errors are written
+type ErrorFormat string
+
+const (
+	// ErrorFormatProblem writes errors as problem details, see [Problem]
+	ErrorFormatProblem ErrorFormat = "problem"
+	// ErrorFormatLegacy writes errors as list of messages, see [ErrorResponse]
+	ErrorFormatLegacy ErrorFormat = "legacy"
+)
+
+func (f *ErrorFormat) String() string {
+	return string(*f)
+}
+
+// Set parses the format, so it can be used as flag
+func (f *ErrorFormat) Set(value string) error {
+	switch format := ErrorFormat(value); format {
+	case ErrorFormatProblem, ErrorFormatLegacy:
+		*f = format
+		return nil
+	}
+	return fmt.Errorf("unknown error format %q, expected %s or %s", value, ErrorFormatProblem, ErrorFormatLegacy)
+}
+
+// WithErrorFormat sets the error format for clients which don't ask for one, problem details are the default.
+func WithErrorFormat(format ErrorFormat) Option {
+	return func(c *config) {
+		c.errorFormat = format
+	}
+}
+
+type errorFormatContextKey struct{}
+
+// NegotiateErrorFormat is a middleware choosing the error format of the request.
+// Clients accepting application/problem+json get problem details, clients only accepting
+// application/json get the legacy format, all others get the given default.
+func NegotiateErrorFormat(defaultFormat ErrorFormat) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			format := defaultFormat
+			if accepted := acceptedErrorFormat(r.Header.Values("Accept")); accepted != "" {
+				format = accepted
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), errorFormatContextKey{}, format)))
+		})
+	}
+}
+
+// errorFormatFromContext returns the negotiated error format, problem details if there was no negotiation
+func errorFormatFromContext(ctx context.Context) ErrorFormat {
+	if format, ok := ctx.Value(errorFormatContextKey{}).(ErrorFormat); ok {
+		return format
+	}
+	return ErrorFormatProblem
+}
+
+func acceptedErrorFormat(accept []string) ErrorFormat {
+	var json, wildcard bool
+	for _, value := range accept {
+		for _, mediaRange := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || params["q"] == "0" {
+				continue
+			}
+			switch mediaType {
+			case ProblemContentType:
+				return ErrorFormatProblem
+			case "application/json":
+				json = true
+			case "*/*", "application/*":
+				wildcard = true
+			}
+		}
+	}
+	// wildcards leave the choice to the server
+	if json && !wildcard {
+		return ErrorFormatLegacy
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func accept(mediaTypes string) http.Header {
+	return http.Header{"Accept": []string{mediaTypes}}
+}
+
+func TestProblem(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	devicePath := "/api/v0/device/993d8948-cb1b-4ce8-98f8-f8b866578faf"
+
+	// Test case 1: Errors are problem details by default
+	for _, header := range []http.Header{nil, accept("*/*"), accept("application/json, application/problem+json")} {
+		var out Problem
+		response := makeRequestWithHeader(assert, header, nil, http.MethodGet, devicePath, api, &out)
+		assert.Equal(http.StatusNotFound, response.Code)
+		assert.Equal(ProblemContentType, response.Header().Get("Content-Type"))
+		assert.Equal(Problem{
+			Type:     "urn:problem-type:signing-service:device_not_found",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "device not found",
+			Instance: devicePath,
+			Code:     apiError.CodeDeviceNotFound,
+		}, out)
+	}
+
+	// Test case 2: Clients only accepting json get the legacy format
+	{
+		var out ErrorResponse
+		response := makeRequestWithHeader(assert, accept("application/json"), nil, http.MethodGet, devicePath, api, &out)
+		assert.Equal(http.StatusNotFound, response.Code)
+		assert.Equal("application/json", response.Header().Get("Content-Type"))
+		assert.Equal([]string{"device not found"}, out.Errors)
+	}
+
+	// Test case 3: Fields with the wrong type are pointed to
+	{
+		var out Problem
+		response := makeRequest(
+			assert,
+			map[string]any{
+				"signing_algorithm": "ECC",
+				"metadata":          map[string]any{"region": 1},
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&out,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+		assert.Equal(apiError.CodeValidationFailed, out.Code)
+		assert.Equal([]ProblemFieldError{{Pointer: "/metadata/region", Detail: "must be a string"}}, out.Errors)
+	}
+
+	// Test case 4: Unknown routes are problems as well
+	{
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/api/v0/unknown", nil)
+		res := httptest.NewRecorder()
+		api.ServeHTTP(res, req)
+		assert.Equal(http.StatusNotFound, res.Code)
+		assert.Equal(ProblemContentType, res.Header().Get("Content-Type"))
+
+		var out Problem
+		assert.NoError(json.Unmarshal(res.Body.Bytes(), &out))
+		assert.Equal(apiError.CodeNotFound, out.Code)
+	}
+}
+
+func TestLegacyErrorFormat(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithErrorFormat(ErrorFormatLegacy)).mux()
+
+	devicePath := "/api/v0/device/993d8948-cb1b-4ce8-98f8-f8b866578faf"
+
+	// Test case 1: The legacy format is the default of the server
+	{
+		var out ErrorResponse
+		response := makeRequest(assert, nil, http.MethodGet, devicePath, api, &out)
+		assert.Equal(http.StatusNotFound, response.Code)
+		assert.Equal([]string{"device not found"}, out.Errors)
+	}
+
+	// Test case 2: Clients can still ask for problem details
+	{
+		var out Problem
+		response := makeRequestWithHeader(assert, accept(ProblemContentType), nil, http.MethodGet, devicePath, api, &out)
+		assert.Equal(http.StatusNotFound, response.Code)
+		assert.Equal(apiError.CodeDeviceNotFound, out.Code)
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RateLimits configures the token buckets requests are taken from, zero rates are unlimited.
+type RateLimits struct {
+	Global    ratelimit.Rate // Shared by all requests
+	PerClient ratelimit.Rate // Per API key, client certificate or remote address
+	PerDevice ratelimit.Rate // Per device, only applied to signing requests
+}
+
+// WithRateLimits limits the request rate globally, per client and per device.
+// All responses carry the X-RateLimit headers of the most restrictive bucket applying to them.
+func WithRateLimits(limits RateLimits) Option {
+	return func(c *config) {
+		c.rateLimits = limits
+	}
+}
+
+type RateLimitHandler struct {
+	limiters ratelimit.Limiters
+	// devices resolves whether a device belongs to the organization of the request
+	devices *deviceManager.Handler
+}
+
+func NewRateLimitHandler(limits RateLimits, devices *deviceManager.Handler) *RateLimitHandler {
+	r := &RateLimitHandler{devices: devices}
+	if limits.Global.Enabled() {
+		r.limiters.Global = ratelimit.NewMemoryLimiter[struct{}](limits.Global)
+	}
+	if limits.PerClient.Enabled() {
+		r.limiters.PerClient = ratelimit.NewMemoryLimiter[string](limits.PerClient)
+	}
+	if limits.PerDevice.Enabled() {
+		r.limiters.PerDevice = ratelimit.NewMemoryLimiter[uuid.UUID](limits.PerDevice)
+	}
+	return r
+}
+
+// Headers is a middleware writing the rate limit headers on every response, also on routes which aren't limited
+// and on failed authentication. Until a bucket is taken from, the headers describe the global bucket and the bucket
+// of the client known before authentication, without taking from them.
+func (l *RateLimitHandler) Headers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := rateLimitResults{}
+		if l.limiters.Global != nil {
+			results["global"] = l.limiters.Global.Peek(struct{}{})
+		}
+		if l.limiters.PerClient != nil {
+			results["client"] = l.limiters.PerClient.Peek(clientKey(r))
+		}
+		results.write(w.Header())
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rateLimitResultsContextKey{}, results)))
+	})
+}
+
+// LimitGlobal is a middleware limiting the rate of all requests.
+func (l *RateLimitHandler) LimitGlobal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.limiters.Global == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !allow(w, r, l.limiters.Global.Allow(struct{}{}), "global") {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitClient is a middleware limiting the rate of requests per client, it has to run after authentication.
+func (l *RateLimitHandler) LimitClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.limiters.PerClient == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !allow(w, r, l.limiters.PerClient.Allow(clientKey(r)), "client") {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitDevice is a middleware limiting the rate of requests per device given by the id URL parameter.
+// It has to run after [OrganizationHandler.Scope], only devices of the organization are taken from,
+// so other tenants can't drain the bucket of a device by knowing its id.
+func (l *RateLimitHandler) LimitDevice(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.limiters.PerDevice == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// invalid ids and unknown devices are rejected by the handler
+		deviceId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, err := l.devices.GetDevice(r.Context(), deviceId); err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !allow(w, r, l.limiters.PerDevice.Allow(deviceId), "device") {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey identifies the client of the request for the per client bucket,
+// anonymous clients are told apart by their address
+func clientKey(r *http.Request) string {
+	if client := domain.ClientIdentity(r.Context()); client != "" {
+		return client
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "address:" + host
+}
+
+type rateLimitResultsContextKey struct{}
+
+// rateLimitResults holds the state of each bucket applying to a request by the name of its limit
+type rateLimitResults map[string]ratelimit.Result
+
+// write sets the rate limit headers, several limits can apply to a request and the headers describe
+// the most restrictive one
+func (results rateLimitResults) write(header http.Header) {
+	var restrictive *ratelimit.Result
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		result := results[name]
+		if restrictive == nil || restrictive.Allowed && !result.Allowed ||
+			restrictive.Allowed == result.Allowed && result.Remaining < restrictive.Remaining {
+			restrictive = &result
+		}
+	}
+	if restrictive == nil {
+		return
+	}
+	header.Set("X-RateLimit-Limit", strconv.Itoa(restrictive.Limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(restrictive.Remaining))
+	header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(restrictive.Reset)))
+}
+
+// allow takes the result of a bucket into the rate limit headers and rejects the request if the bucket is empty.
+func allow(w http.ResponseWriter, r *http.Request, result ratelimit.Result, name string) bool {
+	header := w.Header()
+	results, ok := r.Context().Value(rateLimitResultsContextKey{}).(rateLimitResults)
+	if !ok {
+		// the request didn't pass [RateLimitHandler.Headers]
+		results = rateLimitResults{}
+	}
+	results[name] = result
+	results.write(header)
+
+	if !result.Allowed {
+		domain.LoggerFromContext(r.Context()).Warn("rate limit exceeded", "limit", name)
+		header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		WriteErrorResponse(w, r, http.StatusTooManyRequests, name+" rate limit exceeded")
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
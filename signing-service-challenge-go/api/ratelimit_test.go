@@ -0,0 +1,248 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// slowRate refills so slowly that no token is added while a test runs
+func slowRate(burst int) ratelimit.Rate {
+	return ratelimit.Rate{PerSecond: 0.001, Burst: burst}
+}
+
+// TestRateLimitDevice verifies that signing requests are limited per device
+// This test also covers the rate limit headers written on every response
+func TestRateLimitDevice(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithRateLimits(RateLimits{
+		PerClient: slowRate(100),
+		PerDevice: slowRate(2),
+	})).mux()
+
+	device1 := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	device2 := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	sign := func(device PostDeviceOutputDto) *http.Response {
+		response := makeRequest(
+			assert,
+			PutDeviceSignInputDto{Data: "foo"},
+			http.MethodPut,
+			fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+			api,
+			nil,
+		)
+		return response.Result()
+	}
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		response := sign(device1)
+		assert.Equal(http.StatusOK, response.StatusCode)
+		assert.Equal("2", response.Header.Get("X-RateLimit-Limit"))
+		assert.Equal(strconv.Itoa(remaining), response.Header.Get("X-RateLimit-Remaining"))
+		assert.NotEmpty(response.Header.Get("X-RateLimit-Reset"))
+	}
+
+	// Test case 1: The device is limited, the response tells when to retry
+	response := sign(device1)
+	assert.Equal(http.StatusTooManyRequests, response.StatusCode)
+	retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	assert.NoError(err)
+	assert.Greater(retryAfter, 0)
+
+	// Test case 2: Other devices have their own limit
+	response = sign(device2)
+	assert.Equal(http.StatusOK, response.StatusCode)
+
+	// Test case 3: Other requests only report the client limit
+	{
+		response := makeRequest(assert, nil, http.MethodGet, "/api/v0/device", api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal("100", response.Header().Get("X-RateLimit-Limit"))
+	}
+
+	// Test case 4: Other organizations can't drain the bucket of a device
+	var organization TypedResponse[GetOrganizationOutputDto]
+	makeRequest(assert, PostOrganizationInputDto{Name: "foo"}, http.MethodPost, "/api/v0/organization", api, &organization)
+	for range 3 {
+		response := makeRequestWithHeader(
+			assert,
+			http.Header{OrganizationHeader: []string{organization.Data.Id}},
+			PutDeviceSignInputDto{Data: "foo"},
+			http.MethodPut,
+			fmt.Sprintf("/api/v0/device/%s/sign", device2.Id),
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusNotFound, response.Code)
+	}
+	response = sign(device2)
+	assert.Equal(http.StatusOK, response.StatusCode)
+}
+
+// TestRateLimitClient verifies that requests are limited per client and globally
+func TestRateLimitClient(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithAuthentication(testBootstrapKey), WithRateLimits(RateLimits{
+		Global:    slowRate(6),
+		PerClient: slowRate(3),
+	})).mux()
+
+	// the bootstrap key uses its first token
+	key := createAPIKey(assert, api, bearer(testBootstrapKey), domain.ScopeDeviceRead)
+
+	list := func(secret string) int {
+		return makeRequestWithHeader(assert, bearer(secret), nil, http.MethodGet, "/api/v0/device", api, nil).Code
+	}
+
+	// Test case 1: Each client has its own bucket
+	assert.Equal(http.StatusOK, list(testBootstrapKey))
+	assert.Equal(http.StatusOK, list(testBootstrapKey))
+	assert.Equal(http.StatusTooManyRequests, list(testBootstrapKey))
+	assert.Equal(http.StatusOK, list(key.Secret))
+
+	// Test case 2: All clients share the global bucket
+	assert.Equal(http.StatusOK, list(key.Secret))
+	assert.Equal(http.StatusTooManyRequests, list(key.Secret))
+
+	// Health checks are never limited
+	{
+		response := makeRequest(assert, nil, http.MethodGet, "/api/v0/health", api, nil)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+}
+
+// TestRateLimitHeaders verifies that the rate limit headers are written on every response
+func TestRateLimitHeaders(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithAuthentication(testBootstrapKey), WithRateLimits(RateLimits{
+		Global:    slowRate(10),
+		PerClient: slowRate(5),
+	})).mux()
+
+	// Test case 1: Routes which aren't limited and rejected authentication describe the buckets without taking from them
+	for path, code := range map[string]int{
+		"/api/v0/health":  http.StatusOK,
+		livenessPath:      http.StatusOK,
+		"/api/v0/device":  http.StatusUnauthorized,
+		"/api/v0/unknown": http.StatusNotFound,
+	} {
+		response := makeRequest(assert, nil, http.MethodGet, path, api, nil)
+		assert.Equal(code, response.Code, path)
+		assert.Equal("5", response.Header().Get("X-RateLimit-Limit"), path)
+		assert.Equal("5", response.Header().Get("X-RateLimit-Remaining"), path)
+	}
+
+	// Test case 2: Authenticated clients get the state of their own bucket after taking from it
+	response := makeRequestWithHeader(assert, bearer(testBootstrapKey), nil, http.MethodGet, "/api/v0/device", api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("5", response.Header().Get("X-RateLimit-Limit"))
+	assert.Equal("4", response.Header().Get("X-RateLimit-Remaining"))
+}
+
+// TestSignQuota verifies that devices can't exceed their monthly signature quota
+func TestSignQuota(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	var device TypedResponse[PostDeviceOutputDto]
+	{
+		response := makeRequest(
+			assert,
+			PostDeviceInputDto{
+				SigningAlgorithm:      domain.SigningAlgorithmEcc,
+				MonthlySignatureQuota: null.New(2),
+			},
+			http.MethodPost,
+			"/api/v0/device",
+			api,
+			&device,
+		)
+		assert.Equal(http.StatusCreated, response.Code)
+		assert.Equal(2, device.Data.MonthlySignatureQuota.Some())
+	}
+	devicePath := fmt.Sprintf("/api/v0/device/%s", device.Data.Id)
+	sign := func() *http.Response {
+		return makeRequest(assert, PutDeviceSignInputDto{Data: "foo"}, http.MethodPut, devicePath+"/sign", api, nil).Result()
+	}
+
+	assert.Equal(http.StatusOK, sign().StatusCode)
+	assert.Equal(http.StatusOK, sign().StatusCode)
+
+	// Test case 1: The quota is exhausted until the next month
+	response := sign()
+	assert.Equal(http.StatusTooManyRequests, response.StatusCode)
+	retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	assert.NoError(err)
+	assert.Greater(retryAfter, 0)
+	assert.LessOrEqual(retryAfter, 31*24*60*60)
+
+	{
+		var out TypedResponse[GetDeviceOutputDto]
+		makeRequest(assert, nil, http.MethodGet, devicePath, api, &out)
+		assert.Equal(2, out.Data.SignatureCounter)
+		assert.Equal(2, out.Data.MonthlySignatureUsage)
+	}
+
+	// Test case 2: Raising the quota allows further signatures
+	{
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{MonthlySignatureQuota: null.Set(3)},
+			http.MethodPatch,
+			devicePath,
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	assert.Equal(http.StatusOK, sign().StatusCode)
+	assert.Equal(http.StatusTooManyRequests, sign().StatusCode)
+
+	// Test case 3: Removing the quota lifts the limit
+	{
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{MonthlySignatureQuota: null.Clear[int]()},
+			http.MethodPatch,
+			devicePath,
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusOK, response.Code)
+	}
+	assert.Equal(http.StatusOK, sign().StatusCode)
+
+	// Test case 4: Negative quotas are rejected
+	{
+		response := makeRequest(
+			assert,
+			PatchDeviceInputDto{MonthlySignatureQuota: null.Set(-1)},
+			http.MethodPatch,
+			devicePath,
+			api,
+			nil,
+		)
+		assert.Equal(http.StatusBadRequest, response.Code)
+	}
+}
@@ -1,66 +1,281 @@
 package api
 
 import (
-	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/apiKeyManager"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/organizationManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/webhookManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/eventbus"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/health"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/metrics"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/openapi"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 // Server manages HTTP requests and dispatches them to the appropriate services.
 type Server struct {
-	device *DeviceHandler
+	device       *DeviceHandler
+	organization *OrganizationHandler
+	apiKey       *APIKeyHandler
+	webhook      *WebhookHandler
+	event        *EventHandler
+	rateLimit    *RateLimitHandler
+	tls          *TLSConfig
+	errorFormat  ErrorFormat
+	metrics      *metrics.Metrics
+	serveMetrics bool
+	timeouts     Timeouts
+	health       *health.Registry
+	openAPI      func() *openapi.Document
+	// draining is set once the server shuts down, new signing requests are refused from then on
+	draining atomic.Bool
+}
+
+type config struct {
+	deviceManagerOptions  []deviceManager.Option
+	apiKeyManagerOptions  []apiKeyManager.Option
+	webhookManagerOptions []webhookManager.Option
+	authentication        bool
+	tls                   *TLSConfig
+	rateLimits            RateLimits
+	errorFormat           ErrorFormat
+	metrics               *metrics.Metrics
+	serveMetrics          bool
+	timeouts              Timeouts
+	healthChecks          []func(*health.Registry)
+}
+
+// Option configures optional behaviour of the Server.
+type Option func(*config)
+
+// WithIdempotencyTTL sets how long signing results are kept for retries with the same Idempotency-Key.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.deviceManagerOptions = append(c.deviceManagerOptions, deviceManager.WithIdempotencyTTL(ttl))
+	}
+}
+
+// WithCryptoPolicy restricts the algorithms and key sizes of new devices.
+func WithCryptoPolicy(policy deviceManager.CryptoPolicy) Option {
+	return func(c *config) {
+		c.deviceManagerOptions = append(c.deviceManagerOptions, deviceManager.WithCryptoPolicy(policy))
+	}
+}
+
+// WithAuthentication requires all requests to present an API key with the scopes of the route.
+// The bootstrap key is granted admin access to the default organization, so the first API keys can be created.
+func WithAuthentication(bootstrapKey string) Option {
+	return func(c *config) {
+		c.authentication = true
+		c.apiKeyManagerOptions = append(c.apiKeyManagerOptions, apiKeyManager.WithBootstrapKey(bootstrapKey))
+	}
+}
+
+// metricsPath serves the metrics of the server in the Prometheus format
+const metricsPath = "/metrics"
+
+// WithMetrics measures all requests and the cryptographic operations.
+// They are only served by the API with [WithMetricsEndpoint], e.g. the admin listener serves them otherwise.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(c *config) {
+		c.metrics = m
+		c.deviceManagerOptions = append(c.deviceManagerOptions, deviceManager.WithMetrics(m))
+	}
+}
+
+// WithMetricsEndpoint serves the metrics set with [WithMetrics] on /metrics.
+func WithMetricsEndpoint() Option {
+	return func(c *config) {
+		c.serveMetrics = true
+	}
+}
+
+// WithPrivateWebhookTargets allows webhooks to private, loopback and link-local addresses, e.g. for development.
+// The dispatcher has to allow them as well, see [webhook.WithPrivateTargets].
+func WithPrivateWebhookTargets() Option {
+	return func(c *config) {
+		c.webhookManagerOptions = append(c.webhookManagerOptions, webhookManager.WithPrivateTargets())
+	}
 }
 
 // NewServer is a factory to instantiate a new Server.
 func NewServer(
 	storage persistence.Storage,
 	locker lock.Locker[uuid.UUID],
+	options ...Option,
 ) *Server {
-	deviceService := deviceManager.New(storage)
+	c := config{
+		errorFormat: ErrorFormatProblem,
+		timeouts:    DefaultTimeouts,
+	}
+	for _, option := range options {
+		option(&c)
+	}
+
+	bus := eventbus.New(eventbus.DefaultHistory)
+	deviceService := deviceManager.New(storage, append(c.deviceManagerOptions, deviceManager.WithEventPublisher(bus))...)
+	organizationService := organizationManager.New(storage)
+	apiKeyService := apiKeyManager.New(storage, c.apiKeyManagerOptions...)
+	webhookService := webhookManager.New(storage, c.webhookManagerOptions...)
+
+	// the dependencies of the server are always checked, further checks are added with options
+	healthChecks := health.NewRegistry()
+	healthChecks.Register("storage", health.Storage(storage))
+	// the nil uuid is never a device id, see [domain.ValidateDeviceId].
+	// Instrumented lockers would measure every probe, a check of the same name given as option replaces this one.
+	healthChecks.Register("lock", health.Locker(locker, uuid.Nil))
+	healthChecks.Register("keys", health.Keys())
+	for _, register := range c.healthChecks {
+		register(healthChecks)
+	}
 
-	return &Server{
+	server := &Server{
 		// TODO: add services / further dependencies here ...
 		device: NewDeviceHandler(
 			deviceService,
 			locker,
 		),
+		organization: NewOrganizationHandler(
+			organizationService,
+		),
+		apiKey: NewAPIKeyHandler(
+			apiKeyService,
+			c.authentication,
+		),
+		webhook: NewWebhookHandler(
+			webhookService,
+		),
+		event: NewEventHandler(
+			bus,
+			deviceService,
+		),
+		rateLimit:    NewRateLimitHandler(c.rateLimits, deviceService),
+		tls:          c.tls,
+		errorFormat:  c.errorFormat,
+		metrics:      c.metrics,
+		serveMetrics: c.serveMetrics,
+		timeouts:     c.timeouts,
+		health:       healthChecks,
 	}
+	server.openAPI = sync.OnceValue(server.describe)
+	return server
+}
+
+// DeviceManager returns the device service of the server, so other transports can share it.
+func (s *Server) DeviceManager() *deviceManager.Handler {
+	return s.device.devices
+}
+
+// RateLimiters returns the buckets of the server, so other transports take from the same buckets.
+func (s *Server) RateLimiters() ratelimit.Limiters {
+	return s.rateLimit.limiters
+}
+
+// Handler returns the HTTP handler with all routes and middleware of the server.
+func (s *Server) Handler() http.Handler {
+	return s.mux()
 }
 
 // mux creates and configures the HTTP request multiplexer with all routes and middleware
-func (s *Server) mux() http.Handler {
+func (s *Server) mux() *chi.Mux {
 	mux := chi.NewMux()
-
-	// Add logging middleware to track all incoming requests
-	mux.Use(func(handler http.Handler) http.Handler {
-		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-			slog.Info("incoming request", "method", request.Method, "url", request.URL)
-			handler.ServeHTTP(writer, request)
-		})
+	mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		WriteErrorResponse(w, r, http.StatusNotFound, "route not found")
 	})
 
-	// Health check endpoint
+	// Every request continues the trace of the client, spans are only recorded when tracing is set up
+	mux.Use(tracing.Middleware)
+
+	// Every request gets an id for its log lines and is logged once it is completed
+	mux.Use(RequestID)
+	mux.Use(AccessLog)
+
+	// Errors are written in the format the client accepts
+	mux.Use(NegotiateErrorFormat(s.errorFormat))
+
+	if s.metrics != nil {
+		mux.Use(s.metrics.Middleware)
+	}
+
+	mux.Use(ClientCertificate)
+	mux.Use(s.rateLimit.Headers)
+
+	// Health check endpoints
 	mux.Handle("/api/v0/health", http.HandlerFunc(s.Health))
+	mux.Get(livenessPath, s.Liveness)
+	mux.Get(readinessPath, s.Readiness)
+
+	// OpenAPI description of all routes
+	mux.Get(openAPIPath, s.Specification)
+
+	// Prometheus metrics
+	if s.metrics != nil && s.serveMetrics {
+		mux.Get(metricsPath, s.metrics.Handler().ServeHTTP)
+	}
 
 	// TODO: register further HandlerFuncs here ...
 
-	// Device management endpoints
-	mux.Post("/api/v0/device", s.device.Post)        // Create a new device
-	mux.Get("/api/v0/device", s.device.List)         // List all devices
-	mux.Get("/api/v0/device/{id}", s.device.Get)     // Get a specific device
-	mux.Delete("/api/v0/device/{id}", s.device.Delete) // Delete a device
-	mux.Put("/api/v0/device/{id}/sign", s.device.Sign) // Sign data with a device
-	return mux
-}
+	mux.Group(func(mux chi.Router) {
+		mux.Use(s.rateLimit.LimitGlobal)
+		mux.Use(s.apiKey.Authenticate)
+		mux.Use(LogClient)
+		mux.Use(s.rateLimit.LimitClient)
 
-// Run registers all HandlerFuncs for the existing HTTP routes and starts the Server.
-func (s *Server) Run(listenAddress string) error {
-	slog.Info("server listening", "port", listenAddress)
+		// Organization management endpoints
+		mux.Group(func(mux chi.Router) {
+			mux.Use(s.apiKey.RequireScope(domain.ScopePlatform))
+
+			mux.Post("/api/v0/organization", s.organization.Post)          // Create a new organization
+			mux.Get("/api/v0/organization", s.organization.List)           // List all organizations
+			mux.Get("/api/v0/organization/{id}", s.organization.Get)       // Get a specific organization
+			mux.Delete("/api/v0/organization/{id}", s.organization.Delete) // Delete an organization without devices
+		})
 
-	return http.ListenAndServe(listenAddress, s.mux())
+		// Endpoints scoped to the organization of the request
+		mux.Group(func(mux chi.Router) {
+			mux.Use(s.organization.Scope)
+
+			// API key management endpoints
+			admin := mux.With(s.apiKey.RequireScope(domain.ScopeAdmin))
+			admin.Post("/api/v0/api-key", s.apiKey.Post)          // Create a new API key
+			admin.Get("/api/v0/api-key", s.apiKey.List)           // List all API keys
+			admin.Delete("/api/v0/api-key/{id}", s.apiKey.Delete) // Revoke an API key
+
+			// Webhook management endpoints
+			admin.Post("/api/v0/webhook", s.webhook.Post)                        // Subscribe an endpoint to events
+			admin.Get("/api/v0/webhook", s.webhook.List)                         // List all webhooks
+			admin.Get("/api/v0/webhook/{id}", s.webhook.Get)                     // Get a specific webhook
+			admin.Delete("/api/v0/webhook/{id}", s.webhook.Delete)               // Unsubscribe an endpoint
+			admin.Get("/api/v0/webhook/{id}/delivery", s.webhook.ListDeliveries) // Get the delivery log of a webhook
+
+			// Device management endpoints
+			read := mux.With(s.apiKey.RequireScope(domain.ScopeDeviceRead))
+			write := mux.With(s.apiKey.RequireScope(domain.ScopeDeviceWrite))
+			sign := mux.With(s.RefuseWhileDraining, s.apiKey.RequireScope(domain.ScopeDeviceSign), s.rateLimit.LimitDevice)
+			write.Post("/api/v0/device", s.device.Post)                        // Create a new device
+			read.Get("/api/v0/device", s.device.List)                          // List all devices
+			read.Get("/api/v0/device/{id}", s.device.Get)                      // Get a specific device
+			write.Patch("/api/v0/device/{id}", s.device.Patch)                 // Update mutable attributes of a device
+			write.Delete("/api/v0/device/{id}", s.device.Delete)               // Delete a device
+			write.Post("/api/v0/device/{id}/rotate-key", s.device.RotateKey)   // Replace the signing key of a device
+			sign.Put("/api/v0/device/{id}/sign", s.device.Sign)                // Sign data with a device
+			read.Get("/api/v0/device/{id}/signature", s.device.ListSignatures) // Get the signature log of a device
+
+			// Event streams
+			read.Get("/api/v0/events", s.event.Stream)                   // Stream the events of all devices
+			read.Get("/api/v0/device/{id}/events", s.event.StreamDevice) // Stream the events of a device
+		})
+	})
+	return mux
 }
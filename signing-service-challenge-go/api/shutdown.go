@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Timeouts bound the time clients may take, so slow or idle connections can't exhaust the server
+type Timeouts struct {
+	ReadHeader time.Duration // reading the request headers
+	Read       time.Duration // reading the whole request, including the body
+	Write      time.Duration // handling the request and writing the response, event streams are exempt
+	Idle       time.Duration // waiting for the next request on a keep-alive connection
+	Shutdown   time.Duration // draining in-flight requests on shutdown
+}
+
+// DefaultTimeouts are used unless configured otherwise with [WithTimeouts]
+var DefaultTimeouts = Timeouts{
+	ReadHeader: 5 * time.Second,
+	Read:       30 * time.Second,
+	Write:      30 * time.Second,
+	Idle:       2 * time.Minute,
+	Shutdown:   30 * time.Second,
+}
+
+// drainingRetryAfter is suggested to clients refused while draining, another instance should take over by then
+const drainingRetryAfter = 5 * time.Second
+
+// WithTimeouts sets the timeouts of the HTTP server and how long it drains on shutdown.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(c *config) {
+		c.timeouts = timeouts
+	}
+}
+
+// Run listens on the address and serves requests until the context is canceled, then it shuts down gracefully.
+func (s *Server) Run(ctx context.Context, listenAddress string) error {
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves requests on the listener until the context is canceled.
+// On shutdown new signing requests are refused and in-flight requests are awaited up to the shutdown timeout,
+// open event streams are closed. It returns nil once the server was shut down.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	slog.Info("server listening", "port", listener.Addr().String(), "tls", s.tls != nil)
+
+	server := &http.Server{
+		Handler:           s.mux(),
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+	}
+	if s.tls != nil {
+		tlsConfig, err := s.tls.ServerConfig()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		server.TLSConfig = tlsConfig
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		if s.tls != nil {
+			// certificates are already part of the tls config
+			served <- server.ServeTLS(listener, "", "")
+		} else {
+			served <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("draining server", "timeout", s.timeouts.Shutdown)
+	s.draining.Store(true)
+	s.event.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.timeouts.Shutdown)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("requests didn't complete in time, closing connections", "error", err)
+		server.Close()
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// RefuseWhileDraining is a middleware refusing requests once the server shuts down.
+// Requests which already passed it complete, so clients don't retry operations the server already started.
+func (s *Server) RefuseWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(drainingRetryAfter.Seconds())))
+			WriteErrorResponse(w, r, http.StatusServiceUnavailable, "server is shutting down")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
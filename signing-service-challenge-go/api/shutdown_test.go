@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRefuseWhileDraining verifies that signing requests are refused once the server shuts down
+func TestRefuseWhileDraining(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	server := NewServer(storage, locker)
+	api := server.mux()
+
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	server.draining.Store(true)
+
+	// Test case 1: Signing is refused with a hint when to retry
+	var problem Problem
+	response := makeRequest(
+		assert,
+		PutDeviceSignInputDto{Data: "lorem ipsum"},
+		http.MethodPut,
+		fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+		api,
+		&problem,
+	)
+	assert.Equal(http.StatusServiceUnavailable, response.Code)
+	assert.Equal(apiError.CodeUnavailable, problem.Code)
+	assert.Equal("5", response.Header().Get("Retry-After"))
+
+	// Test case 2: Other requests are still served until the connections are closed
+	response = makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/device/%s", device.Id), api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+}
+
+// TestServeShutdown verifies that in-flight requests complete when the server shuts down
+func TestServeShutdown(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	timeouts := DefaultTimeouts
+	timeouts.Shutdown = 5 * time.Second
+	server := NewServer(storage, locker, WithTimeouts(timeouts))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	baseURL := "http://" + listener.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener)
+	}()
+
+	response, err := http.Post(baseURL+"/api/v0/device", "application/json", bytes.NewBufferString(`{"signing_algorithm":"ECC"}`))
+	assert.NoError(err)
+	var device TypedResponse[PostDeviceOutputDto]
+	assert.NoError(json.NewDecoder(response.Body).Decode(&device))
+	response.Body.Close()
+	deviceId := uuid.MustParse(device.Data.Id)
+
+	stream, err := http.Get(baseURL + "/api/v0/events")
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, stream.StatusCode)
+	defer stream.Body.Close()
+
+	// the signing request waits for the device lock held by the test, so it is in flight during the shutdown
+	held, err := locker.Acquire(context.Background(), deviceId)
+	assert.NoError(err)
+	signed := make(chan *http.Response, 1)
+	go func() {
+		request, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/api/v0/device/%s/sign", baseURL, deviceId), bytes.NewBufferString(`{"data":"lorem ipsum"}`))
+		response, _ := http.DefaultClient.Do(request)
+		signed <- response
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	assert.Eventually(server.draining.Load, time.Second, 10*time.Millisecond)
+
+	// Test case 1: Event streams are closed, so they don't keep the server from draining
+	_, err = io.ReadAll(stream.Body)
+	assert.NoError(err)
+
+	// Test case 2: The in-flight signing request completes
+	held.Unlock()
+	response = <-signed
+	assert.NotNil(response)
+	assert.Equal(http.StatusOK, response.StatusCode)
+	response.Body.Close()
+
+	// Test case 3: The server stops once drained and accepts no new connections
+	select {
+	case err := <-served:
+		assert.NoError(err)
+	case <-time.After(timeouts.Shutdown):
+		assert.Fail("server didn't stop")
+	}
+	_, err = http.Get(baseURL + "/api/v0/health")
+	assert.Error(err)
+}
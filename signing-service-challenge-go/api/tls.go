@@ -0,0 +1,75 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// TLSConfig configures serving the API over TLS, optionally authenticating clients with certificates.
+type TLSConfig struct {
+	CertFile string // PEM encoded server certificate chain
+	KeyFile  string // PEM encoded server private key
+	// ClientCAFile enables client certificate authentication with the PEM encoded CAs in it
+	ClientCAFile string
+	// RequireClientCert rejects connections without a valid client certificate,
+	// otherwise clients without a certificate are accepted, but not identified
+	RequireClientCert bool
+}
+
+// WithTLS serves the API over TLS instead of plain HTTP.
+func WithTLS(tlsConfig TLSConfig) Option {
+	return func(c *config) {
+		c.tls = &tlsConfig
+	}
+}
+
+// ServerConfig loads the certificates and builds the [tls.Config] for the server.
+func (t TLSConfig) ServerConfig() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if t.ClientCAFile != "" {
+		caBytes, err := os.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New("no certificates found in client ca file")
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if t.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if t.RequireClientCert {
+		return nil, errors.New("requiring client certificates needs a client ca file")
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientCertificate is a middleware exposing the verified client certificate of the connection in the request context.
+func ClientCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// only verified chains identify a client, unverified peer certificates are ignored
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			certificate := domain.NewClientCertificate(r.TLS.VerifiedChains[0][0])
+			r = r.WithContext(domain.WithClientCertificate(r.Context(), certificate))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
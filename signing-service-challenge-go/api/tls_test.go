@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate is a certificate with its private key issued by a test CA
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+// issueCertificate is a helper function to create a certificate signed by the parent, or self-signed without parent
+func issueCertificate(
+	assert *require.Assertions,
+	commonName string,
+	parent *testCertificate,
+	template *x509.Certificate,
+) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(err)
+	template.SerialNumber = serial
+	template.Subject = pkix.Name{CommonName: commonName}
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerCertificate := key, template
+	if parent != nil {
+		signer, signerCertificate = parent.key, parent.certificate
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCertificate, &key.PublicKey, signer)
+	assert.NoError(err)
+
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(err)
+	return &testCertificate{certificate: certificate, key: key}
+}
+
+func (c *testCertificate) fingerprint() string {
+	sum := sha256.Sum256(c.certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.certificate.Raw},
+		PrivateKey:  c.key,
+	}
+}
+
+// writePEM is a helper function to write the certificate and its key into PEM files
+func (c *testCertificate) writePEM(assert *require.Assertions, dir string, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.certificate.Raw}), 0o600)
+	assert.NoError(err)
+
+	keyBytes, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(err)
+	keyFile := filepath.Join(dir, name+".key")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0o600)
+	assert.NoError(err)
+
+	return certFile, keyFile
+}
+
+// TestMutualTLS verifies that devices bound to client certificates can only be used by those clients
+// This test runs a real TLS server, so the whole certificate verification is covered
+func TestMutualTLS(t *testing.T) {
+	assert := require.New(t)
+	dir := t.TempDir()
+
+	ca := issueCertificate(assert, "test ca", nil, &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	serverCertificate := issueCertificate(assert, "localhost", ca, &x509.Certificate{
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	terminal1 := issueCertificate(assert, "terminal 1", ca, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	terminal2 := issueCertificate(assert, "terminal 2", ca, &x509.Certificate{
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	caFile, _ := ca.writePEM(assert, dir, "ca")
+	certFile, keyFile := serverCertificate.writePEM(assert, dir, "server")
+
+	tlsConfig := TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: caFile,
+	}
+	serverTLSConfig, err := tlsConfig.ServerConfig()
+	assert.NoError(err)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	server := httptest.NewUnstartedServer(NewServer(storage, locker, WithTLS(tlsConfig)).mux())
+	server.TLS = serverTLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	newClient := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs:      roots,
+					Certificates: certificates,
+				},
+			},
+		}
+	}
+	do := func(client *http.Client, method string, path string, body any) int {
+		buf := bytes.NewBuffer(nil)
+		assert.NoError(json.NewEncoder(buf).Encode(body))
+		req, err := http.NewRequest(method, server.URL+path, buf)
+		assert.NoError(err)
+		res, err := client.Do(req)
+		assert.NoError(err)
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	// the fingerprint is accepted in the colon separated format printed by openssl
+	var opensslFingerprint bytes.Buffer
+	sum := sha256.Sum256(terminal1.certificate.Raw)
+	for i, b := range sum {
+		if i > 0 {
+			opensslFingerprint.WriteByte(':')
+		}
+		fmt.Fprintf(&opensslFingerprint, "%02X", b)
+	}
+
+	var device PostDeviceOutputDto
+	{
+		buf := bytes.NewBuffer(nil)
+		assert.NoError(json.NewEncoder(buf).Encode(PostDeviceInputDto{
+			SigningAlgorithm:   domain.SigningAlgorithmEcc,
+			ClientCertificates: []string{opensslFingerprint.String()},
+		}))
+		res, err := newClient(terminal1.tlsCertificate()).Post(server.URL+"/api/v0/device", "application/json", buf)
+		assert.NoError(err)
+		defer res.Body.Close()
+		assert.Equal(http.StatusCreated, res.StatusCode)
+
+		var out TypedResponse[PostDeviceOutputDto]
+		assert.NoError(json.NewDecoder(res.Body).Decode(&out))
+		device = out.Data
+	}
+	assert.Equal([]string{terminal1.fingerprint()}, device.ClientCertificates)
+	signPath := fmt.Sprintf("/api/v0/device/%s/sign", device.Id)
+
+	// Test case 1: Only the bound terminal can sign
+	assert.Equal(http.StatusOK, do(newClient(terminal1.tlsCertificate()), http.MethodPut, signPath, PutDeviceSignInputDto{Data: "foo"}))
+	assert.Equal(http.StatusForbidden, do(newClient(terminal2.tlsCertificate()), http.MethodPut, signPath, PutDeviceSignInputDto{Data: "foo"}))
+	assert.Equal(http.StatusForbidden, do(newClient(), http.MethodPut, signPath, PutDeviceSignInputDto{Data: "foo"}))
+
+	// Test case 2: The binding can be extended and removed
+	assert.Equal(http.StatusOK, do(newClient(), http.MethodPatch, "/api/v0/device/"+device.Id, PatchDeviceInputDto{
+		ClientCertificates: null.Set([]string{terminal1.fingerprint(), terminal2.fingerprint()}),
+	}))
+	assert.Equal(http.StatusOK, do(newClient(terminal2.tlsCertificate()), http.MethodPut, signPath, PutDeviceSignInputDto{Data: "foo"}))
+	assert.Equal(http.StatusOK, do(newClient(), http.MethodPatch, "/api/v0/device/"+device.Id, PatchDeviceInputDto{
+		ClientCertificates: null.Clear[[]string](),
+	}))
+	assert.Equal(http.StatusOK, do(newClient(), http.MethodPut, signPath, PutDeviceSignInputDto{Data: "foo"}))
+
+	// Test case 3: Invalid fingerprints are rejected
+	assert.Equal(http.StatusBadRequest, do(newClient(), http.MethodPatch, "/api/v0/device/"+device.Id, PatchDeviceInputDto{
+		ClientCertificates: null.Set([]string{"foo"}),
+	}))
+}
+
+// TestRequireClientCertificate verifies that clients without certificates are rejected during the handshake
+func TestRequireClientCertificate(t *testing.T) {
+	assert := require.New(t)
+	dir := t.TempDir()
+
+	ca := issueCertificate(assert, "test ca", nil, &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+	serverCertificate := issueCertificate(assert, "localhost", ca, &x509.Certificate{
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	caFile, _ := ca.writePEM(assert, dir, "ca")
+	certFile, keyFile := serverCertificate.writePEM(assert, dir, "server")
+
+	// requiring client certificates without a ca to verify them is a configuration error
+	_, err := TLSConfig{CertFile: certFile, KeyFile: keyFile, RequireClientCert: true}.ServerConfig()
+	assert.Error(err)
+
+	serverTLSConfig, err := TLSConfig{
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		ClientCAFile:      caFile,
+		RequireClientCert: true,
+	}.ServerConfig()
+	assert.NoError(err)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	server := httptest.NewUnstartedServer(NewServer(storage, locker).mux())
+	server.TLS = serverTLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots},
+		},
+	}
+	_, err = client.Get(server.URL + "/api/v0/health")
+	assert.Error(err)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/tracing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracing verifies that a signing request is traced through the lock, the signing and the storage
+func TestTracing(t *testing.T) {
+	assert := require.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	storage := persistence.Instrument(persistence.NewMemoryStorage(), tracing.ObserveStorage)
+	locker := tracing.InstrumentLocker(lock.NewMemoryLocker[uuid.UUID]())
+	api := NewServer(storage, locker).mux()
+
+	// Test case 1: The key generation is part of the request creating the device
+	device := createDevice(assert, api, domain.SigningAlgorithmRsa)
+	keyGeneration := findSpan(recorder, func(span sdktrace.ReadOnlySpan) bool { return span.Name() == "crypto.GenerateKeyPair" })
+	assert.NotNil(keyGeneration)
+	parent := findSpan(recorder, func(span sdktrace.ReadOnlySpan) bool {
+		return span.SpanContext().SpanID() == keyGeneration.Parent().SpanID()
+	})
+	assert.NotNil(parent)
+	assert.Equal("POST /api/v0/device", parent.Name())
+
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	response := makeRequestWithHeader(
+		assert,
+		http.Header{"Traceparent": {"00-" + traceId + "-00f067aa0ba902b7-01"}},
+		PutDeviceSignInputDto{Data: "lorem ipsum"},
+		http.MethodPut,
+		fmt.Sprintf("/api/v0/device/%s/sign", device.Id),
+		api,
+		nil,
+	)
+	assert.Equal(http.StatusOK, response.Code)
+
+	// Test case 2: All spans of the request belong to the trace of the client
+	names := make(map[string]bool)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() == traceId {
+			names[span.Name()] = true
+		}
+	}
+	for _, name := range []string{
+		"PUT /api/v0/device/{id}/sign",
+		"lock.Acquire",
+		"deviceManager.SignData",
+		"crypto.DecodePrivateKey",
+		"crypto.Sign",
+		"storage devices.get",
+		"storage storage.transaction",
+		"storage devices.update",
+		"storage signatures.create",
+	} {
+		assert.True(names[name], "missing span %s", name)
+	}
+}
+
+// findSpan returns the first ended span matching the predicate, or nil
+func findSpan(recorder *tracetest.SpanRecorder, matches func(sdktrace.ReadOnlySpan) bool) sdktrace.ReadOnlySpan {
+	for _, span := range recorder.Ended() {
+		if matches(span) {
+			return span
+		}
+	}
+	return nil
+}
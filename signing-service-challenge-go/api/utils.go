@@ -1,15 +1,15 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
-	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
 )
 
 // Response is the generic API response container.
@@ -23,75 +23,95 @@ type ErrorResponse struct {
 }
 
 // WriteInternalError writes a default internal error message as an HTTP response.
-func WriteInternalError(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusInternalServerError)
-	w.Write([]byte(http.StatusText(http.StatusInternalServerError)))
+func WriteInternalError(w http.ResponseWriter, r *http.Request) {
+	WriteError(w, r, apiError.New(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)))
 }
 
 // WriteErrorResponse takes an HTTP status code and a slice of errors
 // and writes those as an HTTP error response in a structured format.
-func WriteErrorResponse(w http.ResponseWriter, code int, errorMessage string, additional ...string) {
-	w.WriteHeader(code)
-	w.Header().Set("Content-Type", "application/json")
-
-	errorResponse := ErrorResponse{
-		Errors: append([]string{errorMessage}, additional...),
-	}
-
-	bytes, err := json.Marshal(errorResponse)
-	if err != nil {
-		WriteInternalError(w)
-	}
-	w.Header().Set("Content-Length", strconv.FormatInt(int64(len(bytes)), 10))
-
-	w.Write(bytes)
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, code int, errorMessage string, additional ...string) {
+	WriteError(w, r, apiError.New(code, errorMessage, additional...))
 }
 
 // WriteError tries to write a [apiError.Error] into a response writer, if error is not [apiError.Error]
 // it writes internal server error instead.
-func WriteError(w http.ResponseWriter, err error) {
+// The error is written in the format negotiated by [NegotiateErrorFormat].
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
 	var apiErr apiError.Error
-	if errors.As(err, &apiErr) {
-		messages := apiErr.Messages()
-		WriteErrorResponse(w, apiErr.Code(), messages[0], messages[1:]...)
+	if !errors.As(err, &apiErr) {
+		domain.LoggerFromContext(r.Context()).Error("internal error", "error", err)
+		apiErr = apiError.New(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)).(apiError.Error)
+	}
+	for key, values := range apiErr.Header() {
+		w.Header()[key] = values
+	}
+
+	var response any = NewProblem(r, apiErr)
+	contentType := ProblemContentType
+	if errorFormatFromContext(r.Context()) == ErrorFormatLegacy {
+		response = ErrorResponse{Errors: apiErr.Messages()}
+		contentType = "application/json"
+	}
+
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("marshalling error response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusInternalServerError)
-	w.Write([]byte(http.StatusText(http.StatusInternalServerError)))
+	writeBody(w, apiErr.Code(), contentType, bytes)
 }
 
 // WriteAPIResponse takes an HTTP status code and a generic data struct
 // and writes those as an HTTP response in a structured format.
 func WriteAPIResponse(w http.ResponseWriter, code int, data interface{}) {
-	w.WriteHeader(code)
-	if data != nil {
-		w.Header().Set("Content-Type", "application/json")
-
-		response := Response{
-			Data: data,
-		}
+	if data == nil {
+		w.WriteHeader(code)
+		return
+	}
 
-		bytes, err := json.MarshalIndent(response, "", "  ")
-		if err != nil {
-			WriteInternalError(w)
-		}
-		w.Header().Set("Content-Length", strconv.FormatInt(int64(len(bytes)), 10))
+	response := Response{
+		Data: data,
+	}
 
-		w.Write(bytes)
+	bytes, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		slog.Error("marshalling response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+	writeBody(w, code, "application/json", bytes)
+}
+
+// writeBody writes a response with a body, headers have to be set before the status is written
+func writeBody(w http.ResponseWriter, code int, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(code)
+	w.Write(body)
 }
 
-func ParseBody[T interface{ Validate() error }](ctx context.Context, w http.ResponseWriter, r io.Reader) (T, bool) {
-	bodyDecoder := json.NewDecoder(r)
+// maxBodySize limits request bodies, so clients can't exhaust the memory of the server
+const maxBodySize = 1 << 20
+
+// ParseBody decodes and validates the request body, if either fails the error is written and false is returned.
+// Unknown fields and trailing data are rejected, errors of fields point to the field, see [validation.Errors].
+func ParseBody[T interface{ Validate() error }](w http.ResponseWriter, r *http.Request) (T, bool) {
+	ctx := r.Context()
 	var dto T
-	if err := bodyDecoder.Decode(&dto); err != nil {
-		slog.ErrorContext(ctx, "unmarshalling dto", "error", err)
-		WriteErrorResponse(w, http.StatusBadRequest, "validation failed", err.Error())
+	if err := validation.Decode(http.MaxBytesReader(w, r.Body, maxBodySize), &dto); err != nil {
+		domain.LoggerFromContext(ctx).Error("unmarshalling dto", "error", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteErrorResponse(w, r, http.StatusRequestEntityTooLarge, "request body must be at most "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes")
+			return dto, false
+		}
+		WriteError(w, r, apiError.Validation(err))
 		return dto, false
 	}
 	if err := dto.Validate(); err != nil {
-		slog.ErrorContext(ctx, "validating dto", "error", err)
-		WriteErrorResponse(w, http.StatusBadRequest, "validation failed", err.Error())
+		domain.LoggerFromContext(ctx).Error("validating dto", "error", err)
+		WriteError(w, r, apiError.Validation(err))
 		return dto, false
 	}
 	return dto, true
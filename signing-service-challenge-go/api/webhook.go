@@ -0,0 +1,17 @@
+package api
+
+import (
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/webhookManager"
+)
+
+type WebhookHandler struct {
+	webhooks *webhookManager.Handler
+}
+
+func NewWebhookHandler(
+	webhooks *webhookManager.Handler,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+	}
+}
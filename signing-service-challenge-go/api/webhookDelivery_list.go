@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// ListDeliveries returns the delivery log of a webhook, newest first.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhookId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveryLimit {
+			WriteError(w, r, apiError.Validation(errors.New("limit must be between 1 and "+strconv.Itoa(maxDeliveryLimit))))
+			return
+		}
+	}
+
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	deliveries, err := h.webhooks.ListDeliveries(ctx, organizationId, webhookId, limit)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	var out ListWebhookDeliveryOutputDto
+	for _, delivery := range deliveries {
+		out.Items = append(out.Items, NewGetWebhookDeliveryOutputDto(delivery))
+	}
+
+	WriteAPIResponse(w, http.StatusOK, out)
+}
+
+type ListWebhookDeliveryOutputDto struct {
+	Items []GetWebhookDeliveryOutputDto `json:"items"`
+}
+
+type GetWebhookDeliveryOutputDto struct {
+	Id            string                    `json:"id"`
+	EventId       string                    `json:"event_id"`
+	EventType     domain.EventType          `json:"event_type"`
+	DeviceId      string                    `json:"device_id"`
+	Data          json.RawMessage           `json:"data"`
+	Status        domain.DeliveryStatus     `json:"status"`
+	Attempts      []WebhookAttemptOutputDto `json:"attempts"`
+	NextAttemptAt *time.Time                `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+}
+
+type WebhookAttemptOutputDto struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// NewGetWebhookDeliveryOutputDto maps a [domain.WebhookDelivery] to its public representation.
+func NewGetWebhookDeliveryOutputDto(delivery *domain.WebhookDelivery) GetWebhookDeliveryOutputDto {
+	out := GetWebhookDeliveryOutputDto{
+		Id:        delivery.Id.String(),
+		EventId:   delivery.Event.Id.String(),
+		EventType: delivery.Event.Type,
+		DeviceId:  delivery.Event.DeviceId.String(),
+		Data:      delivery.Event.Data,
+		Status:    delivery.Status,
+		Attempts:  []WebhookAttemptOutputDto{},
+		CreatedAt: delivery.CreatedAt,
+	}
+	for _, attempt := range delivery.Attempts {
+		out.Attempts = append(out.Attempts, WebhookAttemptOutputDto{
+			AttemptedAt: attempt.AttemptedAt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			DurationMs:  attempt.Duration.Milliseconds(),
+		})
+	}
+	// the next attempt is only meaningful while the delivery is retried
+	if delivery.Status == domain.DeliveryStatusPending {
+		out.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return out
+}
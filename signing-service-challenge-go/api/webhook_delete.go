@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhookId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	if err := h.webhooks.DeleteWebhook(ctx, organizationId, webhookId); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhookId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		domain.LoggerFromContext(r.Context()).Error("invalid uuid", "error", err)
+		WriteErrorResponse(w, r, http.StatusBadRequest, "invalid uuid", err.Error())
+		return
+	}
+
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	webhook, err := h.webhooks.GetWebhook(ctx, organizationId, webhookId)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusOK, NewGetWebhookOutputDto(webhook))
+}
+
+type GetWebhookOutputDto struct {
+	Id         string             `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []domain.EventType `json:"event_types,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// NewGetWebhookOutputDto maps a [domain.Webhook] to its public representation, without the secret.
+func NewGetWebhookOutputDto(webhook *domain.Webhook) GetWebhookOutputDto {
+	return GetWebhookOutputDto{
+		Id:         webhook.Id.String(),
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
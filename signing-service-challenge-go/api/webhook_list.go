@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	webhooks, err := h.webhooks.ListWebhooks(ctx, organizationId)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	var out ListWebhookOutputDto
+	for _, webhook := range webhooks {
+		out.Items = append(out.Items, NewGetWebhookOutputDto(webhook))
+	}
+
+	WriteAPIResponse(w, http.StatusOK, out)
+}
+
+type ListWebhookOutputDto struct {
+	Items []GetWebhookOutputDto `json:"items"`
+}
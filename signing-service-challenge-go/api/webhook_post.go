@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/webhookManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+)
+
+type PostWebhookInputDto struct {
+	URL        string             `json:"url"`
+	EventTypes []domain.EventType `json:"event_types,omitempty"`
+}
+
+func (d PostWebhookInputDto) Validate() error {
+	var v validation.Validator
+	endpoint, err := url.Parse(d.URL)
+	v.Assert(
+		err == nil && (endpoint.Scheme == "http" || endpoint.Scheme == "https") && endpoint.Host != "",
+		"/url",
+		"url must be an absolute http or https url",
+	)
+	for i, eventType := range d.EventTypes {
+		v.Check(validation.Pointer("event_types", i), eventType.Validate())
+	}
+	return v.Err()
+}
+
+func (h *WebhookHandler) Post(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	dto, success := ParseBody[PostWebhookInputDto](w, r)
+	if !success {
+		return
+	}
+
+	// webhooks receive the events of the organization the request is scoped to
+	organizationId, _ := domain.OrganizationFromContext(ctx)
+
+	webhook, err := h.webhooks.CreateWebhook(ctx, webhookManager.NewWebhook{
+		OrganizationId: organizationId,
+		URL:            dto.URL,
+		EventTypes:     dto.EventTypes,
+	})
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	WriteAPIResponse(w, http.StatusCreated, PostWebhookOutputDto{
+		GetWebhookOutputDto: NewGetWebhookOutputDto(webhook),
+		Secret:              webhook.Secret,
+	})
+}
+
+// PostWebhookOutputDto is the only response containing the secret the payloads are signed with.
+type PostWebhookOutputDto struct {
+	GetWebhookOutputDto
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,429 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/outbox"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver is a local HTTP endpoint recording the events delivered to it
+type webhookReceiver struct {
+	*httptest.Server
+	secret string
+	// failures is the number of requests answered with an error before events are accepted
+	failures int
+	events   []webhook.Payload
+	mu       sync.Mutex
+}
+
+func newWebhookReceiver(assert *require.Assertions) *webhookReceiver {
+	receiver := &webhookReceiver{}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+
+		if receiver.failures > 0 {
+			receiver.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(err)
+		assert.NoError(webhook.Verify(receiver.secret, r.Header, body, time.Now(), webhook.DefaultTolerance))
+
+		var payload webhook.Payload
+		assert.NoError(json.Unmarshal(body, &payload))
+		assert.Equal(payload.Id.String(), r.Header.Get(webhook.IdHeader))
+		assert.Equal(string(payload.Type), r.Header.Get(webhook.EventHeader))
+		receiver.events = append(receiver.events, payload)
+	}))
+	return receiver
+}
+
+// received returns the events delivered so far and forgets them
+func (r *webhookReceiver) received() []webhook.Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// dispatchWebhooks is a helper function to move all events from the outbox to the webhooks
+// and to make one attempt for every delivery which is due, it returns the number of attempts
+func dispatchWebhooks(
+	assert *require.Assertions,
+	storage persistence.Storage,
+	dispatcher *webhook.Dispatcher,
+) int {
+	ctx := context.Background()
+
+	_, err := outbox.NewDispatcher(storage, []outbox.Sink{webhook.NewSink(storage)}).Dispatch(ctx)
+	assert.NoError(err)
+	attempts, err := dispatcher.Dispatch(ctx)
+	assert.NoError(err)
+
+	return attempts
+}
+
+// createWebhook is a helper function to subscribe the receiver to events
+func createWebhook(
+	assert *require.Assertions,
+	api http.Handler,
+	receiver *webhookReceiver,
+	eventTypes ...domain.EventType,
+) PostWebhookOutputDto {
+	var out TypedResponse[PostWebhookOutputDto]
+	response := makeRequest(
+		assert,
+		PostWebhookInputDto{
+			URL:        receiver.URL,
+			EventTypes: eventTypes,
+		},
+		http.MethodPost,
+		"/api/v0/webhook",
+		api,
+		&out,
+	)
+	assert.Equal(http.StatusCreated, response.Code)
+	assert.NotEmpty(out.Data.Secret)
+	receiver.secret = out.Data.Secret
+
+	return out.Data
+}
+
+// TestWebhookEvents verifies that device changes are delivered to subscribed webhooks
+// This test also covers key rotation, which emits key.rotated events
+func TestWebhookEvents(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	// the receivers listen on loopback addresses
+	api := NewServer(storage, locker, WithPrivateWebhookTargets()).mux()
+	dispatcher := webhook.NewDispatcher(storage, webhook.WithPrivateTargets())
+
+	receiver := newWebhookReceiver(assert)
+	defer receiver.Close()
+	subscription := createWebhook(assert, api, receiver)
+
+	// dispatch delivers all pending events and returns them in the order they were received
+	dispatch := func() []webhook.Payload {
+		dispatchWebhooks(assert, storage, dispatcher)
+		return receiver.received()
+	}
+
+	// Test case 1: Creating a device emits device.created
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	events := dispatch()
+	assert.Len(events, 1)
+	assert.Equal(domain.EventDeviceCreated, events[0].Type)
+	assert.Equal(device.Id, events[0].DeviceId.String())
+	assert.Equal(domain.DefaultOrganizationId, events[0].OrganizationId)
+	assert.JSONEq(fmt.Sprintf(`{"device_id":%q,"signing_algorithm":"ECC"}`, device.Id), string(events[0].Data))
+
+	// Test case 2: Signing emits signature.created with the new counter
+	var signature TypedResponse[PutDeviceSignOutputDto]
+	makeRequest(assert, PutDeviceSignInputDto{Data: "foo"}, http.MethodPut, fmt.Sprintf("/api/v0/device/%s/sign", device.Id), api, &signature)
+	events = dispatch()
+	assert.Len(events, 1)
+	assert.Equal(domain.EventSignatureCreated, events[0].Type)
+	assert.JSONEq(
+		fmt.Sprintf(`{"device_id":%q,"signature_counter":1,"signature":%q}`, device.Id, signature.Data.Signature),
+		string(events[0].Data),
+	)
+
+	// Test case 3: Rotating the key emits key.rotated, new signatures use the new key
+	var rotated TypedResponse[GetDeviceOutputDto]
+	response := makeRequest(assert, nil, http.MethodPost, fmt.Sprintf("/api/v0/device/%s/rotate-key", device.Id), api, &rotated)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Len(rotated.Data.PublicKeys, 2)
+	assert.Equal(device.PublicKeys[0], rotated.Data.PublicKeys[0])
+	events = dispatch()
+	assert.Len(events, 1)
+	assert.Equal(domain.EventKeyRotated, events[0].Type)
+	var keyRotated domain.KeyRotatedEventData
+	assert.NoError(json.Unmarshal(events[0].Data, &keyRotated))
+	assert.Equal(rotated.Data.PublicKeys[1], keyRotated.PublicKey)
+	assert.Equal(2, keyRotated.KeyVersion)
+
+	makeRequest(assert, PutDeviceSignInputDto{Data: "bar"}, http.MethodPut, fmt.Sprintf("/api/v0/device/%s/sign", device.Id), api, &signature)
+	validateSignature(assert, signature.Data, PostDeviceOutputDto{
+		SigningAlgorithm: device.SigningAlgorithm,
+		PublicKeys:       rotated.Data.PublicKeys[1:],
+	})
+	dispatch()
+
+	// Test case 4: Deleting a device emits device.deleted
+	makeRequest(assert, nil, http.MethodDelete, fmt.Sprintf("/api/v0/device/%s", device.Id), api, nil)
+	events = dispatch()
+	assert.Len(events, 1)
+	assert.Equal(domain.EventDeviceDeleted, events[0].Type)
+
+	// Test case 5: Deleting a missing device emits nothing
+	makeRequest(assert, nil, http.MethodDelete, fmt.Sprintf("/api/v0/device/%s", device.Id), api, nil)
+	assert.Empty(dispatch())
+
+	// Test case 6: The delivery log lists all deliveries, newest first
+	var deliveries TypedResponse[ListWebhookDeliveryOutputDto]
+	response = makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s/delivery", subscription.Id), api, &deliveries)
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Len(deliveries.Data.Items, 5)
+	assert.Equal(domain.EventDeviceDeleted, deliveries.Data.Items[0].EventType)
+	for _, delivery := range deliveries.Data.Items {
+		assert.Equal(domain.DeliveryStatusDelivered, delivery.Status)
+		assert.Len(delivery.Attempts, 1)
+		assert.Equal(http.StatusOK, delivery.Attempts[0].StatusCode)
+		assert.Nil(delivery.NextAttemptAt)
+	}
+
+	// Test case 7: Unsubscribed endpoints don't receive events anymore
+	response = makeRequest(assert, nil, http.MethodDelete, fmt.Sprintf("/api/v0/webhook/%s", subscription.Id), api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+	createDevice(assert, api, domain.SigningAlgorithmRsa)
+	assert.Empty(dispatch())
+}
+
+// TestWebhookRetry verifies that failed deliveries are retried until they succeed or are given up
+func TestWebhookRetry(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithPrivateWebhookTargets()).mux()
+
+	backoff := time.Duration(0)
+	dispatcher := webhook.NewDispatcher(
+		storage,
+		webhook.WithPrivateTargets(),
+		webhook.WithMaxAttempts(3),
+		webhook.WithBackoff(func(int) time.Duration { return backoff }),
+	)
+
+	receiver := newWebhookReceiver(assert)
+	defer receiver.Close()
+	subscription := createWebhook(assert, api, receiver, domain.EventDeviceCreated)
+	deliveryLog := func() []GetWebhookDeliveryOutputDto {
+		var out TypedResponse[ListWebhookDeliveryOutputDto]
+		makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s/delivery", subscription.Id), api, &out)
+		return out.Data.Items
+	}
+
+	// Test case 1: A failed delivery is retried after the backoff
+	receiver.failures = 1
+	backoff = time.Hour
+	createDevice(assert, api, domain.SigningAlgorithmEcc)
+
+	assert.Equal(1, dispatchWebhooks(assert, storage, dispatcher))
+	assert.Equal(0, dispatchWebhooks(assert, storage, dispatcher), "delivery must wait for the backoff")
+
+	deliveries := deliveryLog()
+	assert.Len(deliveries, 1)
+	assert.Equal(domain.DeliveryStatusPending, deliveries[0].Status)
+	assert.Len(deliveries[0].Attempts, 1)
+	assert.Equal(http.StatusServiceUnavailable, deliveries[0].Attempts[0].StatusCode)
+	assert.NotEmpty(deliveries[0].Attempts[0].Error)
+	assert.NotNil(deliveries[0].NextAttemptAt)
+	assert.WithinDuration(time.Now().Add(time.Hour), *deliveries[0].NextAttemptAt, time.Minute)
+
+	// Test case 2: The delivery succeeds once the receiver recovers
+	receiver.failures = 1
+	backoff = 0
+	createDevice(assert, api, domain.SigningAlgorithmEcc)
+	dispatchWebhooks(assert, storage, dispatcher)
+	dispatchWebhooks(assert, storage, dispatcher)
+	assert.Len(receiver.received(), 1)
+
+	deliveries = deliveryLog()
+	assert.Len(deliveries, 2)
+	assert.Equal(domain.DeliveryStatusDelivered, deliveries[0].Status)
+	assert.Len(deliveries[0].Attempts, 2)
+
+	// Test case 3: The delivery is given up after the maximum number of attempts
+	receiver.failures = 3
+	createDevice(assert, api, domain.SigningAlgorithmEcc)
+	for range 5 {
+		dispatchWebhooks(assert, storage, dispatcher)
+	}
+	assert.Empty(receiver.received())
+
+	deliveries = deliveryLog()
+	assert.Len(deliveries, 3)
+	assert.Equal(domain.DeliveryStatusFailed, deliveries[0].Status)
+	assert.Len(deliveries[0].Attempts, 3)
+
+	// Test case 4: Events the webhook isn't subscribed to aren't delivered
+	device := createDevice(assert, api, domain.SigningAlgorithmEcc)
+	makeRequest(assert, nil, http.MethodDelete, fmt.Sprintf("/api/v0/device/%s", device.Id), api, nil)
+	dispatchWebhooks(assert, storage, dispatcher)
+	events := receiver.received()
+	assert.Len(events, 1)
+	assert.Equal(domain.EventDeviceCreated, events[0].Type)
+}
+
+// TestWebhookManagement verifies that webhooks are validated and isolated per organization
+func TestWebhookManagement(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithPrivateWebhookTargets()).mux()
+	dispatcher := webhook.NewDispatcher(storage, webhook.WithPrivateTargets())
+
+	receiver := newWebhookReceiver(assert)
+	defer receiver.Close()
+
+	// Test case 1: Invalid urls and event types are rejected
+	for _, input := range []PostWebhookInputDto{
+		{URL: "not a url"},
+		{URL: "ftp://example.com"},
+		{URL: "/relative"},
+		{URL: receiver.URL, EventTypes: []domain.EventType{"device.unknown"}},
+	} {
+		response := makeRequest(assert, input, http.MethodPost, "/api/v0/webhook", api, nil)
+		assert.Equal(http.StatusBadRequest, response.Code, input)
+	}
+
+	// Test case 2: The secret is only returned on creation
+	subscription := createWebhook(assert, api, receiver)
+	{
+		var out TypedResponse[map[string]any]
+		response := makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s", subscription.Id), api, &out)
+		assert.Equal(http.StatusOK, response.Code)
+		assert.Equal(receiver.URL, out.Data["url"])
+		assert.NotContains(out.Data, "secret")
+	}
+	{
+		var out TypedResponse[ListWebhookOutputDto]
+		makeRequest(assert, nil, http.MethodGet, "/api/v0/webhook", api, &out)
+		assert.Equal([]GetWebhookOutputDto{subscription.GetWebhookOutputDto}, out.Data.Items)
+	}
+
+	// Test case 3: Webhooks only receive events of their organization
+	organization := createOrganization(assert, api, "other")
+	header := http.Header{OrganizationHeader: []string{organization.Id}}
+	response := makeRequestWithHeader(
+		assert,
+		header,
+		PostDeviceInputDto{SigningAlgorithm: domain.SigningAlgorithmEcc},
+		http.MethodPost,
+		"/api/v0/device",
+		api,
+		nil,
+	)
+	assert.Equal(http.StatusCreated, response.Code)
+	dispatchWebhooks(assert, storage, dispatcher)
+	assert.Empty(receiver.received())
+
+	// Test case 4: Webhooks of other organizations can't be accessed
+	response = makeRequestWithHeader(assert, header, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s", subscription.Id), api, nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+	response = makeRequestWithHeader(assert, header, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s/delivery", subscription.Id), api, nil)
+	assert.Equal(http.StatusNotFound, response.Code)
+	response = makeRequestWithHeader(assert, header, nil, http.MethodDelete, fmt.Sprintf("/api/v0/webhook/%s", subscription.Id), api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+	response = makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s", subscription.Id), api, nil)
+	assert.Equal(http.StatusOK, response.Code)
+}
+
+// TestWebhookPrivateTargets verifies that webhooks can't reach internal services unless private targets are allowed
+func TestWebhookPrivateTargets(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker).mux()
+
+	// Test case 1: Loopback, private and link-local addresses are rejected
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.0.1]/hook",
+	} {
+		var out Problem
+		response := makeRequest(assert, PostWebhookInputDto{URL: target}, http.MethodPost, "/api/v0/webhook", api, &out)
+		assert.Equal(http.StatusBadRequest, response.Code, target)
+		assert.Equal([]ProblemFieldError{{Pointer: "/url", Detail: webhook.ErrPrivateTarget.Error()}}, out.Errors)
+	}
+
+	// Test case 2: Public targets are accepted
+	response := makeRequest(assert, PostWebhookInputDto{URL: "https://example.com/hook"}, http.MethodPost, "/api/v0/webhook", api, nil)
+	assert.Equal(http.StatusCreated, response.Code)
+
+	// Test case 3: Host names resolving to private addresses are refused on delivery
+	receiver := newWebhookReceiver(assert)
+	defer receiver.Close()
+	receiver.URL = strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+	storage = persistence.NewMemoryStorage()
+	api = NewServer(storage, locker, WithPrivateWebhookTargets()).mux()
+	subscription := createWebhook(assert, api, receiver, domain.EventDeviceCreated)
+
+	createDevice(assert, api, domain.SigningAlgorithmEcc)
+	assert.Equal(1, dispatchWebhooks(assert, storage, webhook.NewDispatcher(storage)))
+	assert.Empty(receiver.received())
+
+	var deliveries TypedResponse[ListWebhookDeliveryOutputDto]
+	makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s/delivery", subscription.Id), api, &deliveries)
+	assert.Len(deliveries.Data.Items, 1)
+	assert.Equal(domain.DeliveryStatusPending, deliveries.Data.Items[0].Status)
+	assert.Contains(deliveries.Data.Items[0].Attempts[0].Error, webhook.ErrPrivateTarget.Error())
+}
+
+// TestWebhookRetention verifies that completed deliveries are removed from the delivery log after the retention
+func TestWebhookRetention(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	api := NewServer(storage, locker, WithPrivateWebhookTargets()).mux()
+	dispatcher := webhook.NewDispatcher(
+		storage,
+		webhook.WithPrivateTargets(),
+		webhook.WithBackoff(func(int) time.Duration { return time.Hour }),
+		webhook.WithRetention(time.Nanosecond),
+	)
+
+	receiver := newWebhookReceiver(assert)
+	defer receiver.Close()
+	subscription := createWebhook(assert, api, receiver, domain.EventDeviceCreated)
+	deliveryLog := func() []GetWebhookDeliveryOutputDto {
+		var out TypedResponse[ListWebhookDeliveryOutputDto]
+		makeRequest(assert, nil, http.MethodGet, fmt.Sprintf("/api/v0/webhook/%s/delivery", subscription.Id), api, &out)
+		return out.Data.Items
+	}
+
+	// one delivery succeeds, the next one waits for its retry
+	createDevice(assert, api, domain.SigningAlgorithmEcc)
+	dispatchWebhooks(assert, storage, dispatcher)
+	receiver.failures = 1
+	createDevice(assert, api, domain.SigningAlgorithmEcc)
+	dispatchWebhooks(assert, storage, dispatcher)
+	assert.Len(deliveryLog(), 2)
+
+	// Test case 1: Completed deliveries beyond the retention are swept, pending ones are kept
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Sweep(ctx, time.Millisecond)
+	assert.Eventually(func() bool { return len(deliveryLog()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(domain.DeliveryStatusPending, deliveryLog()[0].Status)
+}
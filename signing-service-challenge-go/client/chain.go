@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/crypto"
+	"github.com/google/uuid"
+)
+
+// signaturePageSize is the number of signatures fetched per request, the maximum of the API
+const signaturePageSize = 500
+
+// SignatureRecord is an entry of the signature log of a device
+type SignatureRecord struct {
+	Counter       int       `json:"counter"`
+	Signature     string    `json:"signature"`
+	SignedData    string    `json:"signed_data"`
+	Data          string    `json:"data"`
+	LastSignature string    `json:"last_signature"`
+	KeyVersion    int       `json:"key_version"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ListSignatures iterates over the signature log of a device after the given counter, oldest first.
+// The iteration stops at the first error.
+func (c *Client) ListSignatures(ctx context.Context, deviceId uuid.UUID, after int) iter.Seq2[*SignatureRecord, error] {
+	return func(yield func(*SignatureRecord, error) bool) {
+		for {
+			var page struct {
+				Items []*SignatureRecord `json:"items"`
+			}
+			_, err := c.do(ctx, request{
+				method: http.MethodGet,
+				path:   "/api/v0/device/" + deviceId.String() + "/signature",
+				query: url.Values{
+					"after": {strconv.Itoa(after)},
+					"limit": {strconv.Itoa(signaturePageSize)},
+				},
+				retry: true,
+			}, &page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, signature := range page.Items {
+				if !yield(signature, nil) {
+					return
+				}
+				after = signature.Counter
+			}
+			if len(page.Items) < signaturePageSize {
+				return
+			}
+		}
+	}
+}
+
+// ChainError describes where the signature chain of a device is broken
+type ChainError struct {
+	// Counter of the first signature failing verification
+	Counter int
+	Err     error
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("signature %d: %v", e.Counter, e.Err)
+}
+
+func (e *ChainError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyChain checks locally that the signature log of the device is complete and unaltered:
+// counters are consecutive, every signature is chained to the previous one
+// and was created by the key of the device it names. It returns the number of verified signatures.
+func VerifyChain(device *Device, signatures iter.Seq2[*SignatureRecord, error]) (int, error) {
+	verifiers := make([]crypto.Verifier, len(device.PublicKeys))
+	for i, publicKey := range device.PublicKeys {
+		verifier, err := crypto.DecodePublicKey([]byte(publicKey))
+		if err != nil {
+			return 0, fmt.Errorf("decoding public key %d: %w", i+1, err)
+		}
+		verifiers[i] = verifier
+	}
+
+	count := 0
+	lastSignature := base64.StdEncoding.EncodeToString(device.Id[:])
+	for signature, err := range signatures {
+		if err != nil {
+			return count, err
+		}
+		counter := count + 1
+		if err := verifyRecord(verifiers, signature, counter, lastSignature); err != nil {
+			return count, &ChainError{Counter: counter, Err: err}
+		}
+		count = counter
+		lastSignature = signature.Signature
+	}
+
+	// signatures created after fetching the device may be part of the log, missing ones may not
+	if count < device.SignatureCounter {
+		return count, &ChainError{
+			Counter: count + 1,
+			Err:     fmt.Errorf("missing, the device has created %d signatures", device.SignatureCounter),
+		}
+	}
+	return count, nil
+}
+
+func verifyRecord(verifiers []crypto.Verifier, signature *SignatureRecord, counter int, lastSignature string) error {
+	if signature.Counter != counter {
+		return fmt.Errorf("unexpected counter %d", signature.Counter)
+	}
+	if signature.LastSignature != lastSignature {
+		return errors.New("not chained to the previous signature")
+	}
+	signedData, err := ParseSignedData(signature.SignedData)
+	if err != nil {
+		return err
+	}
+	if signedData != (SignedData{Counter: counter, LastSignature: lastSignature, Data: signature.Data}) {
+		return errors.New("signed data doesn't match the signature")
+	}
+	if signature.KeyVersion < 1 || signature.KeyVersion > len(verifiers) {
+		return fmt.Errorf("unknown key version %d", signature.KeyVersion)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	return verifiers[signature.KeyVersion-1].Verify([]byte(signature.Data), decoded)
+}
@@ -0,0 +1,214 @@
+// Package client is the Go client of the signature service API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRetries is how often failed requests are retried by default
+	DefaultRetries = 3
+
+	// headers understood by the API
+	authorizationHeader  = "Authorization"
+	organizationHeader   = "X-Organization-Id"
+	idempotencyKeyHeader = "Idempotency-Key"
+	replayedHeader       = "Idempotent-Replayed"
+	retryAfterHeader     = "Retry-After"
+)
+
+// Client calls the signature service API.
+// Requests which are safe to repeat are retried on network errors, rate limits and server errors,
+// signing requests are made safe to repeat with idempotency keys.
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	apiKey       string
+	organization string
+	retries      int
+	backoff      func(attempt int) time.Duration
+}
+
+// Option configures optional behaviour of the Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with, e.g. to configure TLS.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates all requests with the API key.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithOrganization operates on the organization instead of the organization of the API key, which requires an admin key.
+func WithOrganization(organizationId string) Option {
+	return func(c *Client) {
+		c.organization = organizationId
+	}
+}
+
+// WithRetries sets how often failed requests are retried, zero disables retries.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff sets how long to wait before a retry, unless the API asks for a longer wait with Retry-After.
+func WithBackoff(backoff func(attempt int) time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = backoff
+	}
+}
+
+// ExponentialBackoff waits 100ms before the first retry and doubles the wait for every further retry, up to 5s.
+func ExponentialBackoff(attempt int) time.Duration {
+	return min(100*time.Millisecond<<min(attempt, 6), 5*time.Second)
+}
+
+// New creates a client of the API at the base URL, e.g. http://localhost:8080.
+func New(baseURL string, options ...Option) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, errors.New("base url must be an absolute http or https url")
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: http.DefaultClient,
+		retries:    DefaultRetries,
+		backoff:    ExponentialBackoff,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
+}
+
+// request describes a call of the API
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+	// retry marks requests which are safe to repeat
+	retry bool
+}
+
+// response is the generic API response container
+type response struct {
+	Data any `json:"data"`
+}
+
+// do sends the request, retrying it if allowed, and decodes the data of the response into out.
+func (c *Client) do(ctx context.Context, r request, out any) (http.Header, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		body, err = json.Marshal(r.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		header, retryable, err := c.send(ctx, r, body, out)
+		if err == nil {
+			return header, nil
+		}
+		if !r.retry || !retryable || attempt >= c.retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		wait := c.backoff(attempt)
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send makes a single attempt of the request and reports whether it may succeed when sent again
+func (c *Client) send(ctx context.Context, r request, body []byte, out any) (http.Header, bool, error) {
+	endpoint := c.baseURL.JoinPath(r.path)
+	endpoint.RawQuery = r.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, endpoint.String(), reader)
+	if err != nil {
+		return nil, false, err
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// problem details carry machine-readable error codes, the legacy format is only a fallback
+	req.Header.Set("Accept", problemContentType+", application/json")
+	if c.apiKey != "" {
+		req.Header.Set(authorizationHeader, "Bearer "+c.apiKey)
+	}
+	if c.organization != "" {
+		req.Header.Set(organizationHeader, c.organization)
+	}
+
+	// failures before the complete response was received, e.g. on the network, are retried
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	if res.StatusCode >= 400 {
+		retryable := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return nil, retryable, newError(res, payload)
+	}
+	if out != nil && len(payload) > 0 {
+		if err := json.Unmarshal(payload, &response{Data: out}); err != nil {
+			return nil, false, fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	return res.Header, false, nil
+}
+
+// parseRetryAfter reads the delay of a Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,380 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/crypto"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testBootstrapKey = "sk_bootstrap"
+
+func noBackoff(int) time.Duration {
+	return 0
+}
+
+// startServer serves the API with authentication, the middleware can intercept requests
+func startServer(t *testing.T, middleware func(http.Handler) http.Handler) *httptest.Server {
+	handler := api.NewServer(
+		persistence.NewMemoryStorage(),
+		lock.NewMemoryLocker[uuid.UUID](),
+		api.WithAuthentication(testBootstrapKey),
+	).Handler()
+	if middleware != nil {
+		handler = middleware(handler)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newClient(assert *require.Assertions, server *httptest.Server, options ...Option) *Client {
+	c, err := New(server.URL, append([]Option{WithAPIKey(testBootstrapKey), WithBackoff(noBackoff)}, options...)...)
+	assert.NoError(err)
+	return c
+}
+
+// TestDevices verifies the device operations of the client
+func TestDevices(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	c := newClient(assert, startServer(t, nil))
+
+	// Test case 1: Create and get a device
+	created, err := c.CreateDevice(ctx, CreateDevice{
+		SigningAlgorithm: domain.SigningAlgorithmEcc,
+		Label:            "client",
+		Tags:             []string{"pos"},
+	})
+	assert.NoError(err)
+	assert.NotEqual(uuid.Nil, created.Id)
+	assert.Equal("client", created.Label)
+	assert.Len(created.PublicKeys, 1)
+
+	device, err := c.GetDevice(ctx, created.Id)
+	assert.NoError(err)
+	assert.Equal(created.Id, device.Id)
+	assert.Equal(domain.DeviceStatusActive, device.Status)
+
+	// Test case 2: Errors of the API are decoded
+	_, err = c.GetDevice(ctx, uuid.New())
+	assert.True(IsNotFound(err))
+	assert.True(IsCode(err, "device_not_found"))
+	_, err = c.CreateDevice(ctx, CreateDevice{SigningAlgorithm: "DSA"})
+	var apiErr *Error
+	assert.ErrorAs(err, &apiErr)
+	assert.Equal(http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal("validation_failed", apiErr.Code)
+	assert.Equal([]string{"validation failed", "/signing_algorithm: signing algorithm invalid value"}, apiErr.Messages)
+	assert.Equal([]FieldError{{Pointer: "/signing_algorithm", Detail: "signing algorithm invalid value"}}, apiErr.Fields)
+	_, err = c.CreateDevice(ctx, CreateDevice{Id: created.Id, SigningAlgorithm: domain.SigningAlgorithmRsa})
+	assert.True(IsStatus(err, http.StatusConflict))
+	assert.True(IsCode(err, "device_conflict"))
+
+	// Test case 3: List all devices page by page
+	ids := []uuid.UUID{created.Id}
+	for range 4 {
+		device, err := c.CreateDevice(ctx, CreateDevice{SigningAlgorithm: domain.SigningAlgorithmEcc})
+		assert.NoError(err)
+		ids = append(ids, device.Id)
+	}
+	var listed []uuid.UUID
+	for device, err := range c.ListDevices(ctx, DeviceFilter{PageSize: 2}) {
+		assert.NoError(err)
+		listed = append(listed, device.Id)
+	}
+	assert.Equal(ids, listed)
+
+	listed = nil
+	for device, err := range c.ListDevices(ctx, DeviceFilter{Tags: []string{"pos"}}) {
+		assert.NoError(err)
+		listed = append(listed, device.Id)
+	}
+	assert.Equal([]uuid.UUID{created.Id}, listed)
+
+	// stopping the iteration early
+	count := 0
+	for range c.ListDevices(ctx, DeviceFilter{PageSize: 2}) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(3, count)
+
+	// Test case 4: Delete a device, deleting again succeeds
+	assert.NoError(c.DeleteDevice(ctx, created.Id))
+	assert.NoError(c.DeleteDevice(ctx, created.Id))
+	_, err = c.GetDevice(ctx, created.Id)
+	assert.True(IsNotFound(err))
+}
+
+// TestSign verifies signing and the local verification of signatures
+func TestSign(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	server := startServer(t, nil)
+	c := newClient(assert, server)
+
+	for _, algorithm := range []domain.SigningAlgorithm{domain.SigningAlgorithmEcc, domain.SigningAlgorithmRsa} {
+		device, err := c.CreateDevice(ctx, CreateDevice{SigningAlgorithm: algorithm})
+		assert.NoError(err)
+
+		// Test case 1: Signatures are chained and verify locally
+		first, err := c.Sign(ctx, device.Id, "foo_bar")
+		assert.NoError(err)
+		assert.False(first.Replayed)
+		assert.NoError(c.Verify(ctx, device.Id, first))
+
+		signedData, err := ParseSignedData(first.SignedData)
+		assert.NoError(err)
+		assert.Equal(SignedData{Counter: 1, LastSignature: base64.StdEncoding.EncodeToString(device.Id[:]), Data: "foo_bar"}, signedData)
+
+		second, err := c.Sign(ctx, device.Id, "baz")
+		assert.NoError(err)
+		signedData, err = ParseSignedData(second.SignedData)
+		assert.NoError(err)
+		assert.Equal(2, signedData.Counter)
+		assert.Equal(first.Signature, signedData.LastSignature)
+		assert.NoError(c.Verify(ctx, device.Id, second))
+
+		// Test case 2: Tampered signatures are rejected
+		tampered := *second
+		tampered.SignedData = "2_" + first.Signature + "_other"
+		assert.ErrorIs(c.Verify(ctx, device.Id, &tampered), crypto.ErrInvalidSignature)
+		tampered = *second
+		tampered.Signature = first.Signature
+		assert.ErrorIs(c.Verify(ctx, device.Id, &tampered), crypto.ErrInvalidSignature)
+		_, err = ParseSignedData("x_y")
+		assert.Error(err)
+
+		// Test case 3: Signing with the same idempotency key replays the signature
+		replayed, err := c.Sign(ctx, device.Id, "baz", WithIdempotencyKey("order-1"))
+		assert.NoError(err)
+		again, err := c.Sign(ctx, device.Id, "baz", WithIdempotencyKey("order-1"))
+		assert.NoError(err)
+		assert.True(again.Replayed)
+		assert.Equal(replayed.Signature, again.Signature)
+
+		// Test case 4: Signatures of replaced keys still verify
+		request, err := http.NewRequest(http.MethodPost, server.URL+"/api/v0/device/"+device.Id.String()+"/rotate-key", nil)
+		assert.NoError(err)
+		request.Header.Set("Authorization", "Bearer "+testBootstrapKey)
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(err)
+		response.Body.Close()
+		assert.Equal(http.StatusOK, response.StatusCode)
+
+		assert.NoError(c.Verify(ctx, device.Id, first))
+		third, err := c.Sign(ctx, device.Id, "rotated")
+		assert.NoError(err)
+		assert.NoError(c.Verify(ctx, device.Id, third))
+	}
+
+	// Test case 5: Empty data is rejected before sending
+	_, err := c.Sign(ctx, uuid.New(), "")
+	assert.Error(err)
+}
+
+// TestRetry verifies that failed requests are retried without duplicating their effect
+func TestRetry(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	// failures holds the statuses the next requests fail with, after they were handled by the API
+	var mu sync.Mutex
+	var failures []int
+	var idempotencyKeys []string
+	server := startServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+				mu.Lock()
+				idempotencyKeys = append(idempotencyKeys, key)
+				mu.Unlock()
+			}
+
+			mu.Lock()
+			var status int
+			if len(failures) > 0 {
+				status, failures = failures[0], failures[1:]
+			}
+			mu.Unlock()
+			if status == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// the request takes effect, but the response is lost
+			next.ServeHTTP(httptest.NewRecorder(), r)
+			w.Header().Set(retryAfterHeader, "0")
+			http.Error(w, http.StatusText(status), status)
+		})
+	})
+	fail := func(statuses ...int) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = statuses
+		idempotencyKeys = nil
+	}
+	c := newClient(assert, server)
+
+	// Test case 1: Creating a device is retried with the generated id
+	fail(http.StatusBadGateway)
+	device, err := c.CreateDevice(ctx, CreateDevice{SigningAlgorithm: domain.SigningAlgorithmEcc})
+	assert.NoError(err)
+	count := 0
+	for _, err := range c.ListDevices(ctx, DeviceFilter{}) {
+		assert.NoError(err)
+		count++
+	}
+	assert.Equal(1, count)
+
+	// Test case 2: Signing is retried with the same idempotency key and signs only once
+	fail(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	signature, err := c.Sign(ctx, device.Id, "foo")
+	assert.NoError(err)
+	assert.True(signature.Replayed)
+	assert.Len(idempotencyKeys, 3)
+	assert.Equal(idempotencyKeys[0], idempotencyKeys[1])
+	assert.Equal(idempotencyKeys[0], idempotencyKeys[2])
+	device, err = c.GetDevice(ctx, device.Id)
+	assert.NoError(err)
+	assert.Equal(1, device.SignatureCounter)
+
+	// Test case 3: Retries are limited
+	fail(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	_, err = c.GetDevice(ctx, device.Id)
+	var apiErr *Error
+	assert.ErrorAs(err, &apiErr)
+	assert.Equal(http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal([]string{http.StatusText(http.StatusBadGateway)}, apiErr.Messages)
+
+	fail(http.StatusBadGateway)
+	_, err = newClient(assert, server, WithRetries(0)).GetDevice(ctx, device.Id)
+	assert.True(IsStatus(err, http.StatusBadGateway))
+
+	// Test case 4: Client errors are not retried
+	fail()
+	_, err = c.Sign(ctx, uuid.New(), "foo")
+	assert.True(IsNotFound(err))
+	assert.Len(idempotencyKeys, 1)
+}
+
+// TestAuthentication verifies that the API key and organization are sent
+func TestAuthentication(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	server := startServer(t, nil)
+
+	// Test case 1: Requests without a valid key are rejected
+	c, err := New(server.URL, WithBackoff(noBackoff))
+	assert.NoError(err)
+	_, err = c.GetDevice(ctx, uuid.New())
+	assert.True(IsStatus(err, http.StatusUnauthorized))
+
+	// Test case 2: Unknown organizations are rejected
+	_, err = newClient(assert, server, WithOrganization(uuid.NewString())).GetDevice(ctx, uuid.New())
+	assert.True(IsNotFound(err))
+	assert.True(IsCode(err, "organization_not_found"))
+
+	// Test case 3: Invalid base urls are rejected
+	_, err = New("localhost:8080")
+	assert.Error(err)
+}
+
+// TestVerifyChain verifies that the signature log is checked for gaps and alterations
+func TestVerifyChain(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	server := startServer(t, nil)
+	c := newClient(assert, server)
+
+	device, err := c.CreateDevice(ctx, CreateDevice{SigningAlgorithm: domain.SigningAlgorithmRsa})
+	assert.NoError(err)
+	for i := range 5 {
+		if i == 3 {
+			request, err := http.NewRequest(http.MethodPost, server.URL+"/api/v0/device/"+device.Id.String()+"/rotate-key", nil)
+			assert.NoError(err)
+			request.Header.Set("Authorization", "Bearer "+testBootstrapKey)
+			response, err := http.DefaultClient.Do(request)
+			assert.NoError(err)
+			response.Body.Close()
+		}
+		_, err := c.Sign(ctx, device.Id, "data_"+strconv.Itoa(i))
+		assert.NoError(err)
+	}
+	device, err = c.GetDevice(ctx, device.Id)
+	assert.NoError(err)
+
+	// Test case 1: The complete log verifies, across key rotations
+	count, err := VerifyChain(device, c.ListSignatures(ctx, device.Id, 0))
+	assert.NoError(err)
+	assert.Equal(5, count)
+
+	var records []*SignatureRecord
+	for record, err := range c.ListSignatures(ctx, device.Id, 2) {
+		assert.NoError(err)
+		records = append(records, record)
+	}
+	assert.Len(records, 3)
+	assert.Equal(3, records[0].Counter)
+	assert.Equal(2, records[2].KeyVersion)
+
+	records = nil
+	for record, err := range c.ListSignatures(ctx, device.Id, 0) {
+		assert.NoError(err)
+		records = append(records, record)
+	}
+	seq := func(records []*SignatureRecord) iter.Seq2[*SignatureRecord, error] {
+		return func(yield func(*SignatureRecord, error) bool) {
+			for _, record := range records {
+				if !yield(record, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	// Test case 2: Altered, reordered and missing signatures are detected
+	for name, test := range map[string]struct {
+		modify  func([]SignatureRecord) []SignatureRecord
+		counter int
+	}{
+		"altered data": {func(r []SignatureRecord) []SignatureRecord {
+			r[1].Data = "other"
+			r[1].SignedData = "2_" + r[0].Signature + "_other"
+			return r
+		}, 2},
+		"wrong key version": {func(r []SignatureRecord) []SignatureRecord { r[4].KeyVersion = 1; return r }, 5},
+		"removed signature": {func(r []SignatureRecord) []SignatureRecord { return append(r[:2], r[3:]...) }, 3},
+		"truncated log":     {func(r []SignatureRecord) []SignatureRecord { return r[:4] }, 5},
+		"broken chain":      {func(r []SignatureRecord) []SignatureRecord { r[3].LastSignature = r[1].Signature; return r }, 4},
+	} {
+		copied := make([]SignatureRecord, len(records))
+		for i, record := range records {
+			copied[i] = *record
+		}
+		var modified []*SignatureRecord
+		for _, record := range test.modify(copied) {
+			modified = append(modified, &record)
+		}
+
+		_, err := VerifyChain(device, seq(modified))
+		var chainErr *ChainError
+		assert.ErrorAs(err, &chainErr, name)
+		assert.Equal(test.counter, chainErr.Counter, name)
+	}
+}
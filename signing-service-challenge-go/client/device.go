@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// DefaultPageSize is the number of devices fetched per request when listing devices
+const DefaultPageSize = 100
+
+// Device is a signature device
+type Device struct {
+	Id                    uuid.UUID               `json:"id"`
+	SigningAlgorithm      domain.SigningAlgorithm `json:"signing_algorithm"`
+	Label                 string                  `json:"label,omitempty"`
+	Status                domain.DeviceStatus     `json:"status,omitempty"`
+	Metadata              map[string]string       `json:"metadata,omitempty"`
+	Tags                  []string                `json:"tags,omitempty"`
+	ClientCertificates    []string                `json:"client_certificates,omitempty"`
+	PublicKeys            []string                `json:"public_keys"`
+	SignatureCounter      int                     `json:"signature_counter"`
+	MonthlySignatureQuota *int                    `json:"monthly_signature_quota,omitempty"`
+	MonthlySignatureUsage int                     `json:"monthly_signature_usage"`
+}
+
+// CreateDevice describes a new device
+type CreateDevice struct {
+	// Id of the device, generated by the client if empty, which allows retrying the creation
+	Id               uuid.UUID               `json:"id"`
+	SigningAlgorithm domain.SigningAlgorithm `json:"signing_algorithm"`
+	Label            string                  `json:"label,omitempty"`
+	Metadata         map[string]string       `json:"metadata,omitempty"`
+	Tags             []string                `json:"tags,omitempty"`
+	// SHA-256 fingerprints of the client certificates allowed to sign with the device
+	ClientCertificates    []string `json:"client_certificates,omitempty"`
+	MonthlySignatureQuota *int     `json:"monthly_signature_quota,omitempty"`
+}
+
+// DeviceFilter narrows down listed devices, a device has to carry all the tags and metadata entries to match
+type DeviceFilter struct {
+	Tags     []string
+	Metadata map[string]string
+	// PageSize is the number of devices fetched per request, DefaultPageSize if zero
+	PageSize int
+}
+
+// CreateDevice creates a new device.
+func (c *Client) CreateDevice(ctx context.Context, device CreateDevice) (*Device, error) {
+	generated := device.Id == uuid.Nil
+	if generated {
+		device.Id = uuid.New()
+	}
+
+	var created Device
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v0/device",
+		body:   device,
+		retry:  generated,
+	}, &created)
+	if generated && IsStatus(err, http.StatusConflict) {
+		// nobody else knows the generated id, the device was created by an attempt whose response was lost
+		return c.GetDevice(ctx, device.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetDevice returns a device.
+func (c *Client) GetDevice(ctx context.Context, id uuid.UUID) (*Device, error) {
+	var device Device
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v0/device/" + id.String(),
+		retry:  true,
+	}, &device)
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// ListDevices iterates over all devices matching the filter in the order of creation, fetching them page by page.
+// The iteration stops at the first error.
+func (c *Client) ListDevices(ctx context.Context, filter DeviceFilter) iter.Seq2[*Device, error] {
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return func(yield func(*Device, error) bool) {
+		for offset := 0; ; offset += pageSize {
+			query := url.Values{
+				"limit":  {strconv.Itoa(pageSize)},
+				"offset": {strconv.Itoa(offset)},
+				"tag":    filter.Tags,
+			}
+			for key, value := range filter.Metadata {
+				query.Set("meta."+key, value)
+			}
+
+			var page struct {
+				Items []*Device `json:"items"`
+			}
+			_, err := c.do(ctx, request{
+				method: http.MethodGet,
+				path:   "/api/v0/device",
+				query:  query,
+				retry:  true,
+			}, &page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, device := range page.Items {
+				if !yield(device, nil) {
+					return
+				}
+			}
+			if len(page.Items) < pageSize {
+				return
+			}
+		}
+	}
+}
+
+// DeleteDevice deletes a device, deleting a device which doesn't exist succeeds.
+func (c *Client) DeleteDevice(ctx context.Context, id uuid.UUID) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/api/v0/device/" + id.String(),
+		retry:  true,
+	}, nil)
+	return err
+}
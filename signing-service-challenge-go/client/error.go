@@ -0,0 +1,100 @@
+package client
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error is returned for requests the API responded to with an error status
+type Error struct {
+	StatusCode int
+	// Messages describe the error, the first one is the summary
+	Messages []string
+	// Code is the machine-readable error code, e.g. device_not_found. It is empty for responses without problem details
+	Code string
+	// Fields are the fields of the request which failed validation
+	Fields []FieldError
+	// RetryAfter is how long the API asks to wait before retrying, e.g. when rate limited
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), strings.Join(e.Messages, ", "))
+}
+
+// FieldError describes a rejected field of a request
+type FieldError struct {
+	// Pointer is the JSON pointer of the field in the request body
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// problemContentType is the media type of problem details, see RFC 9457
+const problemContentType = "application/problem+json"
+
+// problem are the problem details returned by the API
+type problem struct {
+	Title  string       `json:"title"`
+	Detail string       `json:"detail"`
+	Code   string       `json:"code"`
+	Errors []FieldError `json:"errors"`
+}
+
+// errorResponse is the legacy error container of the API
+type errorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// newError decodes an error response of the API
+func newError(res *http.Response, payload []byte) *Error {
+	apiErr := &Error{
+		StatusCode: res.StatusCode,
+		RetryAfter: parseRetryAfter(res.Header.Get(retryAfterHeader)),
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == problemContentType {
+		var decoded problem
+		if err := json.Unmarshal(payload, &decoded); err == nil {
+			apiErr.Code = decoded.Code
+			apiErr.Fields = decoded.Errors
+			apiErr.Messages = []string{cmp.Or(decoded.Detail, decoded.Title, http.StatusText(res.StatusCode))}
+			for _, field := range decoded.Errors {
+				apiErr.Messages = append(apiErr.Messages, field.Pointer+": "+field.Detail)
+			}
+			return apiErr
+		}
+	}
+
+	var decoded errorResponse
+	if err := json.Unmarshal(payload, &decoded); err == nil && len(decoded.Errors) > 0 {
+		apiErr.Messages = decoded.Errors
+	} else if message := strings.TrimSpace(string(payload)); message != "" {
+		apiErr.Messages = []string{message}
+	} else {
+		apiErr.Messages = []string{http.StatusText(res.StatusCode)}
+	}
+	return apiErr
+}
+
+// IsStatus reports whether the error is an [Error] with the status code.
+func IsStatus(err error, statusCode int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// IsCode reports whether the error is an [Error] with the machine-readable code.
+func IsCode(err error, code string) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsNotFound reports whether the error is an [Error] with status 404.
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/crypto"
+	"github.com/google/uuid"
+)
+
+// Signature is the result of signing data with a device
+type Signature struct {
+	// Signature is the base64 encoded signature of the data
+	Signature string `json:"signature"`
+	// SignedData is formatted as "<counter>_<last signature>_<data>", see [ParseSignedData]
+	SignedData string `json:"signed_data"`
+	// Replayed is set when the signature was created by an earlier request with the same idempotency key
+	Replayed bool `json:"-"`
+}
+
+// SignOption configures a signing request.
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey sets the idempotency key of a signing request, instead of a generated one.
+// Signing again with the same key returns the original signature, as long as the API keeps it.
+func WithIdempotencyKey(key string) SignOption {
+	return func(o *signOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// Sign signs data with a device.
+// Every request carries an idempotency key, so failed attempts are retried without signing the data twice.
+func (c *Client) Sign(ctx context.Context, deviceId uuid.UUID, data string, options ...SignOption) (*Signature, error) {
+	if data == "" {
+		return nil, errors.New("data must not be empty")
+	}
+	o := signOptions{idempotencyKey: uuid.NewString()}
+	for _, option := range options {
+		option(&o)
+	}
+
+	var signature Signature
+	header, err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   "/api/v0/device/" + deviceId.String() + "/sign",
+		header: http.Header{idempotencyKeyHeader: {o.idempotencyKey}},
+		body:   map[string]string{"data": data},
+		retry:  true,
+	}, &signature)
+	if err != nil {
+		return nil, err
+	}
+	signature.Replayed = header.Get(replayedHeader) == "true"
+	return &signature, nil
+}
+
+// Verify checks a signature against the public keys of the device, see [VerifySignature].
+func (c *Client) Verify(ctx context.Context, deviceId uuid.UUID, signature *Signature) error {
+	device, err := c.GetDevice(ctx, deviceId)
+	if err != nil {
+		return err
+	}
+	return VerifySignature(device, signature)
+}
+
+// SignedData are the parts of [Signature.SignedData]
+type SignedData struct {
+	// Counter is the signature counter of the device after signing
+	Counter int
+	// LastSignature is the signature the signature is chained to, the base64 encoded device id for the first one
+	LastSignature string
+	// Data is the signed data
+	Data string
+}
+
+// ParseSignedData splits signed data formatted as "<counter>_<last signature>_<data>",
+// the data may contain underscores.
+func ParseSignedData(signedData string) (SignedData, error) {
+	parts := strings.SplitN(signedData, "_", 3)
+	if len(parts) != 3 {
+		return SignedData{}, errors.New("signed data must be formatted as <counter>_<last signature>_<data>")
+	}
+	counter, err := strconv.Atoi(parts[0])
+	if err != nil || counter < 1 {
+		return SignedData{}, fmt.Errorf("invalid signature counter %q", parts[0])
+	}
+	return SignedData{
+		Counter:       counter,
+		LastSignature: parts[1],
+		Data:          parts[2],
+	}, nil
+}
+
+// VerifySignature checks locally that the signature was created by one of the keys of the device,
+// without trusting the API to do so. Keys replaced by a rotation are still accepted.
+func VerifySignature(device *Device, signature *Signature) error {
+	signedData, err := ParseSignedData(signature.SignedData)
+	if err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	// the current key is the last one
+	for i := len(device.PublicKeys) - 1; i >= 0; i-- {
+		verifier, err := crypto.DecodePublicKey([]byte(device.PublicKeys[i]))
+		if err != nil {
+			return fmt.Errorf("decoding public key: %w", err)
+		}
+		if verifier.Verify([]byte(signedData.Data), decoded) == nil {
+			return nil
+		}
+	}
+	return crypto.ErrInvalidSignature
+}
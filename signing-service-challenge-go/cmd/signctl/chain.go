@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/client"
+)
+
+// chainResult is the result of chain-verify
+type chainResult struct {
+	DeviceId   string `json:"device_id"`
+	Signatures int    `json:"signatures"`
+	Valid      bool   `json:"valid"`
+}
+
+func (r chainResult) text() string {
+	return fmt.Sprintf("verified %d signatures of device %s", r.Signatures, r.DeviceId)
+}
+
+func chainVerify(ctx context.Context, a *app, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("chain-verify", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseDeviceId(values[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	device, err := c.GetDevice(ctx, id)
+	if err != nil {
+		return err
+	}
+	count, err := client.VerifyChain(device, c.ListSignatures(ctx, id, 0))
+	if err != nil {
+		return fmt.Errorf("signature chain of device %s is broken after %d valid signatures: %w", id, count, err)
+	}
+	return a.render(chainResult{DeviceId: id.String(), Signatures: count, Valid: true})
+}
+
+// exported is a device with its complete signature log, as written by export
+type exported struct {
+	ExportedAt time.Time                 `json:"exported_at"`
+	Device     *client.Device            `json:"device"`
+	Signatures []*client.SignatureRecord `json:"signatures"`
+}
+
+func export(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := flags.String("file", "", "file to write the export to, stdout if empty or -")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseDeviceId(values[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	device, err := c.GetDevice(ctx, id)
+	if err != nil {
+		return err
+	}
+	out := exported{
+		ExportedAt: time.Now().UTC(),
+		Device:     device,
+		Signatures: []*client.SignatureRecord{},
+	}
+	for signature, err := range c.ListSignatures(ctx, id, 0) {
+		if err != nil {
+			return err
+		}
+		out.Signatures = append(out.Signatures, signature)
+	}
+
+	bytes, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	bytes = append(bytes, '\n')
+	if *file == "" || *file == "-" {
+		_, err = a.stdout.Write(bytes)
+		return err
+	}
+	if err := os.WriteFile(*file, bytes, 0o644); err != nil {
+		return err
+	}
+	return a.render(message{Message: fmt.Sprintf("exported device %s with %d signatures to %s", id, len(out.Signatures), *file)})
+}
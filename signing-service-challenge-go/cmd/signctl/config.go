@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultURL is used when neither a flag, the environment nor a profile sets the url
+const defaultURL = "http://localhost:8080"
+
+// Config holds the profiles of the endpoints signctl talks to
+type Config struct {
+	// Current is the profile used when none is selected
+	Current  string              `json:"current,omitempty"`
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+}
+
+// Profile holds the settings of an endpoint
+type Profile struct {
+	URL          string `json:"url"`
+	APIKey       string `json:"api_key,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// defaultConfigPath returns $SIGNCTL_CONFIG or signctl/config.json in the user's config directory
+func defaultConfigPath() string {
+	if path := os.Getenv("SIGNCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "signctl.json"
+	}
+	return filepath.Join(dir, "signctl", "config.json")
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func loadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: make(map[string]*Profile)}
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, config); err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]*Profile)
+	}
+	return config, nil
+}
+
+// save writes the config file, it is only readable by the user as it contains API keys
+func (c *Config) save(path string) error {
+	bytes, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bytes, '\n'), 0o600)
+}
+
+// profileNames returns the names of all profiles in order
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// resolve merges the selected profile with the environment and the flags, flags take precedence
+func (c *Config) resolve(name string, flags Profile) (Profile, error) {
+	var resolved Profile
+	if name == "" {
+		name = c.Current
+	}
+	if name != "" {
+		profile, found := c.Profiles[name]
+		if !found {
+			return resolved, fmt.Errorf("unknown profile %q", name)
+		}
+		resolved = *profile
+	}
+
+	for _, setting := range []struct {
+		value *string
+		env   string
+		flag  string
+	}{
+		{&resolved.URL, "SIGNCTL_URL", flags.URL},
+		{&resolved.APIKey, "SIGNCTL_API_KEY", flags.APIKey},
+		{&resolved.Organization, "SIGNCTL_ORGANIZATION", flags.Organization},
+	} {
+		if value := os.Getenv(setting.env); value != "" {
+			*setting.value = value
+		}
+		if setting.flag != "" {
+			*setting.value = setting.flag
+		}
+	}
+	if resolved.URL == "" {
+		resolved.URL = defaultURL
+	}
+	resolved.URL = strings.TrimSuffix(resolved.URL, "/")
+	return resolved, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/client"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// devices is a list of devices printed as a table
+type devices []*client.Device
+
+func (d devices) header() []string {
+	return []string{"ID", "ALGORITHM", "STATUS", "LABEL", "SIGNATURES", "TAGS"}
+}
+
+func (d devices) rows() [][]string {
+	rows := make([][]string, 0, len(d))
+	for _, device := range d {
+		rows = append(rows, []string{
+			device.Id.String(),
+			string(device.SigningAlgorithm),
+			string(device.Status),
+			device.Label,
+			strconv.Itoa(device.SignatureCounter),
+			strings.Join(device.Tags, ","),
+		})
+	}
+	return rows
+}
+
+// deviceDetails is a single device printed as a table of its attributes
+type deviceDetails struct {
+	*client.Device
+}
+
+func (d deviceDetails) header() []string {
+	return []string{"FIELD", "VALUE"}
+}
+
+func (d deviceDetails) rows() [][]string {
+	quota := "unlimited"
+	if d.MonthlySignatureQuota != nil {
+		quota = strconv.Itoa(*d.MonthlySignatureQuota)
+	}
+	rows := [][]string{
+		{"id", d.Id.String()},
+		{"algorithm", string(d.SigningAlgorithm)},
+		{"status", string(d.Status)},
+		{"label", d.Label},
+		{"signatures", strconv.Itoa(d.SignatureCounter)},
+		{"monthly usage", fmt.Sprintf("%d of %s", d.MonthlySignatureUsage, quota)},
+		{"tags", strings.Join(d.Tags, ",")},
+		{"metadata", keyValues(d.Metadata).String()},
+		{"client certificates", strings.Join(d.ClientCertificates, ",")},
+		{"key versions", strconv.Itoa(len(d.PublicKeys))},
+	}
+	return rows
+}
+
+// parseDeviceId parses a device id argument
+func parseDeviceId(value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid device id %q", value)
+	}
+	return id, nil
+}
+
+func deviceCreate(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("device create", flag.ContinueOnError)
+	algorithm := flags.String("algorithm", string(domain.SigningAlgorithmEcc), "signing algorithm, ECC or RSA")
+	label := flags.String("label", "", "label of the device")
+	quota := flags.Int("quota", -1, "monthly signature quota, unlimited if negative")
+	var tags stringList
+	flags.Var(&tags, "tag", "tag of the device, can be repeated")
+	metadata := keyValues{}
+	flags.Var(metadata, "meta", "metadata entry as KEY=VALUE, can be repeated")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	input := client.CreateDevice{
+		SigningAlgorithm: domain.SigningAlgorithm(strings.ToUpper(*algorithm)),
+		Label:            *label,
+		Tags:             tags,
+		Metadata:         metadata,
+	}
+	if *quota >= 0 {
+		input.MonthlySignatureQuota = quota
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	device, err := c.CreateDevice(ctx, input)
+	if err != nil {
+		return err
+	}
+	return a.render(deviceDetails{device})
+}
+
+func deviceList(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("device list", flag.ContinueOnError)
+	var tags stringList
+	flags.Var(&tags, "tag", "only devices with the tag, can be repeated")
+	metadata := keyValues{}
+	flags.Var(metadata, "meta", "only devices with the metadata entry KEY=VALUE, can be repeated")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	list := devices{}
+	for device, err := range c.ListDevices(ctx, client.DeviceFilter{Tags: tags, Metadata: metadata}) {
+		if err != nil {
+			return err
+		}
+		list = append(list, device)
+	}
+	return a.render(list)
+}
+
+func deviceGet(ctx context.Context, a *app, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("device get", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseDeviceId(values[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	device, err := c.GetDevice(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.render(deviceDetails{device})
+}
+
+func deviceDelete(ctx context.Context, a *app, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("device delete", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseDeviceId(values[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteDevice(ctx, id); err != nil {
+		return err
+	}
+	return a.render(message{Message: "deleted device " + id.String()})
+}
@@ -0,0 +1,215 @@
+// Command signctl manages the devices of the signature service and signs data from the command line.
+//
+// Settings are taken from flags, the environment (SIGNCTL_URL, SIGNCTL_API_KEY, SIGNCTL_ORGANIZATION)
+// and the selected profile of the config file, in that order of precedence.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/client"
+)
+
+// errUsage marks errors caused by invalid arguments
+var errUsage = errors.New("usage")
+
+// command runs a subcommand with its arguments
+type command struct {
+	usage       string
+	description string
+	run         func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"device create":  {"[-algorithm ECC|RSA] [-label LABEL] [-tag TAG]... [-meta KEY=VALUE]... [-quota N]", "create a device", deviceCreate},
+	"device list":    {"[-tag TAG]... [-meta KEY=VALUE]...", "list devices", deviceList},
+	"device get":     {"DEVICE_ID", "show a device", deviceGet},
+	"device delete":  {"DEVICE_ID", "delete a device", deviceDelete},
+	"sign":           {"DEVICE_ID [-file FILE | -data DATA] [-idempotency-key KEY]", "sign data, read from stdin by default", sign},
+	"verify":         {"DEVICE_ID [-file FILE | -signature SIGNATURE -signed-data SIGNED_DATA]", "verify a signature, the output of sign is read from stdin by default", verify},
+	"chain-verify":   {"DEVICE_ID", "verify the complete signature log of a device", chainVerify},
+	"export":         {"DEVICE_ID [-file FILE]", "export a device with its signature log as json", export},
+	"profile list":   {"", "list the profiles of the config file", profileList},
+	"profile set":    {"NAME [-url URL] [-api-key KEY] [-organization ID]", "create or update a profile", profileSet},
+	"profile use":    {"NAME", "make a profile the current one", profileUse},
+	"profile delete": {"NAME", "delete a profile", profileDelete},
+}
+
+// app holds the state shared by all commands
+type app struct {
+	stdin      io.Reader
+	stdout     io.Writer
+	configPath string
+	config     *Config
+	profile    string
+	flags      Profile
+	output     string
+}
+
+// client creates an API client from the resolved settings
+func (a *app) client() (*client.Client, error) {
+	profile, err := a.config.resolve(a.profile, a.flags)
+	if err != nil {
+		return nil, err
+	}
+	options := []client.Option{}
+	if profile.APIKey != "" {
+		options = append(options, client.WithAPIKey(profile.APIKey))
+	}
+	if profile.Organization != "" {
+		options = append(options, client.WithOrganization(profile.Organization))
+	}
+	return client.New(profile.URL, options...)
+}
+
+// render writes a result in the selected output format
+func (a *app) render(value any) error {
+	return render(a.stdout, a.output, value)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	a := &app{stdin: stdin, stdout: stdout}
+
+	flags := flag.NewFlagSet("signctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { usage(stderr, flags) }
+	flags.StringVar(&a.configPath, "config", defaultConfigPath(), "config file with the profiles (defaults to $SIGNCTL_CONFIG)")
+	flags.StringVar(&a.profile, "profile", os.Getenv("SIGNCTL_PROFILE"), "profile to use instead of the current one (defaults to $SIGNCTL_PROFILE)")
+	flags.StringVar(&a.flags.URL, "url", "", "url of the signature service")
+	flags.StringVar(&a.flags.APIKey, "api-key", "", "api key to authenticate with")
+	flags.StringVar(&a.flags.Organization, "organization", "", "organization to operate on, requires an admin key")
+	flags.StringVar(&a.output, "output", outputTable, "output format, table or json")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of the command")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if a.output != outputTable && a.output != outputJSON {
+		fmt.Fprintf(stderr, "signctl: unknown output format %q\n", a.output)
+		flags.Usage()
+		return 2
+	}
+
+	name, cmd, rest, found := findCommand(flags.Args())
+	if !found {
+		flags.Usage()
+		return 2
+	}
+
+	config, err := loadConfig(a.configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "signctl: %v\n", err)
+		return 1
+	}
+	a.config = config
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	if err := cmd.run(ctx, a, rest); err != nil {
+		if errors.Is(err, errUsage) {
+			if err != errUsage {
+				fmt.Fprintf(stderr, "signctl: %v\n", err)
+			}
+			fmt.Fprintf(stderr, "usage: signctl %s %s\n", name, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "signctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// findCommand looks up the command named by the first one or two arguments
+func findCommand(args []string) (string, command, []string, bool) {
+	for words := min(2, len(args)); words > 0; words-- {
+		name := strings.Join(args[:words], " ")
+		if cmd, found := commands[name]; found {
+			return name, cmd, args[words:], true
+		}
+	}
+	return "", command{}, nil, false
+}
+
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: signctl [flags] COMMAND [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-15s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(w, "\nflags:")
+	flags.PrintDefaults()
+}
+
+// parseArgs parses the flags of a command, which may follow its positional arguments, and returns the positional arguments
+func parseArgs(flags *flag.FlagSet, args []string, positional int) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var values []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		values = append(values, args[0])
+		args = args[1:]
+	}
+	if len(values) != positional {
+		return nil, errUsage
+	}
+	return values, nil
+}
+
+// stringList is a flag which can be given multiple times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// keyValues is a flag of KEY=VALUE pairs which can be given multiple times
+type keyValues map[string]string
+
+func (k keyValues) String() string {
+	pairs := make([]string, 0, len(k))
+	for key, value := range k {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (k keyValues) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found || key == "" {
+		return errors.New("expected KEY=VALUE")
+	}
+	k[key] = val
+	return nil
+}
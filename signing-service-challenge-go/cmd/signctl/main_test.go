@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testBootstrapKey = "sk_bootstrap"
+
+// signctl runs the command line with a config file in the directory and returns the exit code and output
+type signctl func(stdin string, args ...string) (int, string, string)
+
+func newSignctl(t *testing.T) (signctl, *httptest.Server) {
+	server := httptest.NewServer(api.NewServer(
+		persistence.NewMemoryStorage(),
+		lock.NewMemoryLocker[uuid.UUID](),
+		api.WithAuthentication(testBootstrapKey),
+	).Handler())
+	t.Cleanup(server.Close)
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	return func(stdin string, args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), append([]string{"-config", configPath}, args...), strings.NewReader(stdin), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}, server
+}
+
+// TestProfiles verifies that profiles select the endpoint and credentials
+func TestProfiles(t *testing.T) {
+	assert := require.New(t)
+	cli, server := newSignctl(t)
+
+	// Test case 1: Without a profile the default url is used and nothing is found
+	code, _, stderr := cli("", "-url", server.URL, "device", "list")
+	assert.Equal(1, code)
+	assert.Contains(stderr, "401")
+
+	// Test case 2: The first profile becomes the current one
+	code, stdout, _ := cli("", "profile", "set", "local", "-url", server.URL, "-api-key", testBootstrapKey)
+	assert.Equal(0, code)
+	assert.Equal("saved profile local\n", stdout)
+	code, _, stderr = cli("", "device", "list")
+	assert.Equal(0, code, stderr)
+
+	// Test case 3: Profiles can be switched, flags take precedence
+	code, _, _ = cli("", "profile", "set", "other", "-url", "http://127.0.0.1:1")
+	assert.Equal(0, code)
+	code, _, _ = cli("", "profile", "use", "other")
+	assert.Equal(0, code)
+	code, _, _ = cli("", "-timeout", "1s", "device", "list")
+	assert.Equal(1, code)
+	code, _, stderr = cli("", "-profile", "local", "device", "list")
+	assert.Equal(0, code, stderr)
+	code, _, stderr = cli("", "-url", server.URL, "-api-key", testBootstrapKey, "device", "list")
+	assert.Equal(0, code, stderr)
+
+	// Test case 4: API keys are not listed
+	code, stdout, _ = cli("", "-output", "json", "profile", "list")
+	assert.Equal(0, code)
+	assert.NotContains(stdout, testBootstrapKey)
+	var listed profiles
+	assert.NoError(json.Unmarshal([]byte(stdout), &listed))
+	assert.Equal("other", listed.Current)
+	assert.Len(listed.Profiles, 2)
+	code, stdout, _ = cli("", "profile", "list")
+	assert.Equal(0, code)
+	assert.Regexp(`\*\s+other`, stdout)
+
+	// Test case 5: Deleted profiles can't be used anymore
+	code, _, _ = cli("", "profile", "delete", "other")
+	assert.Equal(0, code)
+	code, _, stderr = cli("", "-profile", "other", "device", "list")
+	assert.Equal(1, code)
+	assert.Contains(stderr, `unknown profile "other"`)
+}
+
+// TestDeviceCommands verifies managing devices and signing with them
+func TestDeviceCommands(t *testing.T) {
+	assert := require.New(t)
+	cli, server := newSignctl(t)
+	code, _, _ := cli("", "profile", "set", "local", "-url", server.URL, "-api-key", testBootstrapKey)
+	assert.Equal(0, code)
+
+	// Test case 1: Create and show devices
+	code, stdout, stderr := cli("", "-output", "json", "device", "create", "-algorithm", "rsa", "-label", "till 1", "-tag", "pos", "-meta", "store=berlin")
+	assert.Equal(0, code, stderr)
+	var device struct {
+		Id               string            `json:"id"`
+		SigningAlgorithm string            `json:"signing_algorithm"`
+		Label            string            `json:"label"`
+		Metadata         map[string]string `json:"metadata"`
+	}
+	assert.NoError(json.Unmarshal([]byte(stdout), &device))
+	assert.Equal("RSA", device.SigningAlgorithm)
+	assert.Equal("till 1", device.Label)
+	assert.Equal(map[string]string{"store": "berlin"}, device.Metadata)
+
+	code, stdout, _ = cli("", "device", "get", device.Id)
+	assert.Equal(0, code)
+	assert.Contains(stdout, "till 1")
+	assert.Contains(stdout, "store=berlin")
+
+	code, _, _ = cli("", "device", "create")
+	assert.Equal(0, code)
+	code, stdout, _ = cli("", "device", "list")
+	assert.Equal(0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Len(lines, 3)
+	assert.True(strings.HasPrefix(lines[0], "ID"))
+	assert.Contains(lines[1], device.Id)
+	code, stdout, _ = cli("", "device", "list", "-tag", "pos")
+	assert.Equal(0, code)
+	assert.Len(strings.Split(strings.TrimSpace(stdout), "\n"), 2)
+
+	// Test case 2: Sign from stdin, a file and the command line
+	code, stdout, stderr = cli("first", "-output", "json", "sign", device.Id)
+	assert.Equal(0, code, stderr)
+	signed := stdout
+	assert.Contains(signed, "1_")
+
+	dataFile := filepath.Join(t.TempDir(), "receipt.txt")
+	assert.NoError(os.WriteFile(dataFile, []byte("second"), 0o600))
+	code, stdout, _ = cli("", "sign", device.Id, "-file", dataFile)
+	assert.Equal(0, code)
+	assert.Contains(stdout, "_second")
+	code, _, _ = cli("", "sign", device.Id, "-data", "third", "-idempotency-key", "k1")
+	assert.Equal(0, code)
+	code, stdout, _ = cli("", "-output", "json", "sign", device.Id, "-data", "third", "-idempotency-key", "k1")
+	assert.Equal(0, code)
+	assert.Contains(stdout, `"replayed": true`)
+
+	// Test case 3: Verify the output of sign
+	code, stdout, stderr = cli(signed, "verify", device.Id)
+	assert.Equal(0, code, stderr)
+	assert.Equal("signature is valid\n", stdout)
+	tampered := strings.Replace(signed, "_first", "_forged", 1)
+	code, _, stderr = cli(tampered, "verify", device.Id)
+	assert.Equal(1, code)
+	assert.Contains(stderr, "invalid signature")
+
+	// Test case 4: Verify the signature chain and export the log
+	code, stdout, stderr = cli("", "chain-verify", device.Id)
+	assert.Equal(0, code, stderr)
+	assert.Equal("verified 3 signatures of device "+device.Id+"\n", stdout)
+
+	exportFile := filepath.Join(t.TempDir(), "export.json")
+	code, _, stderr = cli("", "export", device.Id, "-file", exportFile)
+	assert.Equal(0, code, stderr)
+	content, err := os.ReadFile(exportFile)
+	assert.NoError(err)
+	var export exported
+	assert.NoError(json.Unmarshal(content, &export))
+	assert.Equal(device.Id, export.Device.Id.String())
+	assert.Len(export.Signatures, 3)
+	assert.Equal("second", export.Signatures[1].Data)
+
+	// Test case 5: Delete the device
+	code, stdout, _ = cli("", "device", "delete", device.Id)
+	assert.Equal(0, code)
+	assert.Equal("deleted device "+device.Id+"\n", stdout)
+	code, _, stderr = cli("", "device", "get", device.Id)
+	assert.Equal(1, code)
+	assert.Contains(stderr, "404")
+}
+
+// TestUsage verifies that invalid command lines are rejected
+func TestUsage(t *testing.T) {
+	assert := require.New(t)
+	cli, _ := newSignctl(t)
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"device"},
+		{"device", "get"},
+		{"device", "get", "a", "b"},
+		{"device", "create", "-unknown"},
+		{"-output", "yaml", "device", "list"},
+	} {
+		code, _, stderr := cli("", args...)
+		assert.Equal(2, code, args)
+		assert.Contains(stderr, "usage", args)
+	}
+
+	code, _, stderr := cli("", "device", "get", "not-a-uuid")
+	assert.Equal(1, code)
+	assert.Contains(stderr, `invalid device id "not-a-uuid"`)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// table is a result which can be printed as a table
+type table interface {
+	header() []string
+	rows() [][]string
+}
+
+// text is a result which is printed as a line of text
+type text interface {
+	text() string
+}
+
+// render writes the value in the output format, values which are neither tables nor text are always written as json
+func render(w io.Writer, format string, value any) error {
+	if t, isText := value.(text); isText && format == outputTable {
+		_, err := fmt.Fprintln(w, t.text())
+		return err
+	}
+	t, isTable := value.(table)
+	if format == outputJSON || !isTable {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header(), "\t"))
+	for _, row := range t.rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// message is a plain result of a command
+type message struct {
+	Message string `json:"message"`
+}
+
+func (m message) text() string {
+	return m.Message
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// profiles lists the profiles of the config file
+type profiles struct {
+	Current  string              `json:"current"`
+	Profiles map[string]*Profile `json:"profiles"`
+	names    []string
+}
+
+func (p profiles) header() []string {
+	return []string{"CURRENT", "NAME", "URL", "ORGANIZATION", "API KEY"}
+}
+
+func (p profiles) rows() [][]string {
+	rows := make([][]string, 0, len(p.names))
+	for _, name := range p.names {
+		profile := p.Profiles[name]
+		current := ""
+		if name == p.Current {
+			current = "*"
+		}
+		apiKey := ""
+		if profile.APIKey != "" {
+			apiKey = "set"
+		}
+		rows = append(rows, []string{current, name, profile.URL, profile.Organization, apiKey})
+	}
+	return rows
+}
+
+func profileList(_ context.Context, a *app, args []string) error {
+	if _, err := parseArgs(flag.NewFlagSet("profile list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+
+	// api keys are secrets, they are only shown in the config file itself
+	redacted := make(map[string]*Profile, len(a.config.Profiles))
+	for name, profile := range a.config.Profiles {
+		copied := *profile
+		if copied.APIKey != "" {
+			copied.APIKey = "<redacted>"
+		}
+		redacted[name] = &copied
+	}
+	return a.render(profiles{Current: a.config.Current, Profiles: redacted, names: a.config.profileNames()})
+}
+
+func profileSet(_ context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("profile set", flag.ContinueOnError)
+	url := flags.String("url", "", "url of the signature service")
+	apiKey := flags.String("api-key", "", "api key to authenticate with")
+	organization := flags.String("organization", "", "organization to operate on, requires an admin key")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	name := values[0]
+
+	profile, found := a.config.Profiles[name]
+	if !found {
+		profile = &Profile{URL: defaultURL}
+		a.config.Profiles[name] = profile
+	}
+	// only the given settings are changed
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "url":
+			profile.URL = *url
+		case "api-key":
+			profile.APIKey = *apiKey
+		case "organization":
+			profile.Organization = *organization
+		}
+	})
+	if a.config.Current == "" {
+		a.config.Current = name
+	}
+
+	if err := a.config.save(a.configPath); err != nil {
+		return err
+	}
+	return a.render(message{Message: fmt.Sprintf("saved profile %s", name)})
+}
+
+func profileUse(_ context.Context, a *app, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("profile use", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	name := values[0]
+	if _, found := a.config.Profiles[name]; !found {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	a.config.Current = name
+	if err := a.config.save(a.configPath); err != nil {
+		return err
+	}
+	return a.render(message{Message: fmt.Sprintf("using profile %s", name)})
+}
+
+func profileDelete(_ context.Context, a *app, args []string) error {
+	values, err := parseArgs(flag.NewFlagSet("profile delete", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	name := values[0]
+	if _, found := a.config.Profiles[name]; !found {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	delete(a.config.Profiles, name)
+	if a.config.Current == name {
+		a.config.Current = ""
+	}
+	if err := a.config.save(a.configPath); err != nil {
+		return err
+	}
+	return a.render(message{Message: fmt.Sprintf("deleted profile %s", name)})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/client"
+)
+
+// signature is the result of sign, it is accepted as input by verify
+type signature struct {
+	DeviceId string `json:"device_id"`
+	*client.Signature
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+func (s signature) header() []string {
+	return []string{"SIGNATURE", "SIGNED DATA"}
+}
+
+func (s signature) rows() [][]string {
+	return [][]string{{s.Signature.Signature, s.SignedData}}
+}
+
+// readInput returns the data of the file, or of stdin if the file is empty or "-"
+func (a *app) readInput(file string) ([]byte, error) {
+	if file == "" || file == "-" {
+		return io.ReadAll(a.stdin)
+	}
+	return os.ReadFile(file)
+}
+
+func sign(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("sign", flag.ContinueOnError)
+	file := flags.String("file", "", "file with the data to sign, stdin if empty or -")
+	data := flags.String("data", "", "data to sign instead of reading it")
+	idempotencyKey := flags.String("idempotency-key", "", "idempotency key, signing again with it returns the original signature")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseDeviceId(values[0])
+	if err != nil {
+		return err
+	}
+
+	input := *data
+	if input == "" {
+		bytes, err := a.readInput(*file)
+		if err != nil {
+			return err
+		}
+		input = string(bytes)
+	}
+
+	var options []client.SignOption
+	if *idempotencyKey != "" {
+		options = append(options, client.WithIdempotencyKey(*idempotencyKey))
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	signed, err := c.Sign(ctx, id, input, options...)
+	if err != nil {
+		return err
+	}
+	return a.render(signature{DeviceId: id.String(), Signature: signed, Replayed: signed.Replayed})
+}
+
+func verify(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	file := flags.String("file", "", "file with the json output of sign, stdin if empty or -")
+	signatureValue := flags.String("signature", "", "base64 encoded signature instead of reading the output of sign")
+	signedData := flags.String("signed-data", "", "signed data belonging to the signature")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseDeviceId(values[0])
+	if err != nil {
+		return err
+	}
+
+	toVerify := &client.Signature{Signature: *signatureValue, SignedData: *signedData}
+	if *signatureValue == "" {
+		bytes, err := a.readInput(*file)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(bytes, toVerify); err != nil {
+			return fmt.Errorf("reading signature: %w", err)
+		}
+	}
+	if toVerify.Signature == "" || toVerify.SignedData == "" {
+		return errors.New("signature and signed data are required")
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return err
+	}
+	if err := c.Verify(ctx, id, toVerify); err != nil {
+		return err
+	}
+	return a.render(message{Message: "signature is valid"})
+}
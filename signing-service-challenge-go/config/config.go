@@ -0,0 +1,348 @@
+// Package config holds the settings of the service. Settings are read from a YAML or TOML file,
+// the environment and the command line, see [Load].
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/tracing"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/webhook"
+	"github.com/redis/go-redis/v9"
+)
+
+// StorageBackend selects where devices, signatures and all other data are stored
+type StorageBackend string
+
+const (
+	// StorageMemory keeps all data in memory, it is lost when the process exits
+	StorageMemory StorageBackend = "memory"
+)
+
+func (b *StorageBackend) String() string {
+	return string(*b)
+}
+
+func (b *StorageBackend) Set(value string) error {
+	switch backend := StorageBackend(value); backend {
+	case StorageMemory:
+		*b = backend
+		return nil
+	}
+	return fmt.Errorf("unknown storage backend %q, expected %s", value, StorageMemory)
+}
+
+// LockBackend selects how signing is serialized per device
+type LockBackend string
+
+const (
+	// LockMemory serializes signing within the process, only a single replica may run
+	LockMemory LockBackend = "memory"
+	// LockRedis serializes signing across replicas with leases in Redis
+	LockRedis LockBackend = "redis"
+)
+
+func (b *LockBackend) String() string {
+	return string(*b)
+}
+
+func (b *LockBackend) Set(value string) error {
+	switch backend := LockBackend(value); backend {
+	case LockMemory, LockRedis:
+		*b = backend
+		return nil
+	}
+	return fmt.Errorf("unknown lock backend %q, expected %s or %s", value, LockMemory, LockRedis)
+}
+
+// Lock configures the device locks
+type Lock struct {
+	Backend LockBackend
+	// TTL is how long the lease of a crashed replica blocks the device
+	TTL time.Duration
+	// RedisURL is the redis:// or rediss:// URL of the lease store
+	RedisURL string
+}
+
+// LogFormat selects how log lines are written
+type LogFormat string
+
+const (
+	// LogFormatText writes log lines as key=value pairs
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes log lines as json objects, meant for log collectors
+	LogFormatJSON LogFormat = "json"
+)
+
+func (f *LogFormat) String() string {
+	return string(*f)
+}
+
+func (f *LogFormat) Set(value string) error {
+	switch format := LogFormat(value); format {
+	case LogFormatText, LogFormatJSON:
+		*f = format
+		return nil
+	}
+	return fmt.Errorf("unknown log format %q, expected %s or %s", value, LogFormatText, LogFormatJSON)
+}
+
+// algorithms is a comma separated list of signing algorithms, setting it replaces the whole list
+type algorithms []domain.SigningAlgorithm
+
+func (a *algorithms) String() string {
+	names := make([]string, len(*a))
+	for i, algorithm := range *a {
+		names[i] = string(algorithm)
+	}
+	return strings.Join(names, ",")
+}
+
+func (a *algorithms) Set(value string) error {
+	var parsed algorithms
+	for name := range strings.SplitSeq(value, ",") {
+		algorithm := domain.SigningAlgorithm(strings.TrimSpace(name))
+		if err := algorithm.Validate(); err != nil {
+			return err
+		}
+		if !slices.Contains(parsed, algorithm) {
+			parsed = append(parsed, algorithm)
+		}
+	}
+	*a = parsed
+	return nil
+}
+
+// Log configures the log lines of the service
+type Log struct {
+	Level  slog.Level
+	Format LogFormat
+}
+
+// Handler creates the log handler writing to w, its level can be changed at runtime with the returned variable
+func (l Log) Handler(w io.Writer) (slog.Handler, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	level.Set(l.Level)
+	options := &slog.HandlerOptions{Level: level}
+	if l.Format == LogFormatJSON {
+		return slog.NewJSONHandler(w, options), level
+	}
+	return slog.NewTextHandler(w, options), level
+}
+
+// Admin configures the listener of the admin endpoints, see the admin package
+type Admin struct {
+	// ListenAddress disables the admin endpoints if empty, it shouldn't be reachable by clients
+	ListenAddress string
+	Username      string
+	Password      string
+}
+
+// minRSAKeySize is the smallest RSA key size the service may be configured with
+const minRSAKeySize = 1024
+
+// minLockTTL keeps the lease renewals from hammering the lock backend
+const minLockTTL = time.Second
+
+// Config holds all settings of the service
+type Config struct {
+	ListenAddress  string
+	GRPCAddress    string
+	Storage        StorageBackend
+	Lock           Lock
+	Crypto         deviceManager.CryptoPolicy
+	TLS            api.TLSConfig
+	AdminKey       string
+	Admin          Admin
+	Log            Log
+	RateLimits     api.RateLimits
+	ErrorFormat    api.ErrorFormat
+	IdempotencyTTL time.Duration
+	WebhookPoll    time.Duration
+	WebhookPrivate bool
+	WebhookRetain  time.Duration
+	EventLog       bool
+	EventFile      string
+	Metrics        bool
+	TraceExporter  tracing.Exporter
+	Timeouts       api.Timeouts
+}
+
+// Default returns the settings used unless configured otherwise
+func Default() Config {
+	return Config{
+		ListenAddress: ":8080",
+		GRPCAddress:   ":9090",
+		Storage:       StorageMemory,
+		Lock:          Lock{Backend: LockMemory, TTL: lock.DefaultLeaseTTL},
+		Crypto: deviceManager.CryptoPolicy{
+			Algorithms: []domain.SigningAlgorithm{domain.SigningAlgorithmEcc, domain.SigningAlgorithmRsa},
+			RSAKeySize: 2048,
+		},
+		Admin:          Admin{Username: "admin"},
+		Log:            Log{Level: slog.LevelInfo, Format: LogFormatText},
+		ErrorFormat:    api.ErrorFormatProblem,
+		IdempotencyTTL: deviceManager.DefaultIdempotencyTTL,
+		WebhookPoll:    webhook.DefaultInterval,
+		WebhookRetain:  webhook.DefaultRetention,
+		Metrics:        true,
+		TraceExporter:  tracing.ExporterNone,
+		Timeouts:       api.DefaultTimeouts,
+	}
+}
+
+// secrets are the settings which are redacted when the config is printed
+var secrets = []string{"admin-key", "admin-password", "lock-redis-url"}
+
+// RegisterFlags defines a flag for every setting, the current values are the defaults of the flags.
+// The names of the flags are the keys in the config file, see [Load].
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.ListenAddress, "listen-address", c.ListenAddress, "api listen address")
+	fs.StringVar(&c.GRPCAddress, "grpc-listen-address", c.GRPCAddress, "grpc api listen address, disabled if empty")
+	fs.Var(&c.Storage, "storage-backend", "where data is stored, only memory is supported")
+	fs.Var(&c.Lock.Backend, "lock-backend", "how signing is serialized per device, memory for a single replica or redis for several")
+	fs.DurationVar(&c.Lock.TTL, "lock-ttl", c.Lock.TTL, "how long the lock lease of a crashed replica blocks its device, renewed at a third of it, at least 1s")
+	fs.StringVar(&c.Lock.RedisURL, "lock-redis-url", c.Lock.RedisURL, "redis url of the lock leases, e.g. redis://localhost:6379/0")
+	fs.Var((*algorithms)(&c.Crypto.Algorithms), "crypto-algorithms", "comma separated signing algorithms new devices may use, ECC and RSA")
+	fs.IntVar(&c.Crypto.RSAKeySize, "crypto-rsa-key-size", c.Crypto.RSAKeySize, "size of generated rsa keys in bits")
+	fs.StringVar(&c.TLS.CertFile, "tls-cert", c.TLS.CertFile, "tls certificate file, enables tls together with -tls-key")
+	fs.StringVar(&c.TLS.KeyFile, "tls-key", c.TLS.KeyFile, "tls private key file")
+	fs.StringVar(&c.TLS.ClientCAFile, "tls-client-ca", c.TLS.ClientCAFile, "ca file to verify client certificates with, enables mutual tls")
+	fs.BoolVar(&c.TLS.RequireClientCert, "tls-require-client-cert", c.TLS.RequireClientCert, "reject clients without a valid client certificate")
+	fs.StringVar(&c.AdminKey, "admin-key", c.AdminKey, "bootstrap admin api key, enables authentication")
+	fs.StringVar(&c.Admin.ListenAddress, "admin-listen-address", c.Admin.ListenAddress, "listen address of the admin endpoints with pprof, runtime stats, log level, locks and storage stats, disabled if empty")
+	fs.StringVar(&c.Admin.Username, "admin-username", c.Admin.Username, "username of the admin endpoints")
+	fs.StringVar(&c.Admin.Password, "admin-password", c.Admin.Password, "password of the admin endpoints, required with -admin-listen-address")
+	fs.TextVar(&c.Log.Level, "log-level", c.Log.Level, "minimum level of log lines, debug, info, warn or error")
+	fs.Var(&c.Log.Format, "log-format", "format of log lines, text or json")
+	fs.Var(&c.RateLimits.Global, "rate-limit-global", "rate limit of all requests as <per second>:<burst>, unlimited if zero")
+	fs.Var(&c.RateLimits.PerClient, "rate-limit-client", "rate limit per api client as <per second>:<burst>, unlimited if zero")
+	fs.Var(&c.RateLimits.PerDevice, "rate-limit-device", "rate limit of signatures per device as <per second>:<burst>, unlimited if zero")
+	fs.Var(&c.ErrorFormat, "error-format", "error format for clients which don't ask for one, problem or legacy")
+	fs.DurationVar(&c.IdempotencyTTL, "idempotency-ttl", c.IdempotencyTTL, "how long signing results are kept for retries with the same Idempotency-Key")
+	fs.DurationVar(&c.WebhookPoll, "webhook-poll-interval", c.WebhookPoll, "how often pending webhook deliveries are sent")
+	fs.DurationVar(&c.WebhookRetain, "webhook-retention", c.WebhookRetain, "how long delivered and failed webhook deliveries are kept in the delivery log")
+	fs.BoolVar(&c.WebhookPrivate, "webhook-private-targets", c.WebhookPrivate, "allow webhooks to private, loopback and link-local addresses, e.g. for development")
+	fs.BoolVar(&c.EventLog, "event-log", c.EventLog, "write all device events to the log")
+	fs.StringVar(&c.EventFile, "event-file", c.EventFile, "append all device events as json lines to the file")
+	fs.BoolVar(&c.Metrics, "metrics", c.Metrics, "serve prometheus metrics on /metrics of the admin listener, or of the api listener if the admin listener is disabled")
+	fs.Var(&c.TraceExporter, "trace-exporter", "where opentelemetry spans are sent, none, stdout or otlp (configured with $OTEL_EXPORTER_OTLP_ENDPOINT)")
+	fs.DurationVar(&c.Timeouts.Read, "read-timeout", c.Timeouts.Read, "maximum duration for reading a whole request")
+	fs.DurationVar(&c.Timeouts.Write, "write-timeout", c.Timeouts.Write, "maximum duration for handling a request and writing the response, event streams are exempt")
+	fs.DurationVar(&c.Timeouts.Idle, "idle-timeout", c.Timeouts.Idle, "how long idle keep-alive connections are kept open")
+	fs.DurationVar(&c.Timeouts.Shutdown, "shutdown-timeout", c.Timeouts.Shutdown, "how long in-flight requests are awaited on SIGINT or SIGTERM")
+}
+
+// TLSEnabled tells whether the servers are served over TLS
+func (c *Config) TLSEnabled() bool {
+	return c.TLS.CertFile != "" || c.TLS.KeyFile != ""
+}
+
+// Validate checks the settings before anything is started, it reports all invalid settings at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(setting string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+		}
+	}
+
+	check("listen-address", validateAddress(c.ListenAddress))
+	if c.GRPCAddress != "" {
+		check("grpc-listen-address", validateAddress(c.GRPCAddress))
+		if c.GRPCAddress == c.ListenAddress {
+			check("grpc-listen-address", errors.New("must differ from listen-address"))
+		}
+	}
+
+	if c.Lock.Backend == LockRedis {
+		if c.Lock.RedisURL == "" {
+			check("lock-redis-url", errors.New("is required with the redis lock backend"))
+		} else if _, err := redis.ParseURL(c.Lock.RedisURL); err != nil {
+			// the url isn't part of the error, it may contain a password
+			check("lock-redis-url", errors.New("must be a redis:// or rediss:// url"))
+		}
+	}
+	if c.Lock.TTL < minLockTTL {
+		check("lock-ttl", fmt.Errorf("must be at least %s", minLockTTL))
+	}
+
+	if len(c.Crypto.Algorithms) == 0 {
+		check("crypto-algorithms", errors.New("at least one signing algorithm is required"))
+	}
+	if c.Crypto.RSAKeySize < minRSAKeySize || c.Crypto.RSAKeySize%8 != 0 {
+		check("crypto-rsa-key-size", fmt.Errorf("must be a multiple of 8 and at least %d bits", minRSAKeySize))
+	}
+
+	if c.TLSEnabled() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			check("tls-cert", errors.New("tls-cert and tls-key are required together"))
+		} else {
+			_, err := c.TLS.ServerConfig()
+			check("tls", err)
+		}
+	} else if c.TLS.ClientCAFile != "" || c.TLS.RequireClientCert {
+		check("tls-client-ca", errors.New("client certificates need tls-cert and tls-key"))
+	}
+
+	if c.AdminKey != "" && strings.TrimSpace(c.AdminKey) != c.AdminKey {
+		check("admin-key", errors.New("must not start or end with whitespace"))
+	}
+
+	if c.Admin.ListenAddress != "" {
+		check("admin-listen-address", validateAddress(c.Admin.ListenAddress))
+		if c.Admin.ListenAddress == c.ListenAddress || c.Admin.ListenAddress == c.GRPCAddress {
+			check("admin-listen-address", errors.New("must differ from the api listen addresses"))
+		}
+		if c.Admin.Username == "" {
+			check("admin-username", errors.New("is required with admin-listen-address"))
+		}
+		if c.Admin.Password == "" {
+			check("admin-password", errors.New("is required with admin-listen-address"))
+		}
+	}
+
+	if c.EventFile != "" {
+		if info, err := os.Stat(c.EventFile); err == nil && info.IsDir() {
+			check("event-file", errors.New("is a directory"))
+		}
+	}
+
+	for setting, duration := range map[string]time.Duration{
+		"idempotency-ttl":       c.IdempotencyTTL,
+		"webhook-poll-interval": c.WebhookPoll,
+		"webhook-retention":     c.WebhookRetain,
+		"read-timeout":          c.Timeouts.Read,
+		"write-timeout":         c.Timeouts.Write,
+		"idle-timeout":          c.Timeouts.Idle,
+		"shutdown-timeout":      c.Timeouts.Shutdown,
+	} {
+		if duration <= 0 {
+			check(setting, errors.New("must be positive"))
+		}
+	}
+
+	// the errors are sorted, so the report doesn't depend on the order of the map
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+func validateAddress(address string) error {
+	if address == "" {
+		return errors.New("is required")
+	}
+	_, _, err := net.SplitHostPort(address)
+	return err
+}
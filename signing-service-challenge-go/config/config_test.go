@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+// env is a fake environment for lookupEnv
+type env map[string]string
+
+func (e env) lookup(name string) (string, bool) {
+	value, found := e[name]
+	return value, found
+}
+
+func writeFile(assert *require.Assertions, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestParsePrecedence(t *testing.T) {
+	assert := require.New(t)
+	path := writeFile(assert, t.TempDir(), "config.yaml", `
+listen-address: ":8000"
+grpc-listen-address: ":9000"
+log:
+  level: debug
+  format: json
+crypto:
+  algorithms: [ECC]
+rate-limit:
+  global: "100:10"
+`)
+
+	// Test case 1: Flags override the environment, which overrides the file, the rest keeps the defaults
+	c, err := Parse("test", []string{"-config", path, "-listen-address", ":8001"}, env{
+		"SIGNING_LISTEN_ADDRESS": ":8002",
+		"SIGNING_LOG_LEVEL":      "warn",
+	}.lookup, io.Discard)
+	assert.NoError(err)
+	assert.Equal(":8001", c.ListenAddress)
+	assert.Equal(":9000", c.GRPCAddress)
+	assert.Equal(slog.LevelWarn, c.Log.Level)
+	assert.Equal(LogFormatJSON, c.Log.Format)
+	assert.Equal([]domain.SigningAlgorithm{domain.SigningAlgorithmEcc}, c.Crypto.Algorithms)
+	assert.Equal(ratelimit.Rate{PerSecond: 100, Burst: 10}, c.RateLimits.Global)
+	assert.Equal(2048, c.Crypto.RSAKeySize)
+	assert.Equal(StorageMemory, c.Storage)
+
+	// Test case 2: The file can be named in the environment, the legacy admin key variable is still honored
+	c, err = Parse("test", nil, env{"SIGNING_CONFIG": path, "ADMIN_KEY": "sk_admin"}.lookup, io.Discard)
+	assert.NoError(err)
+	assert.Equal(":8000", c.ListenAddress)
+	assert.Equal("sk_admin", c.AdminKey)
+}
+
+func TestParseTOML(t *testing.T) {
+	assert := require.New(t)
+	path := writeFile(assert, t.TempDir(), "config.toml", `
+admin-key = "sk_admin"
+shutdown-timeout = "5s"
+
+[crypto]
+algorithms = ["RSA", "ECC"]
+rsa-key-size = 4096
+
+[storage]
+backend = "memory"
+`)
+
+	c, err := Parse("test", []string{"-config", path}, env{}.lookup, io.Discard)
+	assert.NoError(err)
+	assert.Equal("sk_admin", c.AdminKey)
+	assert.Equal(5*time.Second, c.Timeouts.Shutdown)
+	assert.Equal([]domain.SigningAlgorithm{domain.SigningAlgorithmRsa, domain.SigningAlgorithmEcc}, c.Crypto.Algorithms)
+	assert.Equal(4096, c.Crypto.RSAKeySize)
+}
+
+func TestParseInvalid(t *testing.T) {
+	assert := require.New(t)
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		name    string
+		args    []string
+		env     env
+		message string
+	}{
+		{"unknown setting", []string{"-config", writeFile(assert, dir, "unknown.yaml", "listen-adress: :80\n")}, nil, `unknown setting "listen-adress"`},
+		{"invalid file value", []string{"-config", writeFile(assert, dir, "invalid.yaml", "log:\n  format: xml\n")}, nil, `invalid value "xml" for log-format`},
+		{"unknown format", []string{"-config", writeFile(assert, dir, "config.json", "{}")}, nil, `unknown format ".json"`},
+		{"missing file", []string{"-config", filepath.Join(dir, "missing.yaml")}, nil, "no such file"},
+		{"invalid environment", nil, env{"SIGNING_CRYPTO_ALGORITHMS": "ECC,DSA"}, "environment SIGNING_CRYPTO_ALGORITHMS"},
+		{"unknown flag", []string{"-foo"}, nil, "flag provided but not defined"},
+		{"arguments", []string{"serve"}, nil, "unexpected arguments"},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String(FileFlag, "", "")
+		c := Default()
+		c.RegisterFlags(fs)
+		err := Load(fs, tc.args, tc.env.lookup)
+		assert.ErrorContains(err, tc.message, tc.name)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert := require.New(t)
+
+	// Test case 1: The defaults are valid
+	c := Default()
+	assert.NoError(c.Validate())
+
+	// Test case 2: All invalid settings are reported at once
+	c.ListenAddress = "8080"
+	c.GRPCAddress = "8080"
+	c.Crypto.Algorithms = nil
+	c.Crypto.RSAKeySize = 512
+	c.TLS.CertFile = "cert.pem"
+	c.Timeouts.Shutdown = 0
+	err := c.Validate()
+	assert.Error(err)
+	for _, setting := range []string{"listen-address", "grpc-listen-address", "crypto-algorithms", "crypto-rsa-key-size", "tls-cert", "shutdown-timeout"} {
+		assert.ErrorContains(err, setting+":")
+	}
+
+	// Test case 3: Client certificates need tls
+	c = Default()
+	c.TLS.RequireClientCert = true
+	assert.ErrorContains(c.Validate(), "tls-client-ca")
+
+	// Test case 4: The redis lock backend needs a valid url
+	c = Default()
+	c.Lock.Backend = LockRedis
+	assert.ErrorContains(c.Validate(), "lock-redis-url: is required")
+	c.Lock.RedisURL = "localhost:6379"
+	assert.ErrorContains(c.Validate(), "lock-redis-url: must be")
+	c.Lock.RedisURL = "redis://localhost:6379/0"
+	assert.NoError(c.Validate())
+	c.Lock.TTL = time.Millisecond
+	assert.ErrorContains(c.Validate(), "lock-ttl: must be at least 1s")
+
+	// Test case 5: The admin listener needs credentials and its own address
+	c = Default()
+	c.Admin.ListenAddress = c.ListenAddress
+	err = c.Validate()
+	assert.ErrorContains(err, "admin-listen-address: must differ")
+	assert.ErrorContains(err, "admin-password: is required")
+}
+
+func TestPrintConfig(t *testing.T) {
+	assert := require.New(t)
+	dir := t.TempDir()
+
+	// Test case 1: The effective config is printed with redacted secrets
+	var output bytes.Buffer
+	_, err := Parse("test", []string{"-print-config", "-crypto-algorithms", "RSA", "-admin-key", "sk_secret"}, env{"SIGNING_ADMIN_PASSWORD": "secret"}.lookup, &output)
+	assert.ErrorIs(err, ErrPrintConfig)
+	assert.Contains(output.String(), "crypto-algorithms: RSA\n")
+	assert.Contains(output.String(), "admin-key: REDACTED\n")
+	assert.Contains(output.String(), "admin-password: REDACTED\n")
+	assert.NotContains(output.String(), "sk_secret")
+	assert.NotContains(output.String(), "print-config")
+
+	// Test case 2: The printed config can be loaded again
+	path := writeFile(assert, dir, "printed.yaml", output.String())
+	c, err := Parse("test", []string{"-config", path}, env{}.lookup, io.Discard)
+	assert.NoError(err)
+	assert.Equal([]domain.SigningAlgorithm{domain.SigningAlgorithmRsa}, c.Crypto.Algorithms)
+
+	// Test case 3: An invalid config is printed, but the validation error is returned
+	output.Reset()
+	_, err = Parse("test", []string{"-print-config", "-listen-address", ""}, env{}.lookup, &output)
+	assert.ErrorContains(err, "listen-address: is required")
+	assert.NotEmpty(output.String())
+}
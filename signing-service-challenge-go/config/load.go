@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileFlag names the config file, it can't be set in the config file itself
+	FileFlag = "config"
+	// EnvPrefix is prepended to the environment variables of the settings
+	EnvPrefix = "SIGNING_"
+)
+
+// EnvName returns the environment variable of a setting, e.g. SIGNING_LISTEN_ADDRESS for listen-address
+func EnvName(setting string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(setting, "-", "_"))
+}
+
+// Load sets the flags of fs from the config file, the environment and the arguments.
+// Arguments take precedence over the environment, which takes precedence over the file,
+// settings missing everywhere keep the defaults of their flags.
+//
+// The file is named by the [FileFlag] flag or its environment variable. Its format is chosen by
+// the extension, .yaml, .yml or .toml. Its keys are the names of the flags, nested keys are joined
+// with a dash, so tls.cert sets tls-cert. Lists are joined with commas.
+func Load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	var path string
+	if file := fs.Lookup(FileFlag); file != nil {
+		path = file.Value.String()
+		if value, found := lookupEnv(EnvName(FileFlag)); found && !explicit[FileFlag] {
+			path = value
+		}
+	}
+	if path != "" {
+		settings, err := readFile(path)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		for _, name := range slices.Sorted(maps.Keys(settings)) {
+			f := fs.Lookup(name)
+			if f == nil || name == FileFlag {
+				return fmt.Errorf("config file %s: unknown setting %q", path, name)
+			}
+			if explicit[name] {
+				continue
+			}
+			if err := f.Value.Set(settings[name]); err != nil {
+				return fmt.Errorf("config file %s: invalid value %q for %s: %w", path, settings[name], name, err)
+			}
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || f.Name == FileFlag {
+			return
+		}
+		if value, found := lookupEnv(EnvName(f.Name)); found {
+			if setErr := f.Value.Set(value); setErr != nil {
+				err = fmt.Errorf("environment %s: invalid value %q: %w", EnvName(f.Name), value, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// readFile reads the settings of a config file as flag values by flag name
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	document := make(map[string]any)
+	switch extension := filepath.Ext(path); extension {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected .yaml, .yml or .toml", extension)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, "", document); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// flatten joins the keys of nested tables with dashes and formats all values like flags
+func flatten(settings map[string]string, prefix string, document map[string]any) error {
+	for key, value := range document {
+		name := key
+		if prefix != "" {
+			name = prefix + "-" + key
+		}
+		if _, found := settings[name]; found {
+			return fmt.Errorf("setting %q is set twice", name)
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			if err := flatten(settings, name, value); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			settings[name] = strings.Join(items, ",")
+		case nil:
+			settings[name] = ""
+		default:
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+	return nil
+}
+
+// Print writes the effective settings of fs as YAML config file, secrets are redacted.
+// The output can be loaded again, apart from the redacted secrets.
+func Print(w io.Writer, fs *flag.FlagSet, skip ...string) error {
+	settings := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == FileFlag || slices.Contains(skip, f.Name) {
+			return
+		}
+		value := f.Value.String()
+		if value != "" && slices.Contains(secrets, f.Name) {
+			value = "REDACTED"
+		}
+		settings[f.Name] = value
+	})
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(settings); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// printFlag prints the effective config instead of starting the service
+const printFlag = "print-config"
+
+// ErrPrintConfig is returned by [Parse] when the config was printed instead of starting the service
+var ErrPrintConfig = errors.New("config printed")
+
+// Parse loads and validates the config of the service from the arguments, the environment and the config file.
+// With the print-config flag the effective config is written to output and [ErrPrintConfig] is returned.
+func Parse(name string, args []string, lookupEnv func(string) (string, bool), output io.Writer) (Config, error) {
+	c := Default()
+	// the admin key was configured with $ADMIN_KEY before the config file existed
+	if adminKey, found := lookupEnv("ADMIN_KEY"); found {
+		c.AdminKey = adminKey
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.String(FileFlag, "", "yaml or toml config file, settings are overridden by $"+EnvPrefix+"* environment variables and flags")
+	printConfig := fs.Bool(printFlag, false, "print the effective config as yaml and exit")
+	c.RegisterFlags(fs)
+	if err := Load(fs, args, lookupEnv); err != nil {
+		return c, err
+	}
+
+	err := c.Validate()
+	if *printConfig {
+		if printErr := Print(output, fs, printFlag); printErr != nil {
+			return c, printErr
+		}
+		if err == nil {
+			err = ErrPrintConfig
+		}
+	}
+	return c, err
+}
@@ -7,10 +7,18 @@ import (
 	"crypto/rsa"
 )
 
+// DefaultRSAKeySize is the size of the keys of GenerateRSAKeyPair in bits.
+const DefaultRSAKeySize = 1024
+
 // GenerateRSAKeyPair generates a new RSAKeyPair.
 func GenerateRSAKeyPair() (*RSAKeyPair, error) {
 	// Security has been ignored for the sake of simplicity.
-	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	return GenerateRSAKeyPairOfSize(DefaultRSAKeySize)
+}
+
+// GenerateRSAKeyPairOfSize generates a new RSAKeyPair with a key of the given size in bits.
+func GenerateRSAKeyPairOfSize(bits int) (*RSAKeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,75 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSignature is returned when a signature doesn't match the data and public key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Verifier defines a contract for checking signatures created by a [Signer].
+type Verifier interface {
+	Verify(data []byte, signature []byte) error
+}
+
+// RSAVerifier checks RSA PKCS1v15 signatures of SHA256 hashes, see [RSAKeyPair.Sign]
+type RSAVerifier struct {
+	Public *rsa.PublicKey
+}
+
+func (r *RSAVerifier) Verify(data []byte, signature []byte) error {
+	sum := sha256.Sum256(data)
+	if err := rsa.VerifyPKCS1v15(r.Public, crypto.SHA256, sum[:], signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ECCVerifier checks ASN.1 encoded ECDSA signatures of SHA256 hashes, see [ECCKeyPair.Sign]
+type ECCVerifier struct {
+	Public *ecdsa.PublicKey
+}
+
+func (e *ECCVerifier) Verify(data []byte, signature []byte) error {
+	sum := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(e.Public, sum[:], signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// DecodePublicKey returns a verifier for a PEM encoded public key, as encoded by [EncodePublicKey].
+func DecodePublicKey(p []byte) (Verifier, error) {
+	block, _ := pem.Decode(p)
+	if block == nil {
+		return nil, errors.New("no pem encoded public key found")
+	}
+
+	switch block.Type {
+	case "RSA_PUBLIC_KEY":
+		publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &RSAVerifier{Public: publicKey}, nil
+	case "PUBLIC_KEY":
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		eccPublicKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+		}
+		return &ECCVerifier{Public: eccPublicKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported pem block type %q", block.Type)
+	}
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope represents a permission granted to an API key
+type Scope string
+
+// Validate checks if the scope is supported
+func (s Scope) Validate() error {
+	isValid := slices.Contains([]Scope{
+		ScopeDeviceRead,
+		ScopeDeviceWrite,
+		ScopeDeviceSign,
+		ScopeAdmin,
+	}, s)
+	if !isValid {
+		return errors.New("scope invalid value")
+	}
+	return nil
+}
+
+// Supported scopes
+const (
+	ScopeDeviceRead  = Scope("device:read")  // Get and list devices
+	ScopeDeviceWrite = Scope("device:write") // Create, update and delete devices
+	ScopeDeviceSign  = Scope("device:sign")  // Sign data with devices
+	ScopeAdmin       = Scope("admin")        // Manage API keys and webhooks of the own organization, implies the device scopes
+)
+
+// ScopePlatform manages all organizations and acts within any of them. It is only held by the bootstrap key
+// and can't be granted to API keys, so tenants can't leave their organization.
+const ScopePlatform = Scope("platform")
+
+// APIKey represents a credential used by clients to authenticate against the API
+type APIKey struct {
+	Id             uuid.UUID           // Unique identifier for the key
+	OrganizationId uuid.UUID           // Organization the key grants access to
+	Name           string              // Human-readable name
+	Prefix         string              // First characters of the secret, to recognize the key without revealing it
+	Hash           string              // Hash of the secret, the secret itself is never stored
+	Scopes         []Scope             // Permissions granted to the key
+	CreatedAt      time.Time           // Key creation timestamp
+	RevokedAt      sql.Null[time.Time] // Time the key was revoked at
+}
+
+// Copy creates a deep copy of the key to prevent unintended mutations
+func (k *APIKey) Copy() *APIKey {
+	newKey := *k
+	newKey.Scopes = slices.Clone(k.Scopes)
+	return &newKey
+}
+
+// HasScope reports whether the key grants the scope, the admin scope grants all scopes but the platform scope
+func (k *APIKey) HasScope(scope Scope) bool {
+	if slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopePlatform) {
+		return true
+	}
+	return scope != ScopePlatform && slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// Revoked reports whether the key can no longer be used
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt.Valid
+}
+
+// APIKeyRepository defines the contract for API key storage operations
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+	List(ctx context.Context, organizationId uuid.UUID) ([]*APIKey, error)
+	Update(ctx context.Context, key *APIKey) error
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context carrying the API key the request was authenticated with
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key the request was authenticated with
+func APIKeyFromContext(ctx context.Context) (*APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key, ok
+}
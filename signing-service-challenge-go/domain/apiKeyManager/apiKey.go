@@ -0,0 +1,48 @@
+package apiKeyManager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+)
+
+// secretPrefix makes API keys recognizable, e.g. for secret scanners
+const secretPrefix = "sk_"
+
+type Handler struct {
+	storage persistence.Storage
+	// bootstrapHash is the hash of a static admin key configured at startup,
+	// so the first keys can be created without any key in storage
+	bootstrapHash string
+}
+
+type Option func(*Handler)
+
+// WithBootstrapKey registers a static admin key for the default organization.
+func WithBootstrapKey(secret string) Option {
+	return func(h *Handler) {
+		if secret != "" {
+			h.bootstrapHash = hashSecret(secret)
+		}
+	}
+}
+
+func New(
+	storage persistence.Storage,
+	options ...Option,
+) *Handler {
+	h := &Handler{
+		storage: storage,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+// hashSecret hashes an API key secret, secrets are random with high entropy so a plain SHA-256 suffices
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package apiKeyManager
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+)
+
+var errInvalidAPIKey = apiError.WithCode(apiError.New(http.StatusUnauthorized, "invalid api key"), apiError.CodeInvalidAPIKey)
+
+// Authenticate returns the key belonging to the secret, revoked and unknown keys are rejected.
+func (h *Handler) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	logger := domain.LoggerFromContext(ctx)
+	hash := hashSecret(secret)
+
+	if h.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(h.bootstrapHash)) == 1 {
+		return &domain.APIKey{
+			OrganizationId: domain.DefaultOrganizationId,
+			Name:           "bootstrap",
+			Scopes:         []domain.Scope{domain.ScopePlatform},
+		}, nil
+	}
+
+	key, err := h.storage.APIKeys().GetByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, persistence.ErrNotFound) {
+			return nil, errInvalidAPIKey
+		}
+		logger.Error("failed fetching api key", "error", err)
+		return nil, err
+	}
+
+	if key.Revoked() {
+		return nil, errInvalidAPIKey
+	}
+
+	return key, nil
+}
@@ -0,0 +1,71 @@
+package apiKeyManager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// displayedPrefixLength is the number of secret characters kept to recognize a key
+const displayedPrefixLength = len(secretPrefix) + 6
+
+type NewAPIKey struct {
+	OrganizationId uuid.UUID
+	Name           string
+	Scopes         []domain.Scope
+}
+
+// CreatedAPIKey carries the secret of a new key, which is only available on creation
+type CreatedAPIKey struct {
+	*domain.APIKey
+	Secret string
+}
+
+func (h *Handler) CreateAPIKey(ctx context.Context, in NewAPIKey) (*CreatedAPIKey, error) {
+	logger := domain.LoggerFromContext(ctx)
+	apiKeyRepository := h.storage.APIKeys()
+
+	// make sure the key isn't created for an organization which doesn't exist
+	if _, err := h.storage.Organizations().GetByID(ctx, in.OrganizationId); err != nil {
+		logger.Error("failed fetching organization", "error", err)
+		return nil, apiError.WithCode(apiError.New(http.StatusNotFound, "organization not found"), apiError.CodeOrganizationNotFound)
+	}
+
+	keyId, err := uuid.NewRandom()
+	if err != nil {
+		logger.Error("uuid generation failed", "error", err)
+		return nil, err
+	}
+
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		logger.Error("secret generation failed", "error", err)
+		return nil, err
+	}
+	secret := secretPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+
+	newKey := &domain.APIKey{
+		Id:             keyId,
+		OrganizationId: in.OrganizationId,
+		Name:           in.Name,
+		Prefix:         secret[:displayedPrefixLength],
+		Hash:           hashSecret(secret),
+		Scopes:         in.Scopes,
+		CreatedAt:      time.Now(),
+	}
+	if err := apiKeyRepository.Create(ctx, newKey); err != nil {
+		logger.Error("creating api key failed", "error", err)
+		return nil, err
+	}
+
+	return &CreatedAPIKey{
+		APIKey: newKey,
+		Secret: secret,
+	}, nil
+}
@@ -0,0 +1,21 @@
+package apiKeyManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ListAPIKeys(ctx context.Context, organizationId uuid.UUID) ([]*domain.APIKey, error) {
+	logger := domain.LoggerFromContext(ctx)
+	apiKeyRepository := h.storage.APIKeys()
+
+	keys, err := apiKeyRepository.List(ctx, organizationId)
+	if err != nil {
+		logger.Error("failed fetching api keys", "error", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
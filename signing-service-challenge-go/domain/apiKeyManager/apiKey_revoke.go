@@ -0,0 +1,39 @@
+package apiKeyManager
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// RevokeAPIKey permanently disables a key of the organization, revoking a revoked key is a no-op.
+func (h *Handler) RevokeAPIKey(ctx context.Context, organizationId uuid.UUID, keyId uuid.UUID) error {
+	logger := domain.LoggerFromContext(ctx)
+	apiKeyRepository := h.storage.APIKeys()
+
+	key, err := apiKeyRepository.GetByID(ctx, keyId)
+	if err != nil || key.OrganizationId != organizationId {
+		logger.Error("failed fetching api key", "error", err)
+		return apiError.WithCode(apiError.New(http.StatusNotFound, "api key not found"), apiError.CodeAPIKeyNotFound)
+	}
+
+	if key.Revoked() {
+		return nil
+	}
+
+	key.RevokedAt = sql.Null[time.Time]{
+		V:     time.Now(),
+		Valid: true,
+	}
+	if err := apiKeyRepository.Update(ctx, key); err != nil {
+		logger.Error("failed updating api key", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+)
+
+// ClientCertificate identifies a client authenticated with a verified TLS client certificate
+type ClientCertificate struct {
+	Fingerprint string // SHA-256 fingerprint of the DER encoded certificate, lowercase hex
+	Subject     string // Distinguished name of the certificate subject
+}
+
+// NewClientCertificate derives the client identity of a certificate
+func NewClientCertificate(certificate *x509.Certificate) ClientCertificate {
+	sum := sha256.Sum256(certificate.Raw)
+	return ClientCertificate{
+		Fingerprint: hex.EncodeToString(sum[:]),
+		Subject:     certificate.Subject.String(),
+	}
+}
+
+// NormalizeFingerprint accepts SHA-256 fingerprints in hex, optionally separated by colons
+// like printed by openssl, and returns them in the form used by ClientCertificate
+func NormalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+	decoded, err := hex.DecodeString(normalized)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", errors.New("certificate fingerprint must be a hex encoded sha-256 hash")
+	}
+	return normalized, nil
+}
+
+type clientCertificateContextKey struct{}
+
+// WithClientCertificate returns a context carrying the client certificate the request was authenticated with
+func WithClientCertificate(ctx context.Context, certificate ClientCertificate) context.Context {
+	return context.WithValue(ctx, clientCertificateContextKey{}, certificate)
+}
+
+// ClientCertificateFromContext returns the client certificate the request was authenticated with
+func ClientCertificateFromContext(ctx context.Context) (ClientCertificate, bool) {
+	certificate, ok := ctx.Value(clientCertificateContextKey{}).(ClientCertificate)
+	return certificate, ok
+}
+
+// ClientIdentity returns a stable identifier of the authenticated client of the context,
+// preferring the API key over the client certificate. It is empty for anonymous clients.
+func ClientIdentity(ctx context.Context) string {
+	if key, ok := APIKeyFromContext(ctx); ok {
+		return "api-key:" + key.Id.String()
+	}
+	if certificate, ok := ClientCertificateFromContext(ctx); ok {
+		return "certificate:" + certificate.Fingerprint
+	}
+	return ""
+}
+
+// NormalizeFingerprints normalizes all fingerprints and turns them into a sorted set without duplicates.
+// The error of an invalid fingerprint points to its index, see [validation.FieldError].
+func NormalizeFingerprints(fingerprints []string) ([]string, error) {
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(fingerprints))
+	for i, fingerprint := range fingerprints {
+		n, err := NormalizeFingerprint(fingerprint)
+		if err != nil {
+			return nil, validation.FieldError{Pointer: validation.Pointer(i), Message: err.Error()}
+		}
+		normalized = append(normalized, n)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
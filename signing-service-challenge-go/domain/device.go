@@ -6,6 +6,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"maps"
 	"slices"
 	"time"
 
@@ -35,17 +36,59 @@ const (
 	SigningAlgorithmRsa = SigningAlgorithm("RSA") // RSA algorithm
 )
 
+// DeviceStatus represents the lifecycle state of a device
+type DeviceStatus string
+
+// Validate checks if the device status is supported
+func (s DeviceStatus) Validate() error {
+	isValid := slices.Contains([]DeviceStatus{
+		DeviceStatusActive,
+		DeviceStatusDisabled,
+	}, s)
+	if !isValid {
+		return errors.New("device status invalid value")
+	}
+	return nil
+}
+
+// Supported device statuses
+const (
+	DeviceStatusActive   = DeviceStatus("active")   // Device can be used for signing
+	DeviceStatusDisabled = DeviceStatus("disabled") // Device rejects signing requests
+)
+
+// ValidateDeviceId checks a device id chosen by the client.
+// The nil uuid is reserved, e.g. the health check of the device lock acquires it.
+func ValidateDeviceId(id string) error {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return err
+	}
+	if parsed == uuid.Nil {
+		return errors.New("device id must not be the nil uuid")
+	}
+	return nil
+}
+
 // Device represents a cryptographic signing device with its associated keys and metadata
 type Device struct {
-	Id               uuid.UUID         // Unique identifier for the device
-	Label            sql.Null[string]  // Optional human-readable label
-	SigningAlgorithm SigningAlgorithm  // Cryptographic algorithm used for signing
-	PrivateKey       string            // Private key in PEM format
-	PublicKeys       []string          // Public keys in PEM format
-	SignatureCounter int               // Number of signatures created with this device
-	LastSignature    sql.Null[string]  // Most recent signature created
-	CreatedAt        time.Time         // Device creation timestamp
-	UpdatedAt        time.Time         // Last modification timestamp
+	Id                    uuid.UUID         // Unique identifier for the device
+	OrganizationId        uuid.UUID         // Organization owning the device
+	Label                 sql.Null[string]  // Optional human-readable label
+	Status                DeviceStatus      // Lifecycle state of the device
+	Metadata              map[string]string // Arbitrary key/value pairs attached by the client
+	Tags                  []string          // Sorted set of tags attached by the client
+	ClientCertificates    []string          // Fingerprints of client certificates allowed to sign, unrestricted if empty
+	SigningAlgorithm      SigningAlgorithm  // Cryptographic algorithm used for signing
+	PrivateKey            string            // Private key in PEM format
+	PublicKeys            []string          // Public keys in PEM format
+	SignatureCounter      int               // Number of signatures created with this device
+	LastSignature         sql.Null[string]  // Most recent signature created
+	MonthlySignatureQuota sql.Null[int]     // Maximum number of signatures per calendar month, unlimited if not set
+	QuotaPeriod           string            // Calendar month QuotaUsage was counted in, see QuotaPeriod
+	QuotaUsage            int               // Number of signatures created in QuotaPeriod
+	CreatedAt             time.Time         // Device creation timestamp
+	UpdatedAt             time.Time         // Last modification timestamp
 }
 
 // Copy creates a deep copy of the device to prevent unintended mutations
@@ -57,23 +100,35 @@ func (d *Device) Copy() *Device {
 
 	// manual clone of uuid to ensure complete independence
 	newDevice.Id = uuid.UUID(slices.Clone(d.Id[:]))
+	newDevice.OrganizationId = uuid.UUID(slices.Clone(d.OrganizationId[:]))
 	newDevice.PublicKeys = slices.Clone(d.PublicKeys)
+	newDevice.Metadata = maps.Clone(d.Metadata)
+	newDevice.Tags = slices.Clone(d.Tags)
+	newDevice.ClientCertificates = slices.Clone(d.ClientCertificates)
 	return newDevice
 }
 
 // DeviceFilter defines filtering criteria for device queries
+// Queries are always scoped to the organization carried in the context, OrganizationId can only narrow it down further.
 type DeviceFilter struct {
-	IDs    []uuid.UUID // Filter by specific device IDs
-	Limit  int         // Maximum number of results to return
-	Offset int         // Number of results to skip for pagination
+	OrganizationId   uuid.NullUUID     // Filter by owning organization
+	IDs              []uuid.UUID       // Filter by specific device IDs
+	SigningAlgorithm SigningAlgorithm  // Filter by signing algorithm
+	Tags             []string          // Filter by devices carrying all of the tags
+	Metadata         map[string]string // Filter by devices with all of the metadata entries
+	Limit            int               // Maximum number of results to return
+	Offset           int               // Number of results to skip for pagination
 }
 
 // DeviceRepository defines the contract for device storage operations
+// All operations only see devices of the organization carried in the context, see WithOrganization
 type DeviceRepository interface {
 	Create(ctx context.Context, device *Device) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Device, error)
 	List(ctx context.Context, filter DeviceFilter) ([]*Device, error)
-	Update(ctx context.Context, device *Device) error
+	// Update rejects the write with a fencing token lower than the highest one seen for the device, see [lock.Lock].
+	// A token of 0 isn't fenced, e.g. for writes without a lock.
+	Update(ctx context.Context, device *Device, fencingToken uint64) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Count(ctx context.Context, filter DeviceFilter) (int64, error)
 }
@@ -1,17 +1,140 @@
 package deviceManager
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultIdempotencyTTL is the time a signing result is kept for retries with the same idempotency key
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// DefaultIdempotencySweepInterval is how often expired idempotency records are removed
+const DefaultIdempotencySweepInterval = time.Minute
+
+var (
+	errDeviceNotFound = apiError.WithCode(apiError.New(http.StatusNotFound, "device not found"), apiError.CodeDeviceNotFound)
+	errDeviceExists   = apiError.WithCode(apiError.New(http.StatusConflict, "device with this uuid already exists"), apiError.CodeDeviceConflict)
+	// errLockExpired is returned when the device lock expired before the write, another writer may hold it by now
+	errLockExpired = apiError.WithCode(apiError.New(http.StatusConflict, "device lock expired, please retry"), apiError.CodeDeviceLockExpired)
 )
 
+const instrumentationName = "github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+
+// startSpan starts a span of the global tracer provider, which doesn't record anything unless tracing is set up
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan records the error of the operation on the span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// updateDevice writes the device fenced by the token of the device lock held for the operation, see [lock.Lock]
+func updateDevice(ctx context.Context, devices domain.DeviceRepository, device *domain.Device, fencingToken uint64) error {
+	err := devices.Update(ctx, device, fencingToken)
+	if errors.Is(err, persistence.ErrStaleToken) {
+		return errLockExpired
+	}
+	return err
+}
+
+// withDeviceLogger adds the device to the logger of the context, so all log lines of an operation name the device
+func withDeviceLogger(ctx context.Context, deviceId uuid.UUID) (context.Context, *slog.Logger) {
+	ctx = domain.WithLogAttrs(ctx, "device_id", deviceId)
+	return ctx, domain.LoggerFromContext(ctx)
+}
+
 type Handler struct {
-	storage persistence.Storage
+	storage        persistence.Storage
+	idempotencyTTL time.Duration
+	publisher      domain.EventPublisher
+	metrics        Metrics
+	policy         CryptoPolicy
+}
+
+// CryptoPolicy restricts the keys of new devices
+type CryptoPolicy struct {
+	// Algorithms new devices may be created with, all algorithms are allowed if empty
+	Algorithms []domain.SigningAlgorithm
+	// RSAKeySize is the size of generated RSA keys in bits, [crypto.DefaultRSAKeySize] if zero
+	RSAKeySize int
+}
+
+// allows tells whether new devices may use the algorithm, existing devices keep working and rotating their keys
+func (p CryptoPolicy) allows(algorithm domain.SigningAlgorithm) bool {
+	return len(p.Algorithms) == 0 || slices.Contains(p.Algorithms, algorithm)
+}
+
+// Metrics receives the durations of the cryptographic operations, which dominate the latency of the service
+type Metrics interface {
+	ObserveSigning(algorithm domain.SigningAlgorithm, duration time.Duration)
+	ObserveKeyGeneration(algorithm domain.SigningAlgorithm, duration time.Duration)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) ObserveSigning(domain.SigningAlgorithm, time.Duration)       {}
+func (noMetrics) ObserveKeyGeneration(domain.SigningAlgorithm, time.Duration) {}
+
+type Option func(*Handler)
+
+// WithIdempotencyTTL sets how long signing results are kept for retries with the same idempotency key.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(h *Handler) {
+		h.idempotencyTTL = ttl
+	}
+}
+
+// WithCryptoPolicy restricts the algorithms and key sizes of new devices.
+func WithCryptoPolicy(policy CryptoPolicy) Option {
+	return func(h *Handler) {
+		h.policy = policy
+	}
+}
+
+// WithEventPublisher publishes the events of all device changes after they were committed.
+func WithEventPublisher(publisher domain.EventPublisher) Option {
+	return func(h *Handler) {
+		h.publisher = publisher
+	}
+}
+
+// WithMetrics reports the durations of signing and key generation.
+func WithMetrics(metrics Metrics) Option {
+	return func(h *Handler) {
+		h.metrics = metrics
+	}
 }
 
 func New(
 	storage persistence.Storage,
+	options ...Option,
 ) *Handler {
-	return &Handler{
-		storage: storage,
+	h := &Handler{
+		storage:        storage,
+		idempotencyTTL: DefaultIdempotencyTTL,
+		metrics:        noMetrics{},
+	}
+	for _, option := range options {
+		option(h)
 	}
+	return h
 }
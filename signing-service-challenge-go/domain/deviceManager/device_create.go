@@ -1,34 +1,47 @@
 package deviceManager
 
 import (
+	"cmp"
 	"context"
 	"errors"
-	"log/slog"
-	"net/http"
+	"fmt"
+	"time"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/crypto"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type NewDevice struct {
-	Id               null.Null[string]
-	Label            null.Null[string]
-	SigningAlgorithm domain.SigningAlgorithm
+	Id                    null.Null[string]
+	Label                 null.Null[string]
+	Metadata              map[string]string
+	Tags                  []string
+	ClientCertificates    []string
+	MonthlySignatureQuota null.Null[int]
+	SigningAlgorithm      domain.SigningAlgorithm
 }
 
 func (h *Handler) CreateDevice(ctx context.Context, in NewDevice) (*domain.Device, error) {
+	logger := domain.LoggerFromContext(ctx)
 	deviceRepository := h.storage.Devices()
 
+	if !h.policy.allows(in.SigningAlgorithm) {
+		return nil, apiError.Validation(validation.Field("/signing_algorithm", fmt.Errorf("signing algorithm %s is not allowed", in.SigningAlgorithm)))
+	}
+
 	newDevice := &domain.Device{}
 	newDevice.SigningAlgorithm = in.SigningAlgorithm
 
 	if value, filled := in.Id.Value(); filled {
 		uuidFromString, err := uuid.Parse(value)
 		if err != nil {
-			slog.Error("invalid uuid", "error", err)
+			logger.Error("invalid uuid", "error", err)
 			return nil, err
 		}
 		count, err := deviceRepository.Count(ctx, domain.DeviceFilter{
@@ -36,57 +49,99 @@ func (h *Handler) CreateDevice(ctx context.Context, in NewDevice) (*domain.Devic
 			Limit: 1,
 		})
 		if err != nil {
-			slog.Error("failed fetching device count", "error", err)
+			logger.Error("failed fetching device count", "error", err)
 			return nil, err
 		}
 		if count > 0 {
-			return nil, apiError.New(http.StatusConflict, "device with this uuid already exists")
+			return nil, errDeviceExists
 		}
 		newDevice.Id = uuidFromString
 	} else {
 		randomUuid, err := uuid.NewRandom()
 		if err != nil {
-			slog.Error("uuid generation failed", "error", err)
+			logger.Error("uuid generation failed", "error", err)
 			return nil, err
 		}
 		newDevice.Id = randomUuid
 	}
+	ctx, logger = withDeviceLogger(ctx, newDevice.Id)
+
+	publicKeyBytes, privateKeyBytes, err := h.generateKeyPair(ctx, newDevice.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	newDevice.Label = in.Label.SqlNull()
+	newDevice.Status = domain.DeviceStatusActive
+	newDevice.Metadata = in.Metadata
+	newDevice.Tags = domain.NormalizeTags(in.Tags)
+	newDevice.MonthlySignatureQuota = in.MonthlySignatureQuota.SqlNull()
+	newDevice.ClientCertificates, err = domain.NormalizeFingerprints(in.ClientCertificates)
+	if err != nil {
+		return nil, apiError.Validation(validation.Field("/client_certificates", err))
+	}
+	newDevice.PrivateKey = string(privateKeyBytes)
+	newDevice.PublicKeys = []string{string(publicKeyBytes)}
+
+	var event *domain.Event
+	err = h.storage.WithTransaction(ctx, func(ctx context.Context, storage persistence.Storage) error {
+		if err := storage.Devices().Create(ctx, newDevice); err != nil {
+			if errors.Is(err, persistence.ErrAlreadyExists) {
+				return errDeviceExists
+			}
+			logger.Error("creating device failed", "error", err)
+			return err
+		}
+		event, err = emitEvent(ctx, storage, newDevice, domain.EventDeviceCreated, domain.DeviceEventData{
+			DeviceId:         newDevice.Id,
+			SigningAlgorithm: newDevice.SigningAlgorithm,
+			Label:            newDevice.Label.V,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.publish(event)
 
-	var (
-		keyPair crypto.KeyPair
-		err     error
-	)
-	switch newDevice.SigningAlgorithm {
+	return newDevice, nil
+}
+
+// generateKeyPair creates a new encoded key pair for the signing algorithm
+func (h *Handler) generateKeyPair(ctx context.Context, algorithm domain.SigningAlgorithm) (publicKey []byte, privateKey []byte, err error) {
+	logger := domain.LoggerFromContext(ctx)
+	_, span := startSpan(ctx, "crypto.GenerateKeyPair", attribute.String("device.signing_algorithm", string(algorithm)))
+	started := time.Now()
+	defer func() {
+		endSpan(span, err)
+		if err == nil {
+			h.metrics.ObserveKeyGeneration(algorithm, time.Since(started))
+		}
+	}()
+
+	var keyPair crypto.KeyPair
+	switch algorithm {
 	case domain.SigningAlgorithmRsa:
-		keyPair, err = crypto.GenerateRSAKeyPair()
+		keyPair, err = crypto.GenerateRSAKeyPairOfSize(cmp.Or(h.policy.RSAKeySize, crypto.DefaultRSAKeySize))
 		if err != nil {
-			slog.Error("rsa key pair generation", "error", err)
-			return nil, err
+			logger.Error("rsa key pair generation", "error", err)
+			return nil, nil, err
 		}
 	case domain.SigningAlgorithmEcc:
 		keyPair, err = crypto.GenerateECCKeyPair()
 		if err != nil {
-			slog.Error("ecc key pair generation", "error", err)
-			return nil, err
+			logger.Error("ecc key pair generation", "error", err)
+			return nil, nil, err
 		}
 	default:
-		slog.Error("invalid signing algorithm")
-		return nil, errors.New("invalid signing algorithm")
+		logger.Error("invalid signing algorithm")
+		return nil, nil, errors.New("invalid signing algorithm")
 	}
 
-	publicKeyBytes, privateKeyBytes, err := crypto.EncodeKeyPair(keyPair)
+	publicKey, privateKey, err = crypto.EncodeKeyPair(keyPair)
 	if err != nil {
-		slog.Error("encode key pair", "error", err)
-		return nil, err
+		logger.Error("encode key pair", "error", err)
+		return nil, nil, err
 	}
-
-	newDevice.Label = in.Label.SqlNull()
-	newDevice.PrivateKey = string(privateKeyBytes)
-	newDevice.PublicKeys = []string{string(publicKeyBytes)}
-
-	if err := deviceRepository.Create(ctx, newDevice); err != nil {
-		slog.Error("creating device failed", "error", err)
-		return nil, err
-	}
-	return newDevice, nil
+	return publicKey, privateKey, nil
 }
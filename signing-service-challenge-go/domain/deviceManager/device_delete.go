@@ -3,22 +3,56 @@ package deviceManager
 import (
 	"context"
 	"errors"
-	"log/slog"
 
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
 	"github.com/google/uuid"
 )
 
 func (h *Handler) DeleteDevice(ctx context.Context, deviceId uuid.UUID) error {
-	deviceRepository := h.storage.Devices()
+	ctx, logger := withDeviceLogger(ctx, deviceId)
+	var event *domain.Event
+	err := h.storage.WithTransaction(ctx, func(ctx context.Context, storage persistence.Storage) error {
+		deviceRepository := storage.Devices()
 
-	if err := deviceRepository.Delete(ctx, deviceId); err != nil {
+		// the device is fetched first, so the event can describe what was deleted
+		device, err := deviceRepository.GetByID(ctx, deviceId)
 		if errors.Is(err, persistence.ErrNotFound) {
 			return nil
 		}
-		slog.Error("deleting device failed", "error", err)
+		if err != nil {
+			logger.Error("failed fetching device", "error", err)
+			return err
+		}
+
+		if err := deviceRepository.Delete(ctx, deviceId); err != nil {
+			if errors.Is(err, persistence.ErrNotFound) {
+				return nil
+			}
+			logger.Error("deleting device failed", "error", err)
+			return err
+		}
+		// device ids are chosen by clients, a device recreated with the id must not continue the old log
+		if err := storage.Signatures().DeleteByDevice(ctx, deviceId); err != nil {
+			logger.Error("deleting signatures failed", "error", err)
+			return err
+		}
+		if err := storage.Idempotency().DeleteByDevice(ctx, deviceId); err != nil {
+			logger.Error("deleting idempotency records failed", "error", err)
+			return err
+		}
+
+		event, err = emitEvent(ctx, storage, device, domain.EventDeviceDeleted, domain.DeviceEventData{
+			DeviceId:         device.Id,
+			SigningAlgorithm: device.SigningAlgorithm,
+			Label:            device.Label.V,
+		})
+		return err
+	})
+	if err != nil {
 		return err
 	}
+	h.publish(event)
 
 	return nil
 }
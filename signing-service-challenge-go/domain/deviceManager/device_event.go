@@ -0,0 +1,35 @@
+package deviceManager
+
+import (
+	"context"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+)
+
+// emitEvent appends the event to the outbox, from where it is delivered to sinks like webhooks.
+// It has to be called within the transaction changing the device, so an event is stored if and only if the change is.
+// The returned event has to be passed to publish once the transaction is committed.
+func emitEvent(ctx context.Context, storage persistence.Storage, device *domain.Device, eventType domain.EventType, data any) (*domain.Event, error) {
+	logger := domain.LoggerFromContext(ctx)
+	event, err := domain.NewEvent(device, eventType, data, time.Now())
+	if err != nil {
+		logger.Error("creating event failed", "error", err)
+		return nil, err
+	}
+
+	if err := storage.Outbox().Append(ctx, event); err != nil {
+		logger.Error("appending event to outbox failed", "error", err)
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// publish notifies in-process subscribers about a committed event, a nil event is ignored
+func (h *Handler) publish(event *domain.Event) {
+	if h.publisher != nil && event != nil {
+		h.publisher.Publish(event)
+	}
+}
@@ -2,17 +2,17 @@ package deviceManager
 
 import (
 	"context"
-	"log/slog"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
 )
 
-func (h *Handler) ListDevices(ctx context.Context) ([]*domain.Device, error) {
+func (h *Handler) ListDevices(ctx context.Context, filter domain.DeviceFilter) ([]*domain.Device, error) {
+	logger := domain.LoggerFromContext(ctx)
 	deviceRepository := h.storage.Devices()
 
-	devices, err := deviceRepository.List(ctx, domain.DeviceFilter{})
+	devices, err := deviceRepository.List(ctx, filter)
 	if err != nil {
-		slog.Error("failed fetching devices", "error", err)
+		logger.Error("failed fetching devices", "error", err)
 		return nil, err
 	}
 
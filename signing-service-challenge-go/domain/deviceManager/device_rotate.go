@@ -0,0 +1,48 @@
+package deviceManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+)
+
+// RotateKey replaces the signing key of the device with a new one of the same algorithm.
+// Previous public keys are kept, so signatures created before the rotation can still be verified.
+// The fencing token of the held device lock rejects the write if the lock expired meanwhile.
+func (h *Handler) RotateKey(ctx context.Context, deviceId uuid.UUID, fencingToken uint64) (*domain.Device, error) {
+	ctx, logger := withDeviceLogger(ctx, deviceId)
+	device, err := h.storage.Devices().GetByID(ctx, deviceId)
+	if err != nil {
+		logger.Error("failed fetching device", "error", err)
+		return nil, errDeviceNotFound
+	}
+
+	publicKeyBytes, privateKeyBytes, err := h.generateKeyPair(ctx, device.SigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	device.PrivateKey = string(privateKeyBytes)
+	device.PublicKeys = append(device.PublicKeys, string(publicKeyBytes))
+
+	var event *domain.Event
+	err = h.storage.WithTransaction(ctx, func(ctx context.Context, storage persistence.Storage) error {
+		if err := updateDevice(ctx, storage.Devices(), device, fencingToken); err != nil {
+			logger.Error("failed updating device", "error", err)
+			return err
+		}
+		event, err = emitEvent(ctx, storage, device, domain.EventKeyRotated, domain.KeyRotatedEventData{
+			DeviceId:   device.Id,
+			PublicKey:  string(publicKeyBytes),
+			KeyVersion: len(device.PublicKeys),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.publish(event)
+
+	return device, nil
+}
@@ -2,16 +2,25 @@ package deviceManager
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/crypto"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type SignedData struct {
@@ -19,16 +28,172 @@ type SignedData struct {
 	SignatureCounter int
 	Data             string
 	LastSignature    string
+	// Replayed is set when the result was stored by an earlier request with the same idempotency key
+	Replayed bool
 }
 
-func (h *Handler) SignData(ctx context.Context, deviceId uuid.UUID, data string) (*SignedData, error) {
-	deviceRepository := h.storage.Devices()
+// SecuredData returns the signed data in the format returned to clients, see [domain.FormatSignedData]
+func (s *SignedData) SecuredData() string {
+	return domain.FormatSignedData(s.SignatureCounter, s.LastSignature, s.Data)
+}
+
+// SignData signs data with the device and increments its signature counter.
+// When an idempotency key is given, a retry of the same request returns the original result.
+// The fencing token of the held device lock rejects the write if the lock expired meanwhile.
+func (h *Handler) SignData(ctx context.Context, deviceId uuid.UUID, data string, idempotencyKey null.Null[string], fencingToken uint64) (_ *SignedData, err error) {
+	ctx, span := startSpan(ctx, "deviceManager.SignData", attribute.String("device.id", deviceId.String()))
+	defer func() { endSpan(span, err) }()
+	ctx, logger := withDeviceLogger(ctx, deviceId)
+
+	requestHash := hashSignRequest(data)
+
+	// the device is fetched first, so idempotency records are only visible within the device's organization
+	device, err := h.storage.Devices().GetByID(ctx, deviceId)
+	if err != nil {
+		logger.Error("failed fetching device", "error", err)
+		return nil, errDeviceNotFound
+	}
+
+	if device.Status == domain.DeviceStatusDisabled {
+		return nil, apiError.WithCode(apiError.New(http.StatusConflict, "device is disabled"), apiError.CodeDeviceDisabled)
+	}
+
+	// devices bound to client certificates can only be used by those clients
+	if len(device.ClientCertificates) > 0 {
+		certificate, ok := domain.ClientCertificateFromContext(ctx)
+		if !ok || !slices.Contains(device.ClientCertificates, certificate.Fingerprint) {
+			return nil, apiError.WithCode(
+				apiError.New(http.StatusForbidden, "client certificate is not allowed to sign with the device"),
+				apiError.CodeCertificateNotAllowed,
+			)
+		}
+	}
+
+	if key, filled := idempotencyKey.Value(); filled {
+		record, err := h.storage.Idempotency().Get(ctx, deviceId, key)
+		switch {
+		case err == nil:
+			if record.RequestHash != requestHash {
+				return nil, apiError.WithCode(
+					apiError.New(http.StatusUnprocessableEntity, "idempotency key was already used for a different request"),
+					apiError.CodeIdempotencyKeyReused,
+				)
+			}
+			return &SignedData{
+				Signature:        record.Signature,
+				SignatureCounter: record.SignatureCounter,
+				Data:             record.Data,
+				LastSignature:    record.LastSignature,
+				Replayed:         true,
+			}, nil
+		case !errors.Is(err, persistence.ErrNotFound):
+			logger.Error("failed fetching idempotency record", "error", err)
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if device.QuotaExceeded(now) {
+		retryAfter := domain.NextQuotaPeriod(now).Sub(now)
+		return nil, apiError.WithHeader(
+			apiError.WithCode(
+				apiError.New(http.StatusTooManyRequests, "monthly signature quota of the device exceeded"),
+				apiError.CodeQuotaExceeded,
+			),
+			"Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))),
+		)
+	}
+
+	started := time.Now()
+	signedData, err := signData(ctx, device, data)
+	if err != nil {
+		return nil, err
+	}
+	h.metrics.ObserveSigning(device.SigningAlgorithm, time.Since(started))
+	device.CountQuotaUsage(now)
 
-	device, err := deviceRepository.GetByID(ctx, deviceId)
+	// the signature counter and the idempotency record have to be stored together,
+	// otherwise a retry could sign a second time although the first attempt succeeded
+	var event *domain.Event
+	err = h.storage.WithTransaction(ctx, func(ctx context.Context, storage persistence.Storage) error {
+		if err := updateDevice(ctx, storage.Devices(), device, fencingToken); err != nil {
+			logger.Error("failed updating device", "error", err)
+			return err
+		}
+
+		if err := storage.Signatures().Create(ctx, &domain.Signature{
+			DeviceId:      device.Id,
+			Counter:       signedData.SignatureCounter,
+			Signature:     signedData.Signature,
+			Data:          signedData.Data,
+			LastSignature: signedData.LastSignature,
+			KeyVersion:    len(device.PublicKeys),
+			CreatedAt:     now,
+		}); err != nil {
+			logger.Error("failed saving signature", "error", err)
+			return err
+		}
+
+		event, err = emitEvent(ctx, storage, device, domain.EventSignatureCreated, domain.SignatureCreatedEventData{
+			DeviceId:         device.Id,
+			SignatureCounter: signedData.SignatureCounter,
+			Signature:        signedData.Signature,
+		})
+		if err != nil {
+			return err
+		}
+
+		key, filled := idempotencyKey.Value()
+		if !filled {
+			return nil
+		}
+		if err := storage.Idempotency().Save(ctx, &domain.IdempotencyRecord{
+			DeviceId:         deviceId,
+			Key:              key,
+			RequestHash:      requestHash,
+			Signature:        signedData.Signature,
+			SignatureCounter: signedData.SignatureCounter,
+			Data:             signedData.Data,
+			LastSignature:    signedData.LastSignature,
+			CreatedAt:        now,
+			ExpiresAt:        now.Add(h.idempotencyTTL),
+		}); err != nil {
+			logger.Error("failed saving idempotency record", "error", err)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		slog.Error("failed fetching device", "error", err)
-		return nil, apiError.New(http.StatusNotFound, "device not found")
+		return nil, err
+	}
+	h.publish(event)
+
+	return signedData, nil
+}
+
+// SweepIdempotency removes expired idempotency records every interval until the context is done.
+// Expired records are never replayed, sweeping only frees their storage.
+func (h *Handler) SweepIdempotency(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := h.storage.Idempotency().DeleteExpired(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Error("deleting expired idempotency records failed", "error", err)
+		}
 	}
+}
+
+// signData creates the signature and advances the signature counter of the device, which still has to be stored.
+func signData(ctx context.Context, device *domain.Device, data string) (*SignedData, error) {
+	logger := domain.LoggerFromContext(ctx)
+	algorithm := attribute.String("device.signing_algorithm", string(device.SigningAlgorithm))
 
 	var keyPair crypto.KeyPair
 	switch device.SigningAlgorithm {
@@ -37,25 +202,30 @@ func (h *Handler) SignData(ctx context.Context, deviceId uuid.UUID, data string)
 	case domain.SigningAlgorithmEcc:
 		keyPair = new(crypto.ECCKeyPair)
 	default:
-		slog.Error("unknown signing algorithm")
+		logger.Error("unknown signing algorithm")
 		return nil, errors.New("unknown signing algorithm")
 	}
 
-	if err := crypto.DecodePrivateKey([]byte(device.PrivateKey), keyPair); err != nil {
-		slog.Error("decode private key", "error", err)
+	_, span := startSpan(ctx, "crypto.DecodePrivateKey", algorithm)
+	err := crypto.DecodePrivateKey([]byte(device.PrivateKey), keyPair)
+	endSpan(span, err)
+	if err != nil {
+		logger.Error("decode private key", "error", err)
 		return nil, err
 	}
 
+	_, span = startSpan(ctx, "crypto.Sign", algorithm)
 	signature, err := keyPair.Sign([]byte(data))
+	endSpan(span, err)
 	if err != nil {
-		slog.Error("signing failed", "error", err)
+		logger.Error("signing failed", "error", err)
 		return nil, err
 	}
 	base64Signature := base64.StdEncoding.EncodeToString(signature)
 
 	device.SignatureCounter++
 
-	lastSignature := base64.StdEncoding.EncodeToString(deviceId[:])
+	lastSignature := base64.StdEncoding.EncodeToString(device.Id[:])
 	if device.LastSignature.Valid {
 		lastSignature = device.LastSignature.V
 	}
@@ -65,11 +235,6 @@ func (h *Handler) SignData(ctx context.Context, deviceId uuid.UUID, data string)
 		Valid: true,
 	}
 
-	if err := deviceRepository.Update(ctx, device); err != nil {
-		slog.Error("failed updating device", "error", err)
-		return nil, err
-	}
-
 	return &SignedData{
 		Signature:        base64Signature,
 		SignatureCounter: device.SignatureCounter,
@@ -77,3 +242,9 @@ func (h *Handler) SignData(ctx context.Context, deviceId uuid.UUID, data string)
 		LastSignature:    lastSignature,
 	}, nil
 }
+
+// hashSignRequest fingerprints a signing request to detect idempotency keys reused for different data
+func hashSignRequest(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
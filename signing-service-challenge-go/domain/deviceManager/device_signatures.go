@@ -0,0 +1,25 @@
+package deviceManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// ListSignatures returns the signature log of the device after the given counter, oldest first.
+func (h *Handler) ListSignatures(ctx context.Context, deviceId uuid.UUID, afterCounter int, limit int) ([]*domain.Signature, error) {
+	// the signature log isn't scoped, the device lookup makes sure it belongs to the organization
+	if _, err := h.GetDevice(ctx, deviceId); err != nil {
+		return nil, err
+	}
+	ctx, logger := withDeviceLogger(ctx, deviceId)
+
+	signatures, err := h.storage.Signatures().List(ctx, deviceId, afterCounter, limit)
+	if err != nil {
+		logger.Error("failed fetching signatures", "error", err)
+		return nil, err
+	}
+
+	return signatures, nil
+}
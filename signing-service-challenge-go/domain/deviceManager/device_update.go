@@ -0,0 +1,90 @@
+package deviceManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+	"github.com/google/uuid"
+)
+
+// DevicePatch describes a JSON Merge Patch (RFC 7396) of the mutable device attributes.
+// Fields which are not present are left untouched, fields present with null are cleared.
+type DevicePatch struct {
+	Label                 null.Patch[string]
+	Metadata              null.Patch[map[string]null.Null[string]]
+	Tags                  null.Patch[[]string]
+	Status                null.Patch[domain.DeviceStatus]
+	ClientCertificates    null.Patch[[]string]
+	MonthlySignatureQuota null.Patch[int]
+}
+
+func (h *Handler) UpdateDevice(ctx context.Context, deviceId uuid.UUID, patch DevicePatch, fencingToken uint64) (*domain.Device, error) {
+	ctx, logger := withDeviceLogger(ctx, deviceId)
+	deviceRepository := h.storage.Devices()
+
+	device, err := deviceRepository.GetByID(ctx, deviceId)
+	if err != nil {
+		logger.Error("failed fetching device", "error", err)
+		return nil, errDeviceNotFound
+	}
+
+	if patch.Label.Present() {
+		device.Label = patch.Label.SqlNull()
+	}
+
+	if patch.Metadata.Present() {
+		if changes, filled := patch.Metadata.Value(); filled {
+			if device.Metadata == nil {
+				device.Metadata = make(map[string]string, len(changes))
+			}
+			for key, value := range changes {
+				if v, filled := value.Value(); filled {
+					device.Metadata[key] = v
+				} else {
+					delete(device.Metadata, key)
+				}
+			}
+		} else {
+			device.Metadata = nil
+		}
+
+		// single entries are validated with the patch, but the merged result could exceed the limits
+		if err := domain.ValidateMetadata(device.Metadata); err != nil {
+			return nil, apiError.Validation(validation.Field("/metadata", err))
+		}
+	}
+
+	if patch.Tags.Present() {
+		// arrays are replaced as a whole by a merge patch
+		device.Tags = domain.NormalizeTags(patch.Tags.Some())
+	}
+
+	if patch.ClientCertificates.Present() {
+		device.ClientCertificates, err = domain.NormalizeFingerprints(patch.ClientCertificates.Some())
+		if err != nil {
+			return nil, apiError.Validation(validation.Field("/client_certificates", err))
+		}
+	}
+
+	if patch.MonthlySignatureQuota.Present() {
+		device.MonthlySignatureQuota = patch.MonthlySignatureQuota.SqlNull()
+	}
+
+	if patch.Status.Present() {
+		status, err := patch.Status.Expect("status can not be null")
+		if err != nil {
+			return nil, apiError.Validation(validation.Field("/status", err))
+		}
+		device.Status = status
+	}
+
+	if err := updateDevice(ctx, deviceRepository, device, fencingToken); err != nil {
+		logger.Error("failed updating device", "error", err)
+		return nil, err
+	}
+
+	return device, nil
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies what happened to a device
+type EventType string
+
+// Validate checks if the event type is supported
+func (t EventType) Validate() error {
+	if !slices.Contains(EventTypes, t) {
+		return errors.New("event type invalid value")
+	}
+	return nil
+}
+
+// Supported event types
+const (
+	EventDeviceCreated    = EventType("device.created")    // A device was created
+	EventDeviceDeleted    = EventType("device.deleted")    // A device was deleted
+	EventKeyRotated       = EventType("key.rotated")       // The signing key of a device was replaced
+	EventSignatureCreated = EventType("signature.created") // Data was signed with a device
+)
+
+// EventTypes lists all supported event types
+var EventTypes = []EventType{
+	EventDeviceCreated,
+	EventDeviceDeleted,
+	EventKeyRotated,
+	EventSignatureCreated,
+}
+
+// Event describes a change of a device, which is delivered to subscribers of the organization
+type Event struct {
+	Id             uuid.UUID       // Unique identifier for the event, stays the same for every delivery attempt
+	OrganizationId uuid.UUID       // Organization the device belongs to
+	Type           EventType       // What happened
+	DeviceId       uuid.UUID       // Device the event is about
+	Data           json.RawMessage // Type specific payload
+	CreatedAt      time.Time       // Time the change happened
+}
+
+// Copy creates a deep copy of the event to prevent unintended mutations
+func (e *Event) Copy() *Event {
+	newEvent := *e
+	newEvent.Data = slices.Clone(e.Data)
+	return &newEvent
+}
+
+// DeviceEventData is the payload of device.created and device.deleted events
+type DeviceEventData struct {
+	DeviceId         uuid.UUID        `json:"device_id"`
+	SigningAlgorithm SigningAlgorithm `json:"signing_algorithm"`
+	Label            string           `json:"label,omitempty"`
+}
+
+// KeyRotatedEventData is the payload of key.rotated events
+type KeyRotatedEventData struct {
+	DeviceId  uuid.UUID `json:"device_id"`
+	PublicKey string    `json:"public_key"`
+	// KeyVersion is the number of keys the device had so far, the first key has version 1
+	KeyVersion int `json:"key_version"`
+}
+
+// SignatureCreatedEventData is the payload of signature.created events
+type SignatureCreatedEventData struct {
+	DeviceId         uuid.UUID `json:"device_id"`
+	SignatureCounter int       `json:"signature_counter"`
+	Signature        string    `json:"signature"`
+}
+
+// NewEvent creates an event about the device with a JSON encoded payload
+func NewEvent(device *Device, eventType EventType, data any, now time.Time) (*Event, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		Id:             id,
+		OrganizationId: device.OrganizationId,
+		Type:           eventType,
+		DeviceId:       device.Id,
+		Data:           encoded,
+		CreatedAt:      now,
+	}, nil
+}
+
+// EventPublisher is notified about events once the change they describe was committed
+type EventPublisher interface {
+	Publish(event *Event)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyRecord stores the result of a signing request, so a retry with the same key can be answered
+// with the original signature instead of creating a new one
+type IdempotencyRecord struct {
+	DeviceId         uuid.UUID // Device the signature was created with
+	Key              string    // Idempotency key provided by the client
+	RequestHash      string    // Hash of the request, used to detect a key reused for a different request
+	Signature        string    // Signature created by the original request
+	SignatureCounter int       // Signature counter after the original request
+	Data             string    // Data signed by the original request
+	LastSignature    string    // Signature the original signature was chained to
+	CreatedAt        time.Time // Time of the original request
+	ExpiresAt        time.Time // Time after which the record is no longer considered
+}
+
+// Expired reports whether the record is no longer valid at the given time
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IdempotencyRepository defines the contract for idempotency record storage operations
+type IdempotencyRepository interface {
+	Get(ctx context.Context, deviceId uuid.UUID, key string) (*IdempotencyRecord, error)
+	Save(ctx context.Context, record *IdempotencyRecord) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+	// DeleteByDevice removes the records of the device, so a device recreated with its id can't replay them
+	DeleteByDevice(ctx context.Context, deviceId uuid.UUID) error
+}
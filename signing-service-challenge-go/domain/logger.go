@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerContextKey struct{}
+
+// WithLogger returns a context carrying the logger for everything done on behalf of a request,
+// so log lines can be correlated by the attributes of the logger, like the request id
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the logger of the context, or the default logger if there is none
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithLogAttrs returns a context whose logger additionally logs the attributes
+func WithLogAttrs(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, LoggerFromContext(ctx).With(args...))
+}
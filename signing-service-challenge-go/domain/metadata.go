@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+)
+
+// Limits for client supplied device metadata and tags
+const (
+	MaxMetadataEntries     = 32
+	MaxMetadataValueLength = 256
+	MaxTags                = 32
+)
+
+// keyPattern restricts metadata keys and tags to characters which are safe to use in query parameters
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,62}$`)
+
+// ValidateMetadata checks the number of entries and the format of metadata keys and values.
+// Errors of single entries point to the key of the entry, see [validation.Errors].
+func ValidateMetadata(metadata map[string]string) error {
+	var v validation.Validator
+	v.Assert(len(metadata) <= MaxMetadataEntries, "", fmt.Sprintf("metadata can have at most %d entries", MaxMetadataEntries))
+	for key, value := range metadata {
+		v.Assert(keyPattern.MatchString(key), validation.Pointer(key), fmt.Sprintf("metadata key %q invalid value", key))
+		v.Check(validation.Pointer(key), validation.Text(value, 0, MaxMetadataValueLength))
+	}
+	return v.Err()
+}
+
+// ValidateTags checks the number and the format of tags.
+// Errors of single tags point to the index of the tag, see [validation.Errors].
+func ValidateTags(tags []string) error {
+	var v validation.Validator
+	v.Assert(len(tags) <= MaxTags, "", fmt.Sprintf("at most %d tags are allowed", MaxTags))
+	for i, tag := range tags {
+		v.Assert(keyPattern.MatchString(tag), validation.Pointer(i), fmt.Sprintf("tag %q invalid value", tag))
+	}
+	return v.Err()
+}
+
+// NormalizeTags turns tags into a sorted set without duplicates
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	normalized := slices.Clone(tags)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOrganizationId identifies the organization used when a request doesn't name one,
+// so single-tenant deployments keep working without any organization management
+var DefaultOrganizationId = uuid.Nil
+
+// Organization represents a tenant owning an isolated namespace of devices
+type Organization struct {
+	Id        uuid.UUID // Unique identifier for the organization
+	Name      string    // Human-readable name
+	CreatedAt time.Time // Organization creation timestamp
+	UpdatedAt time.Time // Last modification timestamp
+}
+
+// Copy creates a copy of the organization to prevent unintended mutations
+func (o *Organization) Copy() *Organization {
+	newOrganization := *o
+	return &newOrganization
+}
+
+// OrganizationRepository defines the contract for organization storage operations
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *Organization) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Organization, error)
+	List(ctx context.Context) ([]*Organization, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type organizationContextKey struct{}
+
+// WithOrganization returns a context scoping all device operations to the organization
+func WithOrganization(ctx context.Context, organizationId uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationContextKey{}, organizationId)
+}
+
+// OrganizationFromContext returns the organization the context is scoped to
+func OrganizationFromContext(ctx context.Context) (uuid.UUID, bool) {
+	organizationId, ok := ctx.Value(organizationContextKey{}).(uuid.UUID)
+	return organizationId, ok
+}
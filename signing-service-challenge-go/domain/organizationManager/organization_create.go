@@ -0,0 +1,34 @@
+package organizationManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+type NewOrganization struct {
+	Name string
+}
+
+func (h *Handler) CreateOrganization(ctx context.Context, in NewOrganization) (*domain.Organization, error) {
+	logger := domain.LoggerFromContext(ctx)
+	organizationRepository := h.storage.Organizations()
+
+	organizationId, err := uuid.NewRandom()
+	if err != nil {
+		logger.Error("uuid generation failed", "error", err)
+		return nil, err
+	}
+
+	newOrganization := &domain.Organization{
+		Id:   organizationId,
+		Name: in.Name,
+	}
+	if err := organizationRepository.Create(ctx, newOrganization); err != nil {
+		logger.Error("creating organization failed", "error", err)
+		return nil, err
+	}
+
+	return newOrganization, nil
+}
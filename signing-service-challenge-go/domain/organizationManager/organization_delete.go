@@ -0,0 +1,40 @@
+package organizationManager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) DeleteOrganization(ctx context.Context, organizationId uuid.UUID) error {
+	logger := domain.LoggerFromContext(ctx)
+	if organizationId == domain.DefaultOrganizationId {
+		return apiError.WithCode(apiError.New(http.StatusConflict, "the default organization can not be deleted"), apiError.CodeOrganizationConflict)
+	}
+
+	return h.storage.WithTransaction(ctx, func(ctx context.Context, storage persistence.Storage) error {
+		// devices would become unreachable, so they have to be deleted first
+		count, err := storage.Devices().Count(domain.WithOrganization(ctx, organizationId), domain.DeviceFilter{})
+		if err != nil {
+			logger.Error("failed fetching device count", "error", err)
+			return err
+		}
+		if count > 0 {
+			return apiError.WithCode(apiError.New(http.StatusConflict, "organization still has devices"), apiError.CodeOrganizationConflict)
+		}
+
+		if err := storage.Organizations().Delete(ctx, organizationId); err != nil {
+			if errors.Is(err, persistence.ErrNotFound) {
+				return nil
+			}
+			logger.Error("deleting organization failed", "error", err)
+			return err
+		}
+		return nil
+	})
+}
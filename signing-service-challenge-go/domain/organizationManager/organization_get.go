@@ -0,0 +1,23 @@
+package organizationManager
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) GetOrganization(ctx context.Context, organizationId uuid.UUID) (*domain.Organization, error) {
+	logger := domain.LoggerFromContext(ctx)
+	organizationRepository := h.storage.Organizations()
+
+	organization, err := organizationRepository.GetByID(ctx, organizationId)
+	if err != nil {
+		logger.Error("failed fetching organization", "error", err)
+		return nil, apiError.WithCode(apiError.New(http.StatusNotFound, "organization not found"), apiError.CodeOrganizationNotFound)
+	}
+
+	return organization, nil
+}
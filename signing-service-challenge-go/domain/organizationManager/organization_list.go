@@ -0,0 +1,20 @@
+package organizationManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+func (h *Handler) ListOrganizations(ctx context.Context) ([]*domain.Organization, error) {
+	logger := domain.LoggerFromContext(ctx)
+	organizationRepository := h.storage.Organizations()
+
+	organizations, err := organizationRepository.List(ctx)
+	if err != nil {
+		logger.Error("failed fetching organizations", "error", err)
+		return nil, err
+	}
+
+	return organizations, nil
+}
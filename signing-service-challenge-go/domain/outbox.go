@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEntry is an event waiting to be delivered to the sinks of the outbox.
+// It is stored in the same transaction as the change it describes, so no event is lost if the process crashes.
+type OutboxEntry struct {
+	Event         Event     // Event to deliver
+	Delivered     []string  // Names of the sinks which already received the event
+	Attempts      int       // Number of failed delivery attempts
+	LastError     string    // Reason of the last failed attempt
+	NextAttemptAt time.Time // Earliest time of the next attempt
+}
+
+// Copy creates a deep copy of the entry to prevent unintended mutations
+func (e *OutboxEntry) Copy() *OutboxEntry {
+	newEntry := *e
+	newEntry.Event = *e.Event.Copy()
+	newEntry.Delivered = slices.Clone(e.Delivered)
+	return &newEntry
+}
+
+// OutboxRepository defines the contract for outbox storage operations
+type OutboxRepository interface {
+	// Append adds the event to the outbox, it is due immediately
+	Append(ctx context.Context, event *Event) error
+	// Pending returns entries of all organizations whose next attempt is not after now, in the order they were appended
+	Pending(ctx context.Context, now time.Time, limit int) ([]*OutboxEntry, error)
+	Update(ctx context.Context, entry *OutboxEntry) error
+	// Delete removes an entry once it was delivered to all sinks
+	Delete(ctx context.Context, eventId uuid.UUID) error
+}
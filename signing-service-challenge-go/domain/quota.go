@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+)
+
+// quotaPeriodLayout formats the calendar month a signature quota applies to
+const quotaPeriodLayout = "2006-01"
+
+// QuotaPeriod returns the calendar month (UTC) the time falls into
+func QuotaPeriod(now time.Time) string {
+	return now.UTC().Format(quotaPeriodLayout)
+}
+
+// NextQuotaPeriod returns the time the quota period after the one of now starts
+func NextQuotaPeriod(now time.Time) time.Time {
+	year, month, _ := now.UTC().Date()
+	return time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// MonthlyUsage returns the number of signatures created in the quota period of now
+func (d *Device) MonthlyUsage(now time.Time) int {
+	if d.QuotaPeriod != QuotaPeriod(now) {
+		return 0
+	}
+	return d.QuotaUsage
+}
+
+// QuotaExceeded reports whether the device has no signatures left in the quota period of now
+func (d *Device) QuotaExceeded(now time.Time) bool {
+	return d.MonthlySignatureQuota.Valid && d.MonthlyUsage(now) >= d.MonthlySignatureQuota.V
+}
+
+// CountQuotaUsage records a signature in the quota period of now
+func (d *Device) CountQuotaUsage(now time.Time) {
+	d.QuotaUsage = d.MonthlyUsage(now) + 1
+	d.QuotaPeriod = QuotaPeriod(now)
+}
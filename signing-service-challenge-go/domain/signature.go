@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Signature is an entry of the signature log of a device
+type Signature struct {
+	DeviceId      uuid.UUID // Device the signature was created with
+	Counter       int       // Signature counter of the device after signing
+	Signature     string    // Base64 encoded signature of the data
+	Data          string    // Data which was signed
+	LastSignature string    // Signature the signature is chained to, the base64 encoded device id for the first one
+	KeyVersion    int       // Version of the key used, the index of its public key starting at 1
+	CreatedAt     time.Time // Time of signing
+}
+
+// SignedData returns the signed data in the format returned to clients, see [FormatSignedData]
+func (s *Signature) SignedData() string {
+	return FormatSignedData(s.Counter, s.LastSignature, s.Data)
+}
+
+// FormatSignedData formats the data of a signature as "<counter>_<last signature>_<data>".
+// The last signature is placed before the data, so the data may contain underscores.
+func FormatSignedData(counter int, lastSignature string, data string) string {
+	return strconv.Itoa(counter) + "_" + lastSignature + "_" + data
+}
+
+// SignatureRepository defines the contract for signature log storage operations
+type SignatureRepository interface {
+	Create(ctx context.Context, signature *Signature) error
+	// List returns the signatures of the device with a counter after the given one, in the order they were created
+	List(ctx context.Context, deviceId uuid.UUID, afterCounter int, limit int) ([]*Signature, error)
+	// DeleteByDevice removes the signature log of the device, so a device recreated with its id starts a new log
+	DeleteByDevice(ctx context.Context, deviceId uuid.UUID) error
+}
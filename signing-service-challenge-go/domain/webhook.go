@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a subscription of an HTTP endpoint to the events of an organization
+type Webhook struct {
+	Id             uuid.UUID   // Unique identifier for the webhook
+	OrganizationId uuid.UUID   // Organization whose events are delivered
+	URL            string      // Endpoint the events are posted to
+	Secret         string      // Key the payloads are signed with, so the receiver can authenticate them
+	EventTypes     []EventType // Event types delivered to the endpoint, all if empty
+	CreatedAt      time.Time   // Webhook creation timestamp
+}
+
+// Copy creates a deep copy of the webhook to prevent unintended mutations
+func (w *Webhook) Copy() *Webhook {
+	newWebhook := *w
+	newWebhook.EventTypes = slices.Clone(w.EventTypes)
+	return &newWebhook
+}
+
+// Subscribed reports whether events of the type are delivered to the webhook
+func (w *Webhook) Subscribed(eventType EventType) bool {
+	return len(w.EventTypes) == 0 || slices.Contains(w.EventTypes, eventType)
+}
+
+// WebhookRepository defines the contract for webhook storage operations
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	List(ctx context.Context, organizationId uuid.UUID) ([]*Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// DeliveryStatus represents the state of a webhook delivery
+type DeliveryStatus string
+
+// Supported delivery states
+const (
+	DeliveryStatusPending   = DeliveryStatus("pending")   // Not delivered yet, will be (re)tried
+	DeliveryStatusDelivered = DeliveryStatus("delivered") // Accepted by the receiver
+	DeliveryStatusFailed    = DeliveryStatus("failed")    // Given up after too many attempts
+)
+
+// DeliveryAttempt records a single try to deliver an event
+type DeliveryAttempt struct {
+	AttemptedAt time.Time     // Time the request was sent
+	StatusCode  int           // HTTP status returned by the receiver, zero if there was no response
+	Error       string        // Reason the attempt failed
+	Duration    time.Duration // Time until the receiver responded
+}
+
+// WebhookDelivery is an entry of the webhook outbox, it is kept after delivery as log of all attempts until it is swept
+type WebhookDelivery struct {
+	Id             uuid.UUID         // Unique identifier for the delivery
+	OrganizationId uuid.UUID         // Organization the webhook belongs to
+	WebhookId      uuid.UUID         // Webhook the event is delivered to
+	Event          Event             // Event to deliver
+	Status         DeliveryStatus    // Current state of the delivery
+	Attempts       []DeliveryAttempt // All attempts made so far
+	NextAttemptAt  time.Time         // Earliest time of the next attempt while pending
+	CreatedAt      time.Time         // Time the delivery was enqueued
+	UpdatedAt      time.Time         // Last modification timestamp
+}
+
+// Copy creates a deep copy of the delivery to prevent unintended mutations
+func (d *WebhookDelivery) Copy() *WebhookDelivery {
+	newDelivery := *d
+	newDelivery.Event = *d.Event.Copy()
+	newDelivery.Attempts = slices.Clone(d.Attempts)
+	return &newDelivery
+}
+
+// WebhookDeliveryRepository defines the contract for webhook delivery storage operations
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+	// List returns the deliveries of the webhook, newest first
+	List(ctx context.Context, webhookId uuid.UUID, limit int) ([]*WebhookDelivery, error)
+	// Due returns pending deliveries of all organizations whose next attempt is not after now, oldest first
+	Due(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+	Update(ctx context.Context, delivery *WebhookDelivery) error
+	// DeleteCompleted removes delivered and failed deliveries last updated before the time, pending ones are kept
+	DeleteCompleted(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,35 @@
+package webhookManager
+
+import (
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+)
+
+// secretPrefix makes webhook secrets recognizable, e.g. for secret scanners
+const secretPrefix = "whsec_"
+
+type Handler struct {
+	storage        persistence.Storage
+	privateTargets bool
+}
+
+type Option func(*Handler)
+
+// WithPrivateTargets allows webhooks to private, loopback and link-local addresses, e.g. for development.
+func WithPrivateTargets() Option {
+	return func(h *Handler) {
+		h.privateTargets = true
+	}
+}
+
+func New(
+	storage persistence.Storage,
+	options ...Option,
+) *Handler {
+	h := &Handler{
+		storage: storage,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
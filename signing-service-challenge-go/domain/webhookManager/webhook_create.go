@@ -0,0 +1,58 @@
+package webhookManager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/validation"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/webhook"
+	"github.com/google/uuid"
+)
+
+type NewWebhook struct {
+	OrganizationId uuid.UUID
+	URL            string
+	EventTypes     []domain.EventType
+}
+
+func (h *Handler) CreateWebhook(ctx context.Context, in NewWebhook) (*domain.Webhook, error) {
+	logger := domain.LoggerFromContext(ctx)
+	webhookRepository := h.storage.Webhooks()
+
+	if !h.privateTargets {
+		if err := webhook.ValidateTarget(in.URL); err != nil {
+			return nil, apiError.Validation(validation.Field("/url", err))
+		}
+	}
+
+	webhookId, err := uuid.NewRandom()
+	if err != nil {
+		logger.Error("uuid generation failed", "error", err)
+		return nil, err
+	}
+
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		logger.Error("secret generation failed", "error", err)
+		return nil, err
+	}
+
+	newWebhook := &domain.Webhook{
+		Id:             webhookId,
+		OrganizationId: in.OrganizationId,
+		URL:            in.URL,
+		Secret:         secretPrefix + base64.RawURLEncoding.EncodeToString(randomBytes),
+		EventTypes:     in.EventTypes,
+		CreatedAt:      time.Now(),
+	}
+	if err := webhookRepository.Create(ctx, newWebhook); err != nil {
+		logger.Error("creating webhook failed", "error", err)
+		return nil, err
+	}
+
+	return newWebhook, nil
+}
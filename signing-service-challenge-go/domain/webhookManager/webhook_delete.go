@@ -0,0 +1,32 @@
+package webhookManager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+)
+
+// DeleteWebhook stops the delivery of events to the webhook, pending deliveries are dropped by the dispatcher.
+func (h *Handler) DeleteWebhook(ctx context.Context, organizationId uuid.UUID, webhookId uuid.UUID) error {
+	logger := domain.LoggerFromContext(ctx)
+	webhookRepository := h.storage.Webhooks()
+
+	webhook, err := webhookRepository.GetByID(ctx, webhookId)
+	if errors.Is(err, persistence.ErrNotFound) || (err == nil && webhook.OrganizationId != organizationId) {
+		return nil
+	}
+	if err != nil {
+		logger.Error("failed fetching webhook", "error", err)
+		return err
+	}
+
+	if err := webhookRepository.Delete(ctx, webhookId); err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		logger.Error("deleting webhook failed", "error", err)
+		return err
+	}
+
+	return nil
+}
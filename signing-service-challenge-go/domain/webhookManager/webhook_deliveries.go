@@ -0,0 +1,24 @@
+package webhookManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// ListDeliveries returns the delivery log of the webhook, newest first
+func (h *Handler) ListDeliveries(ctx context.Context, organizationId uuid.UUID, webhookId uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	logger := domain.LoggerFromContext(ctx)
+	if _, err := h.GetWebhook(ctx, organizationId, webhookId); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := h.storage.WebhookDeliveries().List(ctx, webhookId, limit)
+	if err != nil {
+		logger.Error("failed fetching webhook deliveries", "error", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,21 @@
+package webhookManager
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) GetWebhook(ctx context.Context, organizationId uuid.UUID, webhookId uuid.UUID) (*domain.Webhook, error) {
+	logger := domain.LoggerFromContext(ctx)
+	webhook, err := h.storage.Webhooks().GetByID(ctx, webhookId)
+	if err != nil || webhook.OrganizationId != organizationId {
+		logger.Error("failed fetching webhook", "error", err)
+		return nil, apiError.WithCode(apiError.New(http.StatusNotFound, "webhook not found"), apiError.CodeWebhookNotFound)
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,19 @@
+package webhookManager
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ListWebhooks(ctx context.Context, organizationId uuid.UUID) ([]*domain.Webhook, error) {
+	logger := domain.LoggerFromContext(ctx)
+	webhooks, err := h.storage.Webhooks().List(ctx, organizationId)
+	if err != nil {
+		logger.Error("failed fetching webhooks", "error", err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
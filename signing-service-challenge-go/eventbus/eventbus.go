@@ -0,0 +1,125 @@
+// Package eventbus distributes device events within the process, e.g. to clients streaming them over SSE.
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+const (
+	// DefaultHistory is the number of recent events kept, so subscribers can resume after a reconnect
+	DefaultHistory = 1024
+	// subscriptionBuffer is the number of events queued per subscriber before it is dropped as too slow
+	subscriptionBuffer = 256
+)
+
+// Message is an event together with its position in the bus.
+// Sequences increase with every published event and start at 1 when the process starts.
+type Message struct {
+	Sequence uint64
+	Event    *domain.Event
+}
+
+// Bus fans out published events to all matching subscriptions.
+// Publishing never blocks, subscribers not keeping up are dropped and have to resubscribe.
+type Bus struct {
+	sequence      uint64
+	history       []Message
+	historySize   int
+	subscriptions map[*Subscription]struct{}
+	mu            sync.Mutex
+}
+
+func New(historySize int) *Bus {
+	return &Bus{
+		historySize:   historySize,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish delivers the event to all subscriptions it matches and keeps it in the history.
+func (b *Bus) Publish(event *domain.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequence++
+	message := Message{Sequence: b.sequence, Event: event}
+
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, message)
+	}
+
+	for subscription := range b.subscriptions {
+		if !subscription.match(event) {
+			continue
+		}
+		select {
+		case subscription.messages <- message:
+		default:
+			// the subscriber is too slow, it can resume from the history with the last sequence it received
+			b.unsubscribe(subscription)
+		}
+	}
+}
+
+// Subscribe registers a subscription for events matching the filter, a nil filter matches all events.
+// Matching events from the history with a sequence after the given one are returned as backlog,
+// no event published in between is lost or received twice.
+func (b *Bus) Subscribe(after uint64, match func(event *domain.Event) bool) (*Subscription, []Message) {
+	if match == nil {
+		match = func(*domain.Event) bool { return true }
+	}
+	subscription := &Subscription{
+		bus:      b,
+		match:    match,
+		messages: make(chan Message, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Message
+	if after > 0 {
+		for _, message := range b.history {
+			if message.Sequence > after && match(message.Event) {
+				backlog = append(backlog, message)
+			}
+		}
+	}
+	b.subscriptions[subscription] = struct{}{}
+
+	return subscription, backlog
+}
+
+// unsubscribe removes the subscription, the caller has to hold the lock
+func (b *Bus) unsubscribe(subscription *Subscription) {
+	if _, exists := b.subscriptions[subscription]; !exists {
+		return
+	}
+	delete(b.subscriptions, subscription)
+	close(subscription.messages)
+}
+
+// Subscription receives the events published after it was created
+type Subscription struct {
+	bus      *Bus
+	match    func(event *domain.Event) bool
+	messages chan Message
+}
+
+// Messages returns the channel events are received on, it is closed when the subscription ends.
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.unsubscribe(s)
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	assert := require.New(t)
+
+	bus := New(3)
+	device1, device2 := uuid.New(), uuid.New()
+	publish := func(deviceId uuid.UUID) {
+		bus.Publish(&domain.Event{Id: uuid.New(), DeviceId: deviceId, Type: domain.EventSignatureCreated})
+	}
+	sequences := func(messages []Message) []uint64 {
+		var out []uint64
+		for _, message := range messages {
+			out = append(out, message.Sequence)
+		}
+		return out
+	}
+
+	all, backlog := bus.Subscribe(0, nil)
+	assert.Empty(backlog)
+	onlyDevice1, _ := bus.Subscribe(0, func(event *domain.Event) bool { return event.DeviceId == device1 })
+
+	publish(device1)
+	publish(device2)
+	publish(device1)
+	publish(device2)
+
+	// Test case 1: Subscriptions receive matching events in order
+	assert.Equal(uint64(1), (<-all.Messages()).Sequence)
+	assert.Equal(uint64(2), (<-all.Messages()).Sequence)
+	assert.Equal(uint64(1), (<-onlyDevice1.Messages()).Sequence)
+	assert.Equal(uint64(3), (<-onlyDevice1.Messages()).Sequence)
+
+	// Test case 2: Resuming replays the history after the sequence, as far as it is kept
+	_, backlog = bus.Subscribe(2, nil)
+	assert.Equal([]uint64{3, 4}, sequences(backlog))
+	_, backlog = bus.Subscribe(1, func(event *domain.Event) bool { return event.DeviceId == device2 })
+	assert.Equal([]uint64{2, 4}, sequences(backlog))
+	_, backlog = bus.Subscribe(4, nil)
+	assert.Empty(backlog)
+
+	// Test case 3: Closed subscriptions stop receiving events
+	onlyDevice1.Close()
+	onlyDevice1.Close()
+	publish(device1)
+	_, open := <-onlyDevice1.Messages()
+	assert.False(open)
+
+	// Test case 4: Slow subscribers are dropped instead of blocking the publisher
+	for range subscriptionBuffer {
+		publish(device2)
+	}
+	received := 0
+	for range all.Messages() {
+		received++
+	}
+	assert.Equal(subscriptionBuffer, received)
+}
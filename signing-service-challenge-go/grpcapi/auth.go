@@ -0,0 +1,128 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// OrganizationMetadata selects the organization of a call, like the X-Organization-Id header of the HTTP API
+const OrganizationMetadata = "x-organization-id"
+
+// methodScopes are the scopes an API key needs to call the methods
+var methodScopes = map[string]domain.Scope{
+	signingpb.SigningService_CreateDevice_FullMethodName:   domain.ScopeDeviceWrite,
+	signingpb.SigningService_GetDevice_FullMethodName:      domain.ScopeDeviceRead,
+	signingpb.SigningService_ListDevices_FullMethodName:    domain.ScopeDeviceRead,
+	signingpb.SigningService_DeleteDevice_FullMethodName:   domain.ScopeDeviceWrite,
+	signingpb.SigningService_SignData_FullMethodName:       domain.ScopeDeviceSign,
+	signingpb.SigningService_ListSignatures_FullMethodName: domain.ScopeDeviceRead,
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.limitGlobal(ctx); err != nil {
+		return nil, err
+	}
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limitCaller(ctx, request); err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (s *Server) streamInterceptor(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.limitGlobal(stream.Context()); err != nil {
+		return err
+	}
+	ctx, err := s.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	if err := s.limitCaller(ctx, nil); err != nil {
+		return err
+	}
+	return handler(server, &scopedStream{ServerStream: stream, ctx: ctx})
+}
+
+// scopedStream replaces the context of a stream with the authorized one
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize authenticates the call and scopes it to an organization, following the rules of the HTTP API
+func (s *Server) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	// only verified chains identify a client, unverified peer certificates are ignored
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			ctx = domain.WithClientCertificate(ctx, domain.NewClientCertificate(info.State.VerifiedChains[0][0]))
+		}
+	}
+
+	organizationId := domain.DefaultOrganizationId
+	var key *domain.APIKey
+	if s.authentication {
+		secret, found := strings.CutPrefix(first(md, "authorization"), "Bearer ")
+		if !found || secret == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing api key")
+		}
+
+		var err error
+		key, err = s.apiKeys.Authenticate(ctx, secret)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		scope, known := methodScopes[fullMethod]
+		if !known || !key.HasScope(scope) {
+			domain.LoggerFromContext(ctx).Error("missing scope", "scope", scope)
+			return nil, status.Error(codes.PermissionDenied, "api key is missing scope "+string(scope))
+		}
+
+		ctx = domain.WithAPIKey(ctx, key)
+		organizationId = key.OrganizationId
+	}
+
+	if value := first(md, OrganizationMetadata); value != "" {
+		requested, err := uuid.Parse(value)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid organization uuid")
+		}
+		if key != nil && !key.HasScope(domain.ScopePlatform) && requested != key.OrganizationId {
+			return nil, status.Error(codes.PermissionDenied, "api key is not valid for the organization")
+		}
+		organizationId = requested
+	}
+
+	if _, err := s.organizations.GetOrganization(ctx, organizationId); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return domain.WithOrganization(ctx, organizationId), nil
+}
+
+// first returns the first metadata value of the key
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
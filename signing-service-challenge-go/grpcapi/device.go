@@ -0,0 +1,51 @@
+package grpcapi
+
+import (
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var signingAlgorithms = map[signingpb.SigningAlgorithm]domain.SigningAlgorithm{
+	signingpb.SigningAlgorithm_SIGNING_ALGORITHM_ECC: domain.SigningAlgorithmEcc,
+	signingpb.SigningAlgorithm_SIGNING_ALGORITHM_RSA: domain.SigningAlgorithmRsa,
+}
+
+var deviceStatuses = map[domain.DeviceStatus]signingpb.DeviceStatus{
+	domain.DeviceStatusActive:   signingpb.DeviceStatus_DEVICE_STATUS_ACTIVE,
+	domain.DeviceStatusDisabled: signingpb.DeviceStatus_DEVICE_STATUS_DISABLED,
+}
+
+// newDevice maps a [domain.Device] to its public representation.
+func newDevice(device *domain.Device) *signingpb.Device {
+	out := &signingpb.Device{
+		Id:               device.Id.String(),
+		Status:           deviceStatuses[device.Status],
+		Metadata:         device.Metadata,
+		Tags:             device.Tags,
+		PublicKeys:       device.PublicKeys,
+		SignatureCounter: int64(device.SignatureCounter),
+		CreatedAt:        timestamppb.New(device.CreatedAt),
+	}
+	for algorithm, signingAlgorithm := range signingAlgorithms {
+		if signingAlgorithm == device.SigningAlgorithm {
+			out.SigningAlgorithm = algorithm
+		}
+	}
+	if device.Label.Valid {
+		out.Label = &device.Label.V
+	}
+	return out
+}
+
+// parseDeviceId parses the id of a device from a request
+func parseDeviceId(id string) (uuid.UUID, error) {
+	deviceId, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid uuid: "+err.Error())
+	}
+	return deviceId, nil
+}
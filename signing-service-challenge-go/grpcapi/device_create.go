@@ -0,0 +1,55 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateDevice(ctx context.Context, request *signingpb.CreateDeviceRequest) (*signingpb.Device, error) {
+	algorithm, known := signingAlgorithms[request.GetSigningAlgorithm()]
+	validationErr := errors.Join(
+		domain.ValidateMetadata(request.GetMetadata()),
+		domain.ValidateTags(request.GetTags()),
+	)
+	if !known {
+		validationErr = errors.Join(validationErr, errors.New("signing algorithm invalid value"))
+	}
+	if request.Id != nil {
+		validationErr = errors.Join(validationErr, domain.ValidateDeviceId(request.GetId()))
+	}
+	if validationErr != nil {
+		return nil, status.Error(codes.InvalidArgument, "validation failed: "+validationErr.Error())
+	}
+
+	in := deviceManager.NewDevice{
+		Metadata:         request.GetMetadata(),
+		Tags:             request.GetTags(),
+		SigningAlgorithm: algorithm,
+	}
+	if request.Id != nil {
+		in.Id = null.New(request.GetId())
+	}
+	if request.Label != nil {
+		in.Label = null.New(request.GetLabel())
+	}
+
+	device, err := s.devices.CreateDevice(ctx, in)
+	if err != nil {
+		var apiErr apiError.Error
+		if errors.As(err, &apiErr) && apiErr.Code() == http.StatusConflict {
+			return nil, status.Error(codes.AlreadyExists, apiErr.Error())
+		}
+		return nil, toStatus(err)
+	}
+
+	return newDevice(device), nil
+}
@@ -0,0 +1,31 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) DeleteDevice(ctx context.Context, request *signingpb.DeleteDeviceRequest) (*signingpb.DeleteDeviceResponse, error) {
+	deviceId, err := parseDeviceId(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	// lock here to ensure we don't delete the device while signing is in progress
+	lock, err := s.locker.Acquire(ctx, deviceId)
+	if err != nil {
+		domain.LoggerFromContext(ctx).Error("unable to acquire lock", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer lock.Unlock()
+
+	if err := s.devices.DeleteDevice(ctx, deviceId); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &signingpb.DeleteDeviceResponse{}, nil
+}
@@ -0,0 +1,21 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+)
+
+func (s *Server) GetDevice(ctx context.Context, request *signingpb.GetDeviceRequest) (*signingpb.Device, error) {
+	deviceId, err := parseDeviceId(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := s.devices.GetDevice(ctx, deviceId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return newDevice(device), nil
+}
@@ -0,0 +1,33 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ListDevices(ctx context.Context, request *signingpb.ListDevicesRequest) (*signingpb.ListDevicesResponse, error) {
+	filter := domain.DeviceFilter{
+		Tags:     request.GetTags(),
+		Metadata: request.GetMetadata(),
+	}
+	if err := errors.Join(domain.ValidateTags(filter.Tags), domain.ValidateMetadata(filter.Metadata)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "validation failed: "+err.Error())
+	}
+
+	devices, err := s.devices.ListDevices(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	out := &signingpb.ListDevicesResponse{}
+	for _, device := range devices {
+		out.Devices = append(out.Devices, newDevice(device))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxIdempotencyKeyLength matches the limit of the Idempotency-Key header of the HTTP API
+const maxIdempotencyKeyLength = 255
+
+func (s *Server) SignData(ctx context.Context, request *signingpb.SignDataRequest) (*signingpb.SignDataResponse, error) {
+	deviceId, err := parseDeviceId(request.GetDeviceId())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.GetData()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "data must not be empty")
+	}
+
+	var idempotencyKey null.Null[string]
+	if request.IdempotencyKey != nil {
+		key := request.GetIdempotencyKey()
+		if len(key) == 0 || len(key) > maxIdempotencyKeyLength {
+			return nil, status.Error(codes.InvalidArgument, "idempotency key must be between 1 and 255 characters long")
+		}
+		for _, c := range key {
+			if c < '!' || c > '~' {
+				return nil, status.Error(codes.InvalidArgument, "idempotency key must only contain visible ascii characters")
+			}
+		}
+		idempotencyKey = null.New(key)
+	}
+
+	// the lock is shared with the HTTP API, so the signature counter is incremented safely across both
+	lock, err := s.locker.Acquire(ctx, deviceId)
+	if err != nil {
+		domain.LoggerFromContext(ctx).Error("unable to acquire lock", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer lock.Unlock()
+
+	signedData, err := s.devices.SignData(ctx, deviceId, request.GetData(), idempotencyKey, lock.Token())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &signingpb.SignDataResponse{
+		Signature:        signedData.Signature,
+		SignedData:       signedData.SecuredData(),
+		SignatureCounter: int64(signedData.SignatureCounter),
+		Replayed:         signedData.Replayed,
+	}, nil
+}
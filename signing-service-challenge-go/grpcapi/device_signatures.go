@@ -0,0 +1,49 @@
+package grpcapi
+
+import (
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// signaturePageSize is the number of signatures fetched from storage at once while streaming
+const signaturePageSize = 100
+
+func (s *Server) ListSignatures(request *signingpb.ListSignaturesRequest, stream grpc.ServerStreamingServer[signingpb.Signature]) error {
+	ctx := stream.Context()
+
+	deviceId, err := parseDeviceId(request.GetDeviceId())
+	if err != nil {
+		return err
+	}
+	if request.GetAfterCounter() < 0 {
+		return status.Error(codes.InvalidArgument, "after counter can not be negative")
+	}
+
+	after := int(request.GetAfterCounter())
+	for {
+		signatures, err := s.devices.ListSignatures(ctx, deviceId, after, signaturePageSize)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for _, signature := range signatures {
+			if err := stream.Send(&signingpb.Signature{
+				Counter:    int64(signature.Counter),
+				Signature:  signature.Signature,
+				SignedData: signature.SignedData(),
+				KeyVersion: int32(signature.KeyVersion),
+				CreatedAt:  timestamppb.New(signature.CreatedAt),
+			}); err != nil {
+				return err
+			}
+			after = signature.Counter
+		}
+
+		if len(signatures) < signaturePageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package grpcapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api/apiError"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpCodes maps the HTTP status codes of [apiError.Error] to gRPC codes
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.FailedPrecondition,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+}
+
+// toStatus converts errors of the domain services to gRPC status errors, unknown errors are internal
+func toStatus(err error) error {
+	var apiErr apiError.Error
+	if errors.As(err, &apiErr) {
+		code, known := httpCodes[apiErr.Code()]
+		if !known {
+			code = codes.Internal
+		}
+		return status.Error(code, strings.Join(apiErr.Messages(), ": "))
+	}
+	return status.Error(codes.Internal, "internal error")
+}
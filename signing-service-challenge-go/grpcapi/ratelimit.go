@@ -0,0 +1,80 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// limitGlobal takes the call from the global bucket, like the HTTP API it runs before authentication
+func (s *Server) limitGlobal(ctx context.Context) error {
+	if s.limiters.Global == nil {
+		return nil
+	}
+	return allow(ctx, s.limiters.Global.Allow(struct{}{}), "global")
+}
+
+// limitCaller takes the authorized call from the bucket of its client and signing calls from the bucket of the device.
+// Only devices of the organization of the call are taken from, so other tenants can't drain them.
+func (s *Server) limitCaller(ctx context.Context, request any) error {
+	if s.limiters.PerClient != nil {
+		if err := allow(ctx, s.limiters.PerClient.Allow(clientKey(ctx)), "client"); err != nil {
+			return err
+		}
+	}
+
+	sign, ok := request.(*signingpb.SignDataRequest)
+	if s.limiters.PerDevice == nil || !ok {
+		return nil
+	}
+	// invalid ids and unknown devices are rejected by the handler
+	deviceId, err := uuid.Parse(sign.GetDeviceId())
+	if err != nil {
+		return nil
+	}
+	if _, err := s.devices.GetDevice(ctx, deviceId); err != nil {
+		return nil
+	}
+	return allow(ctx, s.limiters.PerDevice.Allow(deviceId), "device")
+}
+
+// clientKey identifies the client of the call like the HTTP API, so both share the bucket of a client
+func clientKey(ctx context.Context) string {
+	if client := domain.ClientIdentity(ctx); client != "" {
+		return client
+	}
+	address := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		address = p.Addr.String()
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return "address:" + host
+}
+
+// allow rejects the call if the bucket is empty, the retry-after header tells when to retry
+func allow(ctx context.Context, result ratelimit.Result, name string) error {
+	if result.Allowed {
+		return nil
+	}
+	domain.LoggerFromContext(ctx).Warn("rate limit exceeded", "limit", name)
+	retryAfter := strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds())))
+	if err := grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter)); err != nil {
+		slog.Debug("unable to set retry-after header", "error", err)
+	}
+	return status.Error(codes.ResourceExhausted, name+" rate limit exceeded")
+}
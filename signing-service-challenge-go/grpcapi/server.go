@@ -0,0 +1,170 @@
+// Package grpcapi exposes the device operations as gRPC service, next to the HTTP API of package api.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative -I signingpb signingpb/signing.proto
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/apiKeyManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/organizationManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/tracing"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Server implements the SigningService with the same services as the HTTP API.
+type Server struct {
+	signingpb.UnimplementedSigningServiceServer
+
+	devices        *deviceManager.Handler
+	organizations  *organizationManager.Handler
+	apiKeys        *apiKeyManager.Handler
+	locker         lock.Locker[uuid.UUID]
+	limiters       ratelimit.Limiters
+	authentication bool
+	tls            *tls.Config
+	// shutdownTimeout bounds how long in-flight calls are awaited on shutdown
+	shutdownTimeout time.Duration
+}
+
+// DefaultShutdownTimeout is used unless configured otherwise with [WithShutdownTimeout]
+const DefaultShutdownTimeout = 30 * time.Second
+
+type config struct {
+	apiKeyManagerOptions []apiKeyManager.Option
+	limiters             ratelimit.Limiters
+	authentication       bool
+	tls                  *tls.Config
+	shutdownTimeout      time.Duration
+}
+
+// Option configures optional behaviour of the Server.
+type Option func(*config)
+
+// WithAuthentication requires all calls to present an API key with the scopes of the method.
+// The bootstrap key is granted admin access to the default organization.
+func WithAuthentication(bootstrapKey string) Option {
+	return func(c *config) {
+		c.authentication = true
+		c.apiKeyManagerOptions = append(c.apiKeyManagerOptions, apiKeyManager.WithBootstrapKey(bootstrapKey))
+	}
+}
+
+// WithRateLimiters takes calls from the buckets of the HTTP API, see [api.Server.RateLimiters].
+// Signing calls are limited per device, all calls globally and per client.
+func WithRateLimiters(limiters ratelimit.Limiters) Option {
+	return func(c *config) {
+		c.limiters = limiters
+	}
+}
+
+// WithTLS serves gRPC over TLS, verified client certificates are bound to devices like with the HTTP API.
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) {
+		c.tls = tlsConfig
+	}
+}
+
+// WithShutdownTimeout sets how long in-flight calls are awaited on shutdown before they are canceled.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+// NewServer is a factory to instantiate a new Server.
+// The device service and locker have to be shared with the HTTP API, so both serialize access to a device.
+func NewServer(
+	storage persistence.Storage,
+	devices *deviceManager.Handler,
+	locker lock.Locker[uuid.UUID],
+	options ...Option,
+) *Server {
+	c := config{
+		shutdownTimeout: DefaultShutdownTimeout,
+	}
+	for _, option := range options {
+		option(&c)
+	}
+
+	return &Server{
+		devices:         devices,
+		organizations:   organizationManager.New(storage),
+		apiKeys:         apiKeyManager.New(storage, c.apiKeyManagerOptions...),
+		locker:          locker,
+		limiters:        c.limiters,
+		authentication:  c.authentication,
+		tls:             c.tls,
+		shutdownTimeout: c.shutdownTimeout,
+	}
+}
+
+// grpcServer creates the gRPC server with the service and interceptors registered
+func (s *Server) grpcServer() *grpc.Server {
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor, s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor, s.streamInterceptor),
+	}
+	if s.tls != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(s.tls)))
+	}
+
+	server := grpc.NewServer(options...)
+	signingpb.RegisterSigningServiceServer(server, s)
+	return server
+}
+
+// Run listens on the address and serves gRPC calls until the context is canceled.
+// In-flight calls are awaited up to the shutdown timeout before the remaining ones are canceled.
+func (s *Server) Run(ctx context.Context, listenAddress string) error {
+	slog.Info("grpc server listening", "port", listenAddress, "tls", s.tls != nil)
+
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return err
+	}
+
+	server := s.grpcServer()
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("draining grpc server", "timeout", s.shutdownTimeout)
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		slog.Error("grpc calls didn't complete in time, canceling them")
+		server.Stop()
+	}
+	// serving may not even have started when the server was stopped
+	if err := <-served; !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
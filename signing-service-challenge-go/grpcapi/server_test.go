@@ -0,0 +1,319 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpb"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/ratelimit"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testBootstrapKey = "sk_bootstrap"
+
+// startServer serves the gRPC API on an in-memory connection and returns a client for it,
+// the HTTP API shares the device service, locker and rate limits
+func startServer(
+	t *testing.T,
+	httpOptions []api.Option,
+	options ...Option,
+) (signingpb.SigningServiceClient, *api.Server) {
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	httpServer := api.NewServer(storage, locker, httpOptions...)
+
+	listener := bufconn.Listen(1 << 20)
+	options = append([]Option{WithRateLimiters(httpServer.RateLimiters())}, options...)
+	server := NewServer(storage, httpServer.DeviceManager(), locker, options...).grpcServer()
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	connection, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { connection.Close() })
+
+	return signingpb.NewSigningServiceClient(connection), httpServer
+}
+
+// TestDevices verifies the device operations of the gRPC API
+func TestDevices(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	client, _ := startServer(t, nil)
+
+	// Test case 1: Create a device
+	label := "grpc"
+	device, err := client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{
+		SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_ECC,
+		Label:            &label,
+		Tags:             []string{"store"},
+	})
+	assert.NoError(err)
+	assert.NoError(uuid.Validate(device.GetId()))
+	assert.Equal(signingpb.SigningAlgorithm_SIGNING_ALGORITHM_ECC, device.GetSigningAlgorithm())
+	assert.Equal(signingpb.DeviceStatus_DEVICE_STATUS_ACTIVE, device.GetStatus())
+	assert.Equal("grpc", device.GetLabel())
+	assert.Len(device.GetPublicKeys(), 1)
+
+	// Test case 2: Invalid requests are rejected
+	_, err = client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+	id := device.GetId()
+	_, err = client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{
+		Id:               &id,
+		SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_RSA,
+	})
+	assert.Equal(codes.AlreadyExists, status.Code(err))
+	nilId := uuid.Nil.String()
+	_, err = client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{
+		Id:               &nilId,
+		SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_RSA,
+	})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	// Test case 3: Get and list devices
+	got, err := client.GetDevice(ctx, &signingpb.GetDeviceRequest{Id: device.GetId()})
+	assert.NoError(err)
+	assert.Equal(device.GetId(), got.GetId())
+	_, err = client.GetDevice(ctx, &signingpb.GetDeviceRequest{Id: uuid.NewString()})
+	assert.Equal(codes.NotFound, status.Code(err))
+	_, err = client.GetDevice(ctx, &signingpb.GetDeviceRequest{Id: "invalid"})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_RSA})
+	assert.NoError(err)
+	list, err := client.ListDevices(ctx, &signingpb.ListDevicesRequest{})
+	assert.NoError(err)
+	assert.Len(list.GetDevices(), 2)
+	list, err = client.ListDevices(ctx, &signingpb.ListDevicesRequest{Tags: []string{"store"}})
+	assert.NoError(err)
+	assert.Len(list.GetDevices(), 1)
+
+	// Test case 4: Sign data, retries with an idempotency key return the original signature
+	key := "retry-1"
+	first, err := client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId(), Data: "foo", IdempotencyKey: &key})
+	assert.NoError(err)
+	assert.Equal(int64(1), first.GetSignatureCounter())
+	assert.True(strings.HasPrefix(first.GetSignedData(), "1_"))
+	assert.True(strings.HasSuffix(first.GetSignedData(), "_foo"))
+	retry, err := client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId(), Data: "foo", IdempotencyKey: &key})
+	assert.NoError(err)
+	assert.True(retry.GetReplayed())
+	assert.Equal(first.GetSignature(), retry.GetSignature())
+
+	second, err := client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId(), Data: "bar"})
+	assert.NoError(err)
+	assert.Equal(int64(2), second.GetSignatureCounter())
+	assert.Equal("2_"+first.GetSignature()+"_bar", second.GetSignedData())
+
+	_, err = client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId()})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	// Test case 5: Stream the signature log, optionally after a counter
+	receive := func(request *signingpb.ListSignaturesRequest) []*signingpb.Signature {
+		stream, err := client.ListSignatures(ctx, request)
+		assert.NoError(err)
+		var signatures []*signingpb.Signature
+		for {
+			signature, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return signatures
+			}
+			assert.NoError(err)
+			signatures = append(signatures, signature)
+		}
+	}
+	signatures := receive(&signingpb.ListSignaturesRequest{DeviceId: device.GetId()})
+	assert.Len(signatures, 2)
+	assert.Equal(first.GetSignature(), signatures[0].GetSignature())
+	assert.Equal(first.GetSignedData(), signatures[0].GetSignedData())
+	assert.Equal(second.GetSignedData(), signatures[1].GetSignedData())
+	assert.Equal(int32(1), signatures[1].GetKeyVersion())
+
+	signatures = receive(&signingpb.ListSignaturesRequest{DeviceId: device.GetId(), AfterCounter: 1})
+	assert.Len(signatures, 1)
+	assert.Equal(int64(2), signatures[0].GetCounter())
+
+	stream, err := client.ListSignatures(ctx, &signingpb.ListSignaturesRequest{DeviceId: uuid.NewString()})
+	assert.NoError(err)
+	_, err = stream.Recv()
+	assert.Equal(codes.NotFound, status.Code(err))
+
+	// Test case 6: Delete the device
+	_, err = client.DeleteDevice(ctx, &signingpb.DeleteDeviceRequest{Id: device.GetId()})
+	assert.NoError(err)
+	_, err = client.GetDevice(ctx, &signingpb.GetDeviceRequest{Id: device.GetId()})
+	assert.Equal(codes.NotFound, status.Code(err))
+	_, err = client.DeleteDevice(ctx, &signingpb.DeleteDeviceRequest{Id: device.GetId()})
+	assert.NoError(err)
+}
+
+// TestSharedWithHTTP verifies that the gRPC and the HTTP API operate on the same devices
+func TestSharedWithHTTP(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	client, httpServer := startServer(t, nil)
+	httpAPI := httptest.NewServer(httpServer.Handler())
+	defer httpAPI.Close()
+
+	device, err := client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_ECC})
+	assert.NoError(err)
+
+	request, err := http.NewRequest(http.MethodPut, httpAPI.URL+"/api/v0/device/"+device.GetId()+"/sign", strings.NewReader(`{"data":"http"}`))
+	assert.NoError(err)
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(err)
+	response.Body.Close()
+	assert.Equal(http.StatusOK, response.StatusCode)
+
+	signed, err := client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId(), Data: "grpc"})
+	assert.NoError(err)
+	assert.Equal(int64(2), signed.GetSignatureCounter())
+}
+
+// TestRateLimit verifies that calls take from the same buckets as HTTP requests
+func TestRateLimit(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	client, httpServer := startServer(t, []api.Option{api.WithRateLimits(api.RateLimits{
+		PerDevice: ratelimit.Rate{PerSecond: 0.001, Burst: 2},
+	})})
+	httpAPI := httptest.NewServer(httpServer.Handler())
+	defer httpAPI.Close()
+
+	device, err := client.CreateDevice(ctx, &signingpb.CreateDeviceRequest{SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_ECC})
+	assert.NoError(err)
+
+	request, err := http.NewRequest(http.MethodPut, httpAPI.URL+"/api/v0/device/"+device.GetId()+"/sign", strings.NewReader(`{"data":"http"}`))
+	assert.NoError(err)
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(err)
+	response.Body.Close()
+	assert.Equal(http.StatusOK, response.StatusCode)
+
+	_, err = client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId(), Data: "grpc"})
+	assert.NoError(err)
+
+	// Test case 1: The bucket of the device is exhausted across both transports, the header tells when to retry
+	var header metadata.MD
+	_, err = client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: device.GetId(), Data: "grpc"}, grpc.Header(&header))
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(header.Get("retry-after"))
+
+	// Test case 2: Unknown devices aren't limited but rejected by the handler
+	_, err = client.SignData(ctx, &signingpb.SignDataRequest{DeviceId: uuid.NewString(), Data: "grpc"})
+	assert.Equal(codes.NotFound, status.Code(err))
+}
+
+// TestAuthentication verifies that calls are authenticated and authorized like HTTP requests
+func TestAuthentication(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	client, httpServer := startServer(t, []api.Option{api.WithAuthentication(testBootstrapKey)}, WithAuthentication(testBootstrapKey))
+
+	withKey := func(secret string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+secret)
+	}
+	request := &signingpb.CreateDeviceRequest{SigningAlgorithm: signingpb.SigningAlgorithm_SIGNING_ALGORITHM_ECC}
+
+	// Test case 1: Calls without a valid key are rejected
+	_, err := client.CreateDevice(ctx, request)
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+	_, err = client.CreateDevice(withKey("sk_unknown"), request)
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+
+	// Test case 2: Valid keys are accepted
+	device, err := client.CreateDevice(withKey(testBootstrapKey), request)
+	assert.NoError(err)
+
+	// Test case 3: Streams are authenticated as well
+	stream, err := client.ListSignatures(ctx, &signingpb.ListSignaturesRequest{DeviceId: device.GetId()})
+	assert.NoError(err)
+	_, err = stream.Recv()
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+
+	// Test case 4: Unknown organizations are rejected
+	_, err = client.GetDevice(
+		metadata.AppendToOutgoingContext(withKey(testBootstrapKey), OrganizationMetadata, uuid.NewString()),
+		&signingpb.GetDeviceRequest{Id: device.GetId()},
+	)
+	assert.Equal(codes.NotFound, status.Code(err))
+
+	// Test case 5: Admin keys of an organization can't switch to another one
+	httpAPI := httptest.NewServer(httpServer.Handler())
+	defer httpAPI.Close()
+	post := func(path string, body string, header http.Header, out any) {
+		request, err := http.NewRequest(http.MethodPost, httpAPI.URL+path, strings.NewReader(body))
+		assert.NoError(err)
+		request.Header = header
+		request.Header.Set("Authorization", "Bearer "+testBootstrapKey)
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(err)
+		defer response.Body.Close()
+		assert.Equal(http.StatusCreated, response.StatusCode)
+		assert.NoError(json.NewDecoder(response.Body).Decode(out))
+	}
+	var organization struct {
+		Data struct{ Id string } `json:"data"`
+	}
+	post("/api/v0/organization", `{"name":"foo"}`, http.Header{}, &organization)
+	var key struct {
+		Data struct{ Secret string } `json:"data"`
+	}
+	post("/api/v0/api-key", `{"name":"admin","scopes":["admin"]}`, http.Header{api.OrganizationHeader: {organization.Data.Id}}, &key)
+
+	_, err = client.GetDevice(
+		metadata.AppendToOutgoingContext(withKey(key.Data.Secret), OrganizationMetadata, domain.DefaultOrganizationId.String()),
+		&signingpb.GetDeviceRequest{Id: device.GetId()},
+	)
+	assert.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+// TestRunShutdown verifies that the server stops gracefully once its context is canceled
+func TestRunShutdown(t *testing.T) {
+	assert := require.New(t)
+
+	storage := persistence.NewMemoryStorage()
+	locker := lock.NewMemoryLocker[uuid.UUID]()
+	httpServer := api.NewServer(storage, locker)
+	server := NewServer(storage, httpServer.DeviceManager(), locker, WithShutdownTimeout(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Run(ctx, "127.0.0.1:0")
+	}()
+	cancel()
+
+	select {
+	case err := <-stopped:
+		assert.NoError(err)
+	case <-time.After(5 * time.Second):
+		assert.Fail("server didn't stop")
+	}
+}
@@ -0,0 +1,964 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: signing.proto
+
+package signingpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SigningAlgorithm int32
+
+const (
+	SigningAlgorithm_SIGNING_ALGORITHM_UNSPECIFIED SigningAlgorithm = 0
+	SigningAlgorithm_SIGNING_ALGORITHM_ECC         SigningAlgorithm = 1
+	SigningAlgorithm_SIGNING_ALGORITHM_RSA         SigningAlgorithm = 2
+)
+
+// Enum value maps for SigningAlgorithm.
+var (
+	SigningAlgorithm_name = map[int32]string{
+		0: "SIGNING_ALGORITHM_UNSPECIFIED",
+		1: "SIGNING_ALGORITHM_ECC",
+		2: "SIGNING_ALGORITHM_RSA",
+	}
+	SigningAlgorithm_value = map[string]int32{
+		"SIGNING_ALGORITHM_UNSPECIFIED": 0,
+		"SIGNING_ALGORITHM_ECC":         1,
+		"SIGNING_ALGORITHM_RSA":         2,
+	}
+)
+
+func (x SigningAlgorithm) Enum() *SigningAlgorithm {
+	p := new(SigningAlgorithm)
+	*p = x
+	return p
+}
+
+func (x SigningAlgorithm) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SigningAlgorithm) Descriptor() protoreflect.EnumDescriptor {
+	return file_signing_proto_enumTypes[0].Descriptor()
+}
+
+func (SigningAlgorithm) Type() protoreflect.EnumType {
+	return &file_signing_proto_enumTypes[0]
+}
+
+func (x SigningAlgorithm) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SigningAlgorithm.Descriptor instead.
+func (SigningAlgorithm) EnumDescriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{0}
+}
+
+type DeviceStatus int32
+
+const (
+	DeviceStatus_DEVICE_STATUS_UNSPECIFIED DeviceStatus = 0
+	DeviceStatus_DEVICE_STATUS_ACTIVE      DeviceStatus = 1
+	DeviceStatus_DEVICE_STATUS_DISABLED    DeviceStatus = 2
+)
+
+// Enum value maps for DeviceStatus.
+var (
+	DeviceStatus_name = map[int32]string{
+		0: "DEVICE_STATUS_UNSPECIFIED",
+		1: "DEVICE_STATUS_ACTIVE",
+		2: "DEVICE_STATUS_DISABLED",
+	}
+	DeviceStatus_value = map[string]int32{
+		"DEVICE_STATUS_UNSPECIFIED": 0,
+		"DEVICE_STATUS_ACTIVE":      1,
+		"DEVICE_STATUS_DISABLED":    2,
+	}
+)
+
+func (x DeviceStatus) Enum() *DeviceStatus {
+	p := new(DeviceStatus)
+	*p = x
+	return p
+}
+
+func (x DeviceStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeviceStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_signing_proto_enumTypes[1].Descriptor()
+}
+
+func (DeviceStatus) Type() protoreflect.EnumType {
+	return &file_signing_proto_enumTypes[1]
+}
+
+func (x DeviceStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeviceStatus.Descriptor instead.
+func (DeviceStatus) EnumDescriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{1}
+}
+
+type Device struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SigningAlgorithm SigningAlgorithm       `protobuf:"varint,2,opt,name=signing_algorithm,json=signingAlgorithm,proto3,enum=signing.v0.SigningAlgorithm" json:"signing_algorithm,omitempty"`
+	Label            *string                `protobuf:"bytes,3,opt,name=label,proto3,oneof" json:"label,omitempty"`
+	Status           DeviceStatus           `protobuf:"varint,4,opt,name=status,proto3,enum=signing.v0.DeviceStatus" json:"status,omitempty"`
+	Metadata         map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tags             []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	// public_keys contains all keys of the device, the last one is used for new signatures
+	PublicKeys       []string               `protobuf:"bytes,7,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	SignatureCounter int64                  `protobuf:"varint,8,opt,name=signature_counter,json=signatureCounter,proto3" json:"signature_counter,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_signing_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Device) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Device) GetSigningAlgorithm() SigningAlgorithm {
+	if x != nil {
+		return x.SigningAlgorithm
+	}
+	return SigningAlgorithm_SIGNING_ALGORITHM_UNSPECIFIED
+}
+
+func (x *Device) GetLabel() string {
+	if x != nil && x.Label != nil {
+		return *x.Label
+	}
+	return ""
+}
+
+func (x *Device) GetStatus() DeviceStatus {
+	if x != nil {
+		return x.Status
+	}
+	return DeviceStatus_DEVICE_STATUS_UNSPECIFIED
+}
+
+func (x *Device) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Device) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Device) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
+func (x *Device) GetSignatureCounter() int64 {
+	if x != nil {
+		return x.SignatureCounter
+	}
+	return 0
+}
+
+func (x *Device) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateDeviceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is generated if not given
+	Id               *string           `protobuf:"bytes,1,opt,name=id,proto3,oneof" json:"id,omitempty"`
+	SigningAlgorithm SigningAlgorithm  `protobuf:"varint,2,opt,name=signing_algorithm,json=signingAlgorithm,proto3,enum=signing.v0.SigningAlgorithm" json:"signing_algorithm,omitempty"`
+	Label            *string           `protobuf:"bytes,3,opt,name=label,proto3,oneof" json:"label,omitempty"`
+	Metadata         map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tags             []string          `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CreateDeviceRequest) Reset() {
+	*x = CreateDeviceRequest{}
+	mi := &file_signing_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceRequest) ProtoMessage() {}
+
+func (x *CreateDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateDeviceRequest) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetSigningAlgorithm() SigningAlgorithm {
+	if x != nil {
+		return x.SigningAlgorithm
+	}
+	return SigningAlgorithm_SIGNING_ALGORITHM_UNSPECIFIED
+}
+
+func (x *CreateDeviceRequest) GetLabel() string {
+	if x != nil && x.Label != nil {
+		return *x.Label
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *CreateDeviceRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type GetDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceRequest) Reset() {
+	*x = GetDeviceRequest{}
+	mi := &file_signing_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceRequest) ProtoMessage() {}
+
+func (x *GetDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetDeviceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListDevicesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// devices have to carry all tags and metadata entries to match
+	Tags          []string          `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata      map[string]string `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesRequest) Reset() {
+	*x = ListDevicesRequest{}
+	mi := &file_signing_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesRequest) ProtoMessage() {}
+
+func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListDevicesRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListDevicesRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ListDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*Device              `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_signing_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesResponse) ProtoMessage() {}
+
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListDevicesResponse) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type DeleteDeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceRequest) Reset() {
+	*x = DeleteDeviceRequest{}
+	mi := &file_signing_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceRequest) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteDeviceRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteDeviceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceResponse) Reset() {
+	*x = DeleteDeviceResponse{}
+	mi := &file_signing_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceResponse) ProtoMessage() {}
+
+func (x *DeleteDeviceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceResponse) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{6}
+}
+
+type SignDataRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Data     string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// idempotency_key makes retries return the original signature, like the Idempotency-Key header
+	IdempotencyKey *string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3,oneof" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SignDataRequest) Reset() {
+	*x = SignDataRequest{}
+	mi := &file_signing_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignDataRequest) ProtoMessage() {}
+
+func (x *SignDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignDataRequest.ProtoReflect.Descriptor instead.
+func (*SignDataRequest) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SignDataRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SignDataRequest) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *SignDataRequest) GetIdempotencyKey() string {
+	if x != nil && x.IdempotencyKey != nil {
+		return *x.IdempotencyKey
+	}
+	return ""
+}
+
+type SignDataResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Signature string                 `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	// signed_data is formatted as "<counter>_<last signature>_<data>"
+	SignedData       string `protobuf:"bytes,2,opt,name=signed_data,json=signedData,proto3" json:"signed_data,omitempty"`
+	SignatureCounter int64  `protobuf:"varint,3,opt,name=signature_counter,json=signatureCounter,proto3" json:"signature_counter,omitempty"`
+	// replayed is set when the result was stored by an earlier request with the same idempotency key
+	Replayed      bool `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignDataResponse) Reset() {
+	*x = SignDataResponse{}
+	mi := &file_signing_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignDataResponse) ProtoMessage() {}
+
+func (x *SignDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignDataResponse.ProtoReflect.Descriptor instead.
+func (*SignDataResponse) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SignDataResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *SignDataResponse) GetSignedData() string {
+	if x != nil {
+		return x.SignedData
+	}
+	return ""
+}
+
+func (x *SignDataResponse) GetSignatureCounter() int64 {
+	if x != nil {
+		return x.SignatureCounter
+	}
+	return 0
+}
+
+func (x *SignDataResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+type ListSignaturesRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// after_counter skips signatures up to and including the counter, e.g. to resume a stream
+	AfterCounter  int64 `protobuf:"varint,2,opt,name=after_counter,json=afterCounter,proto3" json:"after_counter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSignaturesRequest) Reset() {
+	*x = ListSignaturesRequest{}
+	mi := &file_signing_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSignaturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSignaturesRequest) ProtoMessage() {}
+
+func (x *ListSignaturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSignaturesRequest.ProtoReflect.Descriptor instead.
+func (*ListSignaturesRequest) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListSignaturesRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ListSignaturesRequest) GetAfterCounter() int64 {
+	if x != nil {
+		return x.AfterCounter
+	}
+	return 0
+}
+
+type Signature struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Counter    int64                  `protobuf:"varint,1,opt,name=counter,proto3" json:"counter,omitempty"`
+	Signature  string                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	SignedData string                 `protobuf:"bytes,3,opt,name=signed_data,json=signedData,proto3" json:"signed_data,omitempty"`
+	// key_version is the index of the public key of the device starting at 1
+	KeyVersion    int32                  `protobuf:"varint,4,opt,name=key_version,json=keyVersion,proto3" json:"key_version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signature) Reset() {
+	*x = Signature{}
+	mi := &file_signing_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signature) ProtoMessage() {}
+
+func (x *Signature) ProtoReflect() protoreflect.Message {
+	mi := &file_signing_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signature.ProtoReflect.Descriptor instead.
+func (*Signature) Descriptor() ([]byte, []int) {
+	return file_signing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Signature) GetCounter() int64 {
+	if x != nil {
+		return x.Counter
+	}
+	return 0
+}
+
+func (x *Signature) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *Signature) GetSignedData() string {
+	if x != nil {
+		return x.SignedData
+	}
+	return ""
+}
+
+func (x *Signature) GetKeyVersion() int32 {
+	if x != nil {
+		return x.KeyVersion
+	}
+	return 0
+}
+
+func (x *Signature) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_signing_proto protoreflect.FileDescriptor
+
+const file_signing_proto_rawDesc = "" +
+	"\n" +
+	"\rsigning.proto\x12\n" +
+	"signing.v0\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd2\x03\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12I\n" +
+	"\x11signing_algorithm\x18\x02 \x01(\x0e2\x1c.signing.v0.SigningAlgorithmR\x10signingAlgorithm\x12\x19\n" +
+	"\x05label\x18\x03 \x01(\tH\x00R\x05label\x88\x01\x01\x120\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x18.signing.v0.DeviceStatusR\x06status\x12<\n" +
+	"\bmetadata\x18\x05 \x03(\v2 .signing.v0.Device.MetadataEntryR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12\x1f\n" +
+	"\vpublic_keys\x18\a \x03(\tR\n" +
+	"publicKeys\x12+\n" +
+	"\x11signature_counter\x18\b \x01(\x03R\x10signatureCounter\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\b\n" +
+	"\x06_label\"\xbd\x02\n" +
+	"\x13CreateDeviceRequest\x12\x13\n" +
+	"\x02id\x18\x01 \x01(\tH\x00R\x02id\x88\x01\x01\x12I\n" +
+	"\x11signing_algorithm\x18\x02 \x01(\x0e2\x1c.signing.v0.SigningAlgorithmR\x10signingAlgorithm\x12\x19\n" +
+	"\x05label\x18\x03 \x01(\tH\x01R\x05label\x88\x01\x01\x12I\n" +
+	"\bmetadata\x18\x04 \x03(\v2-.signing.v0.CreateDeviceRequest.MetadataEntryR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x05\n" +
+	"\x03_idB\b\n" +
+	"\x06_label\"\"\n" +
+	"\x10GetDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xaf\x01\n" +
+	"\x12ListDevicesRequest\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\x12H\n" +
+	"\bmetadata\x18\x02 \x03(\v2,.signing.v0.ListDevicesRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
+	"\x13ListDevicesResponse\x12,\n" +
+	"\adevices\x18\x01 \x03(\v2\x12.signing.v0.DeviceR\adevices\"%\n" +
+	"\x13DeleteDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x16\n" +
+	"\x14DeleteDeviceResponse\"\x84\x01\n" +
+	"\x0fSignDataRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\x12,\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tH\x00R\x0eidempotencyKey\x88\x01\x01B\x12\n" +
+	"\x10_idempotency_key\"\x9a\x01\n" +
+	"\x10SignDataResponse\x12\x1c\n" +
+	"\tsignature\x18\x01 \x01(\tR\tsignature\x12\x1f\n" +
+	"\vsigned_data\x18\x02 \x01(\tR\n" +
+	"signedData\x12+\n" +
+	"\x11signature_counter\x18\x03 \x01(\x03R\x10signatureCounter\x12\x1a\n" +
+	"\breplayed\x18\x04 \x01(\bR\breplayed\"Y\n" +
+	"\x15ListSignaturesRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12#\n" +
+	"\rafter_counter\x18\x02 \x01(\x03R\fafterCounter\"\xc0\x01\n" +
+	"\tSignature\x12\x18\n" +
+	"\acounter\x18\x01 \x01(\x03R\acounter\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\tR\tsignature\x12\x1f\n" +
+	"\vsigned_data\x18\x03 \x01(\tR\n" +
+	"signedData\x12\x1f\n" +
+	"\vkey_version\x18\x04 \x01(\x05R\n" +
+	"keyVersion\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt*k\n" +
+	"\x10SigningAlgorithm\x12!\n" +
+	"\x1dSIGNING_ALGORITHM_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15SIGNING_ALGORITHM_ECC\x10\x01\x12\x19\n" +
+	"\x15SIGNING_ALGORITHM_RSA\x10\x02*c\n" +
+	"\fDeviceStatus\x12\x1d\n" +
+	"\x19DEVICE_STATUS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14DEVICE_STATUS_ACTIVE\x10\x01\x12\x1a\n" +
+	"\x16DEVICE_STATUS_DISABLED\x10\x022\xcc\x03\n" +
+	"\x0eSigningService\x12C\n" +
+	"\fCreateDevice\x12\x1f.signing.v0.CreateDeviceRequest\x1a\x12.signing.v0.Device\x12=\n" +
+	"\tGetDevice\x12\x1c.signing.v0.GetDeviceRequest\x1a\x12.signing.v0.Device\x12N\n" +
+	"\vListDevices\x12\x1e.signing.v0.ListDevicesRequest\x1a\x1f.signing.v0.ListDevicesResponse\x12Q\n" +
+	"\fDeleteDevice\x12\x1f.signing.v0.DeleteDeviceRequest\x1a .signing.v0.DeleteDeviceResponse\x12E\n" +
+	"\bSignData\x12\x1b.signing.v0.SignDataRequest\x1a\x1c.signing.v0.SignDataResponse\x12L\n" +
+	"\x0eListSignatures\x12!.signing.v0.ListSignaturesRequest\x1a\x15.signing.v0.Signature0\x01BRZPgithub.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi/signingpbb\x06proto3"
+
+var (
+	file_signing_proto_rawDescOnce sync.Once
+	file_signing_proto_rawDescData []byte
+)
+
+func file_signing_proto_rawDescGZIP() []byte {
+	file_signing_proto_rawDescOnce.Do(func() {
+		file_signing_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_signing_proto_rawDesc), len(file_signing_proto_rawDesc)))
+	})
+	return file_signing_proto_rawDescData
+}
+
+var file_signing_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_signing_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_signing_proto_goTypes = []any{
+	(SigningAlgorithm)(0),         // 0: signing.v0.SigningAlgorithm
+	(DeviceStatus)(0),             // 1: signing.v0.DeviceStatus
+	(*Device)(nil),                // 2: signing.v0.Device
+	(*CreateDeviceRequest)(nil),   // 3: signing.v0.CreateDeviceRequest
+	(*GetDeviceRequest)(nil),      // 4: signing.v0.GetDeviceRequest
+	(*ListDevicesRequest)(nil),    // 5: signing.v0.ListDevicesRequest
+	(*ListDevicesResponse)(nil),   // 6: signing.v0.ListDevicesResponse
+	(*DeleteDeviceRequest)(nil),   // 7: signing.v0.DeleteDeviceRequest
+	(*DeleteDeviceResponse)(nil),  // 8: signing.v0.DeleteDeviceResponse
+	(*SignDataRequest)(nil),       // 9: signing.v0.SignDataRequest
+	(*SignDataResponse)(nil),      // 10: signing.v0.SignDataResponse
+	(*ListSignaturesRequest)(nil), // 11: signing.v0.ListSignaturesRequest
+	(*Signature)(nil),             // 12: signing.v0.Signature
+	nil,                           // 13: signing.v0.Device.MetadataEntry
+	nil,                           // 14: signing.v0.CreateDeviceRequest.MetadataEntry
+	nil,                           // 15: signing.v0.ListDevicesRequest.MetadataEntry
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_signing_proto_depIdxs = []int32{
+	0,  // 0: signing.v0.Device.signing_algorithm:type_name -> signing.v0.SigningAlgorithm
+	1,  // 1: signing.v0.Device.status:type_name -> signing.v0.DeviceStatus
+	13, // 2: signing.v0.Device.metadata:type_name -> signing.v0.Device.MetadataEntry
+	16, // 3: signing.v0.Device.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: signing.v0.CreateDeviceRequest.signing_algorithm:type_name -> signing.v0.SigningAlgorithm
+	14, // 5: signing.v0.CreateDeviceRequest.metadata:type_name -> signing.v0.CreateDeviceRequest.MetadataEntry
+	15, // 6: signing.v0.ListDevicesRequest.metadata:type_name -> signing.v0.ListDevicesRequest.MetadataEntry
+	2,  // 7: signing.v0.ListDevicesResponse.devices:type_name -> signing.v0.Device
+	16, // 8: signing.v0.Signature.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 9: signing.v0.SigningService.CreateDevice:input_type -> signing.v0.CreateDeviceRequest
+	4,  // 10: signing.v0.SigningService.GetDevice:input_type -> signing.v0.GetDeviceRequest
+	5,  // 11: signing.v0.SigningService.ListDevices:input_type -> signing.v0.ListDevicesRequest
+	7,  // 12: signing.v0.SigningService.DeleteDevice:input_type -> signing.v0.DeleteDeviceRequest
+	9,  // 13: signing.v0.SigningService.SignData:input_type -> signing.v0.SignDataRequest
+	11, // 14: signing.v0.SigningService.ListSignatures:input_type -> signing.v0.ListSignaturesRequest
+	2,  // 15: signing.v0.SigningService.CreateDevice:output_type -> signing.v0.Device
+	2,  // 16: signing.v0.SigningService.GetDevice:output_type -> signing.v0.Device
+	6,  // 17: signing.v0.SigningService.ListDevices:output_type -> signing.v0.ListDevicesResponse
+	8,  // 18: signing.v0.SigningService.DeleteDevice:output_type -> signing.v0.DeleteDeviceResponse
+	10, // 19: signing.v0.SigningService.SignData:output_type -> signing.v0.SignDataResponse
+	12, // 20: signing.v0.SigningService.ListSignatures:output_type -> signing.v0.Signature
+	15, // [15:21] is the sub-list for method output_type
+	9,  // [9:15] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_signing_proto_init() }
+func file_signing_proto_init() {
+	if File_signing_proto != nil {
+		return
+	}
+	file_signing_proto_msgTypes[0].OneofWrappers = []any{}
+	file_signing_proto_msgTypes[1].OneofWrappers = []any{}
+	file_signing_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_signing_proto_rawDesc), len(file_signing_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_signing_proto_goTypes,
+		DependencyIndexes: file_signing_proto_depIdxs,
+		EnumInfos:         file_signing_proto_enumTypes,
+		MessageInfos:      file_signing_proto_msgTypes,
+	}.Build()
+	File_signing_proto = out.File
+	file_signing_proto_goTypes = nil
+	file_signing_proto_depIdxs = nil
+}
@@ -0,0 +1,335 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: signing.proto
+
+package signingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SigningService_CreateDevice_FullMethodName   = "/signing.v0.SigningService/CreateDevice"
+	SigningService_GetDevice_FullMethodName      = "/signing.v0.SigningService/GetDevice"
+	SigningService_ListDevices_FullMethodName    = "/signing.v0.SigningService/ListDevices"
+	SigningService_DeleteDevice_FullMethodName   = "/signing.v0.SigningService/DeleteDevice"
+	SigningService_SignData_FullMethodName       = "/signing.v0.SigningService/SignData"
+	SigningService_ListSignatures_FullMethodName = "/signing.v0.SigningService/ListSignatures"
+)
+
+// SigningServiceClient is the client API for SigningService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SigningService manages signature devices and signs data with them.
+// Requests are authenticated with an API key sent as "authorization: Bearer <key>" metadata,
+// "x-organization-id" metadata selects the organization like the header of the HTTP API.
+type SigningServiceClient interface {
+	// CreateDevice creates a new device with a fresh key pair.
+	CreateDevice(ctx context.Context, in *CreateDeviceRequest, opts ...grpc.CallOption) (*Device, error)
+	// GetDevice returns a single device.
+	GetDevice(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*Device, error)
+	// ListDevices returns all devices matching the filter.
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	// DeleteDevice deletes a device, deleting a missing device succeeds.
+	DeleteDevice(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*DeleteDeviceResponse, error)
+	// SignData signs data with a device and increments its signature counter.
+	SignData(ctx context.Context, in *SignDataRequest, opts ...grpc.CallOption) (*SignDataResponse, error)
+	// ListSignatures streams the signature log of a device, oldest first.
+	ListSignatures(ctx context.Context, in *ListSignaturesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Signature], error)
+}
+
+type signingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSigningServiceClient(cc grpc.ClientConnInterface) SigningServiceClient {
+	return &signingServiceClient{cc}
+}
+
+func (c *signingServiceClient) CreateDevice(ctx context.Context, in *CreateDeviceRequest, opts ...grpc.CallOption) (*Device, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Device)
+	err := c.cc.Invoke(ctx, SigningService_CreateDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingServiceClient) GetDevice(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*Device, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Device)
+	err := c.cc.Invoke(ctx, SigningService_GetDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingServiceClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, SigningService_ListDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingServiceClient) DeleteDevice(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*DeleteDeviceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDeviceResponse)
+	err := c.cc.Invoke(ctx, SigningService_DeleteDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingServiceClient) SignData(ctx context.Context, in *SignDataRequest, opts ...grpc.CallOption) (*SignDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignDataResponse)
+	err := c.cc.Invoke(ctx, SigningService_SignData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *signingServiceClient) ListSignatures(ctx context.Context, in *ListSignaturesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Signature], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SigningService_ServiceDesc.Streams[0], SigningService_ListSignatures_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListSignaturesRequest, Signature]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SigningService_ListSignaturesClient = grpc.ServerStreamingClient[Signature]
+
+// SigningServiceServer is the server API for SigningService service.
+// All implementations must embed UnimplementedSigningServiceServer
+// for forward compatibility.
+//
+// SigningService manages signature devices and signs data with them.
+// Requests are authenticated with an API key sent as "authorization: Bearer <key>" metadata,
+// "x-organization-id" metadata selects the organization like the header of the HTTP API.
+type SigningServiceServer interface {
+	// CreateDevice creates a new device with a fresh key pair.
+	CreateDevice(context.Context, *CreateDeviceRequest) (*Device, error)
+	// GetDevice returns a single device.
+	GetDevice(context.Context, *GetDeviceRequest) (*Device, error)
+	// ListDevices returns all devices matching the filter.
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	// DeleteDevice deletes a device, deleting a missing device succeeds.
+	DeleteDevice(context.Context, *DeleteDeviceRequest) (*DeleteDeviceResponse, error)
+	// SignData signs data with a device and increments its signature counter.
+	SignData(context.Context, *SignDataRequest) (*SignDataResponse, error)
+	// ListSignatures streams the signature log of a device, oldest first.
+	ListSignatures(*ListSignaturesRequest, grpc.ServerStreamingServer[Signature]) error
+	mustEmbedUnimplementedSigningServiceServer()
+}
+
+// UnimplementedSigningServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSigningServiceServer struct{}
+
+func (UnimplementedSigningServiceServer) CreateDevice(context.Context, *CreateDeviceRequest) (*Device, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDevice not implemented")
+}
+func (UnimplementedSigningServiceServer) GetDevice(context.Context, *GetDeviceRequest) (*Device, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDevice not implemented")
+}
+func (UnimplementedSigningServiceServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedSigningServiceServer) DeleteDevice(context.Context, *DeleteDeviceRequest) (*DeleteDeviceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDevice not implemented")
+}
+func (UnimplementedSigningServiceServer) SignData(context.Context, *SignDataRequest) (*SignDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignData not implemented")
+}
+func (UnimplementedSigningServiceServer) ListSignatures(*ListSignaturesRequest, grpc.ServerStreamingServer[Signature]) error {
+	return status.Errorf(codes.Unimplemented, "method ListSignatures not implemented")
+}
+func (UnimplementedSigningServiceServer) mustEmbedUnimplementedSigningServiceServer() {}
+func (UnimplementedSigningServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeSigningServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SigningServiceServer will
+// result in compilation errors.
+type UnsafeSigningServiceServer interface {
+	mustEmbedUnimplementedSigningServiceServer()
+}
+
+func RegisterSigningServiceServer(s grpc.ServiceRegistrar, srv SigningServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSigningServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SigningService_ServiceDesc, srv)
+}
+
+func _SigningService_CreateDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServiceServer).CreateDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SigningService_CreateDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServiceServer).CreateDevice(ctx, req.(*CreateDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SigningService_GetDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServiceServer).GetDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SigningService_GetDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServiceServer).GetDevice(ctx, req.(*GetDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SigningService_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServiceServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SigningService_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServiceServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SigningService_DeleteDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServiceServer).DeleteDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SigningService_DeleteDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServiceServer).DeleteDevice(ctx, req.(*DeleteDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SigningService_SignData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningServiceServer).SignData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SigningService_SignData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningServiceServer).SignData(ctx, req.(*SignDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SigningService_ListSignatures_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListSignaturesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SigningServiceServer).ListSignatures(m, &grpc.GenericServerStream[ListSignaturesRequest, Signature]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SigningService_ListSignaturesServer = grpc.ServerStreamingServer[Signature]
+
+// SigningService_ServiceDesc is the grpc.ServiceDesc for SigningService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SigningService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "signing.v0.SigningService",
+	HandlerType: (*SigningServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateDevice",
+			Handler:    _SigningService_CreateDevice_Handler,
+		},
+		{
+			MethodName: "GetDevice",
+			Handler:    _SigningService_GetDevice_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _SigningService_ListDevices_Handler,
+		},
+		{
+			MethodName: "DeleteDevice",
+			Handler:    _SigningService_DeleteDevice_Handler,
+		},
+		{
+			MethodName: "SignData",
+			Handler:    _SigningService_SignData_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListSignatures",
+			Handler:       _SigningService_ListSignatures_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "signing.proto",
+}
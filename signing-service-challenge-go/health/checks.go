@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/crypto"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+)
+
+// Storage checks that the storage is reachable
+func Storage(storage persistence.Storage) CheckFunc {
+	return storage.Health
+}
+
+// Locker checks that locks can be acquired, using a lock id which isn't used otherwise
+func Locker[I comparable](locker lock.Locker[I], probe I) CheckFunc {
+	return func(ctx context.Context) error {
+		acquired, err := locker.Acquire(ctx, probe)
+		if err != nil {
+			return err
+		}
+		acquired.Unlock()
+		return nil
+	}
+}
+
+// probeData is signed by the [Keys] check
+var probeData = []byte("health check")
+
+// Keys checks that keys go through the encoding they are stored with, and that they sign and verify data.
+// The key pair is generated once, so the check is cheap enough for frequent probes.
+func Keys() CheckFunc {
+	encoded := sync.OnceValues(func() ([]byte, error) {
+		keyPair, err := crypto.GenerateECCKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		_, privateKey, err := crypto.EncodeKeyPair(keyPair)
+		return privateKey, err
+	})
+
+	return func(context.Context) error {
+		privateKey, err := encoded()
+		if err != nil {
+			return err
+		}
+		keyPair := new(crypto.ECCKeyPair)
+		if err := crypto.DecodePrivateKey(privateKey, keyPair); err != nil {
+			return err
+		}
+		signature, err := keyPair.Sign(probeData)
+		if err != nil {
+			return err
+		}
+		publicKey, err := crypto.EncodePublicKey(keyPair)
+		if err != nil {
+			return err
+		}
+		verifier, err := crypto.DecodePublicKey(publicKey)
+		if err != nil {
+			return err
+		}
+		return verifier.Verify(probeData, signature)
+	}
+}
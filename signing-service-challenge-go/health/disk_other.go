@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || freebsd)
+
+package health
+
+import (
+	"context"
+	"errors"
+)
+
+// DiskSpace checks the free space of the file system containing the path.
+// It isn't supported on this platform and always degrades the service.
+func DiskSpace(path string, minFree uint64) CheckFunc {
+	return func(context.Context) error {
+		return Degraded(errors.New("checking disk space is not supported on this platform"))
+	}
+}
@@ -0,0 +1,29 @@
+//go:build linux || darwin || freebsd
+
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// DiskSpace checks the free space of the file system containing the path.
+// Less than minFree bytes degrade the service, a full file system fails it.
+func DiskSpace(path string, minFree uint64) CheckFunc {
+	return func(context.Context) error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return err
+		}
+		free := uint64(stat.Bavail) * uint64(stat.Bsize)
+		if free == 0 {
+			return errors.New("no space left on " + path)
+		}
+		if free < minFree {
+			return Degraded(fmt.Errorf("only %d bytes left on %s, expected at least %d", free, path, minFree))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+// Package health runs checks of the dependencies of the service, e.g. for readiness probes.
+package health
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a check or of all checks, named like in the health check response format for HTTP APIs
+type Status string
+
+const (
+	// StatusPass means the dependency works
+	StatusPass Status = "pass"
+	// StatusWarn means the service is degraded, it still works but e.g. without a non-critical dependency
+	StatusWarn Status = "warn"
+	// StatusFail means the service can't serve requests
+	StatusFail Status = "fail"
+)
+
+// severity orders the statuses, the report has the status of the worst check
+func (s Status) severity() int {
+	return slices.Index([]Status{StatusPass, StatusWarn, StatusFail}, s)
+}
+
+// DefaultTimeout bounds a check unless it was registered with [WithTimeout]
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc checks a dependency, it returns an error if the dependency doesn't work
+type CheckFunc func(ctx context.Context) error
+
+type degradedError struct {
+	err error
+}
+
+func (e degradedError) Error() string {
+	return e.err.Error()
+}
+
+func (e degradedError) Unwrap() error {
+	return e.err
+}
+
+// Degraded marks the error of a check as degraded, the service still works but needs attention,
+// e.g. because the disk is almost full
+func Degraded(err error) error {
+	return degradedError{err: err}
+}
+
+type check struct {
+	name     string
+	run      CheckFunc
+	critical bool
+	timeout  time.Duration
+}
+
+// Option configures a check
+type Option func(*check)
+
+// NonCritical makes failures of the check degrade the service instead of failing it
+func NonCritical() Option {
+	return func(c *check) {
+		c.critical = false
+	}
+}
+
+// WithTimeout sets how long the check may take before it fails
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// Registry holds the checks of the service, checks can be registered at any time
+type Registry struct {
+	mu     sync.RWMutex
+	checks []check
+}
+
+// NewRegistry creates a registry without checks
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check, replacing the check of the same name. Checks are critical unless registered with [NonCritical].
+func (r *Registry) Register(name string, run CheckFunc, options ...Option) {
+	c := check{name: name, run: run, critical: true, timeout: DefaultTimeout}
+	for _, option := range options {
+		option(&c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = slices.DeleteFunc(r.checks, func(existing check) bool { return existing.name == name })
+	r.checks = append(r.checks, c)
+}
+
+// Result is the outcome of a single check
+type Result struct {
+	Status   Status
+	Critical bool
+	Latency  time.Duration
+	// Error describes why the check didn't pass, it is empty for passing checks
+	Error string
+}
+
+// Report is the outcome of all checks, its status is the status of the worst check
+type Report struct {
+	Status Status
+	Checks map[string]Result
+}
+
+// Run runs all checks concurrently, each bounded by its timeout
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := slices.Clone(r.checks)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			results[i] = c.execute(ctx)
+		})
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusPass, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status.severity() > report.Status.severity() {
+			report.Status = results[i].Status
+		}
+	}
+	return report
+}
+
+func (c check) execute(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := c.run(ctx)
+	result := Result{Status: StatusPass, Critical: c.critical, Latency: time.Since(started)}
+	if err == nil {
+		return result
+	}
+
+	result.Error = err.Error()
+	var degraded degradedError
+	if c.critical && !errors.As(err, &degraded) {
+		result.Status = StatusFail
+	} else {
+		result.Status = StatusWarn
+	}
+	return result
+}
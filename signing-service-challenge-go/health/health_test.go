@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/stretchr/testify/require"
+)
+
+func pass(context.Context) error { return nil }
+
+func fail(context.Context) error { return errors.New("broken") }
+
+func TestRegistry(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	// Test case 1: Without checks the service passes
+	registry := NewRegistry()
+	assert.Equal(Report{Status: StatusPass, Checks: map[string]Result{}}, registry.Run(ctx))
+
+	// Test case 2: A failing non-critical check degrades the service
+	registry.Register("storage", pass)
+	registry.Register("disk", fail, NonCritical())
+	report := registry.Run(ctx)
+	assert.Equal(StatusWarn, report.Status)
+	assert.Equal(StatusPass, report.Checks["storage"].Status)
+	assert.True(report.Checks["storage"].Critical)
+	assert.Equal(StatusWarn, report.Checks["disk"].Status)
+	assert.Equal("broken", report.Checks["disk"].Error)
+
+	// Test case 3: A degraded critical check degrades the service as well
+	registry.Register("disk", func(context.Context) error { return Degraded(errors.New("almost full")) })
+	report = registry.Run(ctx)
+	assert.Equal(StatusWarn, report.Status)
+	assert.Equal("almost full", report.Checks["disk"].Error)
+	assert.Len(report.Checks, 2)
+
+	// Test case 4: A failing critical check fails the service
+	registry.Register("keys", fail)
+	report = registry.Run(ctx)
+	assert.Equal(StatusFail, report.Status)
+	assert.Equal(StatusFail, report.Checks["keys"].Status)
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	assert := require.New(t)
+
+	registry := NewRegistry()
+	registry.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	report := registry.Run(context.Background())
+	assert.Equal(StatusFail, report.Status)
+	assert.Equal(context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+	assert.GreaterOrEqual(report.Checks["slow"].Latency, 10*time.Millisecond)
+}
+
+func TestChecks(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	assert.NoError(Storage(persistence.NewMemoryStorage())(ctx))
+	assert.NoError(Keys()(ctx))
+	assert.NoError(DiskSpace(t.TempDir(), 0)(ctx))
+
+	// the probe is released again, so checks don't block each other
+	locker := lock.NewMemoryLocker[int]()
+	assert.NoError(Locker(locker, 0)(ctx))
+	assert.NoError(Locker(locker, 0)(ctx))
+}
@@ -0,0 +1,187 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaseStore keeps the leases shared by all replicas of the service, e.g. in Redis.
+// A lease is held by one owner until it is released or its TTL expires without renewal.
+type LeaseStore interface {
+	// Acquire takes the lease of the key for the owner unless another owner holds it.
+	// The fencing token increases with every acquisition of the key, it is 0 if the lease wasn't acquired.
+	Acquire(ctx context.Context, key string, owner string, ttl time.Duration) (token uint64, err error)
+	// Renew extends the lease by the TTL, it returns [ErrLeaseLost] unless the owner still holds it.
+	Renew(ctx context.Context, key string, owner string, ttl time.Duration) error
+	// Release frees the lease if the owner still holds it.
+	Release(ctx context.Context, key string, owner string) error
+}
+
+// ErrLeaseLost is returned when a lease expired or was taken over by another owner
+var ErrLeaseLost = errors.New("lease lost")
+
+const (
+	// DefaultLeaseTTL bounds how long a lock of a crashed replica blocks the others
+	DefaultLeaseTTL = 10 * time.Second
+	// DefaultLeaseRetry is how often a held lease is tried again
+	DefaultLeaseRetry = 25 * time.Millisecond
+	// storeTimeout bounds releasing leases, which runs without the context of the caller
+	storeTimeout = 2 * time.Second
+	// minLeaseTTL keeps the heartbeat and the TTL in milliseconds, e.g. of Redis, above zero
+	minLeaseTTL = 3 * time.Millisecond
+)
+
+type leaseConfig struct {
+	ttl       time.Duration
+	heartbeat time.Duration
+	retry     time.Duration
+}
+
+// LeaseOption configures the leases of a lease locker
+type LeaseOption func(*leaseConfig)
+
+// WithLeaseTTL sets how long a lease is held without renewal, the heartbeat renews it at a third of the TTL.
+// TTLs below 3ms are raised to it, they would round to zero otherwise.
+func WithLeaseTTL(ttl time.Duration) LeaseOption {
+	return func(c *leaseConfig) {
+		ttl = max(ttl, minLeaseTTL)
+		c.ttl = ttl
+		c.heartbeat = ttl / 3
+	}
+}
+
+// WithLeaseRetry sets how often a lease held by another replica is tried again.
+func WithLeaseRetry(retry time.Duration) LeaseOption {
+	return func(c *leaseConfig) {
+		c.retry = retry
+	}
+}
+
+type leaseLocker[I comparable] struct {
+	store  LeaseStore
+	config leaseConfig
+	// local serializes the callers of this replica, so only one of them polls the store for each ID
+	local Locker[I]
+}
+
+// NewLeaseLocker creates a locker serializing callers across replicas with leases of the store.
+// Held leases are renewed by a heartbeat until they are unlocked, the locks are [Lease]s with a fencing token.
+// The held locks of this replica can be inspected, see [Inspector].
+func NewLeaseLocker[I comparable](store LeaseStore, options ...LeaseOption) Locker[I] {
+	config := leaseConfig{ttl: DefaultLeaseTTL, heartbeat: DefaultLeaseTTL / 3, retry: DefaultLeaseRetry}
+	for _, option := range options {
+		option(&config)
+	}
+	return &leaseLocker[I]{
+		store:  store,
+		config: config,
+		local:  NewMemoryLocker[I](),
+	}
+}
+
+func (l *leaseLocker[I]) Acquire(ctx context.Context, id I) (Lock, error) {
+	local, err := l.local.Acquire(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprint(id)
+	// every acquisition has its own owner, so a lease can't be renewed or released by a later holder
+	owner := uuid.NewString()
+	ticker := time.NewTicker(l.config.retry)
+	defer ticker.Stop()
+	for {
+		token, err := l.store.Acquire(ctx, key, owner, l.config.ttl)
+		if err != nil {
+			local.Unlock()
+			return nil, err
+		}
+		if token > 0 {
+			return l.hold(key, owner, token, local), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			local.Unlock()
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *leaseLocker[I]) Held() []Held[I] {
+	return l.local.(Inspector[I]).Held()
+}
+
+// hold starts the heartbeat of an acquired lease
+func (l *leaseLocker[I]) hold(key string, owner string, token uint64, local Lock) *Lease {
+	ctx, stop := context.WithCancel(context.Background())
+	lease := &Lease{
+		token: token,
+		lost:  make(chan struct{}),
+		stop:  stop,
+	}
+	lease.heartbeat.Go(func() {
+		ticker := time.NewTicker(l.config.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := l.store.Renew(ctx, key, owner, l.config.ttl); err != nil && ctx.Err() == nil {
+				slog.Error("lease renewal failed", "key", key, "token", token, "error", err)
+				if errors.Is(err, ErrLeaseLost) {
+					close(lease.lost)
+					return
+				}
+			}
+		}
+	})
+	lease.release = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := l.store.Release(ctx, key, owner); err != nil {
+			// the lease expires after its TTL anyway
+			slog.Error("lease release failed", "key", key, "token", token, "error", err)
+		}
+		local.Unlock()
+	}
+	return lease
+}
+
+// Lease is a lock held across replicas, it is renewed until it is unlocked
+type Lease struct {
+	token     uint64
+	lost      chan struct{}
+	stop      context.CancelFunc
+	heartbeat sync.WaitGroup
+	release   func()
+	unlock    sync.Once
+}
+
+// Token returns the fencing token of the lease, it is higher than the tokens of all earlier leases of the ID
+// across all replicas sharing the store.
+func (l *Lease) Token() uint64 {
+	return l.token
+}
+
+// Lost is closed when the lease couldn't be renewed and may be held by another replica
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *Lease) Unlock() {
+	l.unlock.Do(func() {
+		l.stop()
+		l.heartbeat.Wait()
+		l.release()
+	})
+}
@@ -0,0 +1,66 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryLease struct {
+	owner   string
+	expires time.Time
+}
+
+// MemoryLeaseStore keeps leases in the process, it is meant for tests and single replicas
+type MemoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+	// tokens keeps the last fencing token of every key, also after its lease was released
+	tokens map[string]uint64
+	now    func() time.Time
+}
+
+func NewMemoryLeaseStore() *MemoryLeaseStore {
+	return &MemoryLeaseStore{
+		leases: make(map[string]memoryLease),
+		tokens: make(map[string]uint64),
+		now:    time.Now,
+	}
+}
+
+func (s *MemoryLeaseStore) Acquire(_ context.Context, key string, owner string, ttl time.Duration) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if lease, held := s.leases[key]; held && now.Before(lease.expires) {
+		return 0, nil
+	}
+	s.leases[key] = memoryLease{owner: owner, expires: now.Add(ttl)}
+	s.tokens[key]++
+	return s.tokens[key], nil
+}
+
+func (s *MemoryLeaseStore) Renew(_ context.Context, key string, owner string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	lease, held := s.leases[key]
+	if !held || lease.owner != owner || !now.Before(lease.expires) {
+		return ErrLeaseLost
+	}
+	lease.expires = now.Add(ttl)
+	s.leases[key] = lease
+	return nil
+}
+
+func (s *MemoryLeaseStore) Release(_ context.Context, key string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, held := s.leases[key]; held && lease.owner == owner {
+		delete(s.leases, key)
+	}
+	return nil
+}
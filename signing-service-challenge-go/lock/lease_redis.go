@@ -0,0 +1,79 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix namespaces the keys of the leases in Redis
+const DefaultRedisPrefix = "signing:lock:"
+
+var (
+	// acquireScript sets the lease unless it is held and increments the fencing token of the key.
+	// KEYS: lease, token; ARGV: owner, ttl in milliseconds
+	acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`)
+	// renewScript extends the lease if it is still held by the owner.
+	// KEYS: lease; ARGV: owner, ttl in milliseconds
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	// releaseScript deletes the lease if it is still held by the owner.
+	// KEYS: lease; ARGV: owner
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLeaseStore keeps leases in Redis, so all replicas connected to it share them.
+// The fencing tokens are kept without expiry, so they keep increasing when leases expire.
+type RedisLeaseStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisLeaseStore creates a lease store with keys below the prefix, [DefaultRedisPrefix] if empty.
+func NewRedisLeaseStore(client redis.UniversalClient, prefix string) *RedisLeaseStore {
+	if prefix == "" {
+		prefix = DefaultRedisPrefix
+	}
+	return &RedisLeaseStore{client: client, prefix: prefix}
+}
+
+// keys returns the keys of the lease and its token, the hash tag keeps both in the same slot of a cluster
+func (s *RedisLeaseStore) keys(key string) []string {
+	lease := s.prefix + "{" + key + "}"
+	return []string{lease, lease + ":token"}
+}
+
+func (s *RedisLeaseStore) Acquire(ctx context.Context, key string, owner string, ttl time.Duration) (uint64, error) {
+	token, err := acquireScript.Run(ctx, s.client, s.keys(key), owner, ttl.Milliseconds()).Uint64()
+	if err != nil {
+		return 0, err
+	}
+	return token, nil
+}
+
+func (s *RedisLeaseStore) Renew(ctx context.Context, key string, owner string, ttl time.Duration) error {
+	renewed, err := renewScript.Run(ctx, s.client, s.keys(key)[:1], owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *RedisLeaseStore) Release(ctx context.Context, key string, owner string) error {
+	return releaseScript.Run(ctx, s.client, s.keys(key)[:1], owner).Err()
+}
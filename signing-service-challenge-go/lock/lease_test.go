@@ -0,0 +1,187 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// testStores returns the lease stores with a function letting their leases expire
+func testStores(t *testing.T) map[string]struct {
+	store  LeaseStore
+	expire func(time.Duration)
+} {
+	memory := NewMemoryLeaseStore()
+	offset := time.Duration(0)
+	memory.now = func() time.Time { return time.Now().Add(offset) }
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]struct {
+		store  LeaseStore
+		expire func(time.Duration)
+	}{
+		"memory": {memory, func(d time.Duration) {
+			memory.mu.Lock()
+			defer memory.mu.Unlock()
+			offset += d
+		}},
+		"redis": {NewRedisLeaseStore(client, ""), server.FastForward},
+	}
+}
+
+func TestLeaseStore(t *testing.T) {
+	ctx := context.Background()
+	for name, tc := range testStores(t) {
+		assert := require.New(t)
+		store := tc.store
+
+		// Test case 1: A lease is only acquired by one owner
+		token, err := store.Acquire(ctx, "device", "a", time.Second)
+		assert.NoError(err, name)
+		assert.Equal(uint64(1), token, name)
+		token, err = store.Acquire(ctx, "device", "b", time.Second)
+		assert.NoError(err, name)
+		assert.Zero(token, name)
+
+		// Test case 2: Only the owner renews and releases the lease
+		assert.NoError(store.Renew(ctx, "device", "a", time.Second), name)
+		assert.ErrorIs(store.Renew(ctx, "device", "b", time.Second), ErrLeaseLost, name)
+		assert.NoError(store.Release(ctx, "device", "b"), name)
+		token, _ = store.Acquire(ctx, "device", "b", time.Second)
+		assert.Zero(token, name)
+		assert.NoError(store.Release(ctx, "device", "a"), name)
+
+		// Test case 3: Released leases are acquired with a higher token
+		token, err = store.Acquire(ctx, "device", "b", time.Second)
+		assert.NoError(err, name)
+		assert.Equal(uint64(2), token, name)
+
+		// Test case 4: Expired leases are lost and acquired by the next owner
+		tc.expire(2 * time.Second)
+		assert.ErrorIs(store.Renew(ctx, "device", "b", time.Second), ErrLeaseLost, name)
+		token, err = store.Acquire(ctx, "device", "c", time.Second)
+		assert.NoError(err, name)
+		assert.Equal(uint64(3), token, name)
+
+		// Test case 5: Keys have their own leases and tokens
+		token, err = store.Acquire(ctx, "other", "a", time.Second)
+		assert.NoError(err, name)
+		assert.Equal(uint64(1), token, name)
+	}
+}
+
+func TestLeaseLocker(t *testing.T) {
+	for name, tc := range testStores(t) {
+		assert := require.New(t)
+		// two replicas sharing the store
+		replicas := []Locker[string]{
+			NewLeaseLocker[string](tc.store, WithLeaseTTL(30*time.Millisecond), WithLeaseRetry(time.Millisecond)),
+			NewLeaseLocker[string](tc.store, WithLeaseTTL(30*time.Millisecond), WithLeaseRetry(time.Millisecond)),
+		}
+
+		held, err := replicas[0].Acquire(context.Background(), "device")
+		assert.NoError(err, name)
+		first := held.(*Lease).Token()
+
+		// Test case 1: The other replica waits, also beyond the TTL as the lease is renewed
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		_, err = replicas[1].Acquire(ctx, "device")
+		cancel()
+		assert.ErrorIs(err, context.DeadlineExceeded, name)
+		assert.Len(replicas[0].(Inspector[string]).Held(), 1, name)
+		assert.Empty(replicas[1].(Inspector[string]).Held(), name)
+
+		// Test case 2: Once unlocked the other replica acquires it with a higher token
+		acquired := make(chan Lock)
+		go func() {
+			l, err := replicas[1].Acquire(context.Background(), "device")
+			assert.NoError(err)
+			acquired <- l
+		}()
+		held.Unlock()
+		held.Unlock() // unlocking twice is harmless
+		second := <-acquired
+		assert.Greater(second.(*Lease).Token(), first, name)
+		second.Unlock()
+	}
+}
+
+func TestLeaseLockerExclusive(t *testing.T) {
+	assert := require.New(t)
+	store := NewMemoryLeaseStore()
+	replicas := []Locker[int]{
+		NewLeaseLocker[int](store, WithLeaseRetry(time.Millisecond)),
+		NewLeaseLocker[int](store, WithLeaseRetry(time.Millisecond)),
+	}
+
+	// callers of both replicas increment a counter, which only works if they are serialized
+	counter := 0
+	var lastToken uint64
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			held, err := replicas[i%2].Acquire(context.Background(), 1)
+			assert.NoError(err)
+			defer held.Unlock()
+
+			token := held.(*Lease).Token()
+			assert.Greater(token, lastToken)
+			lastToken = token
+			value := counter
+			time.Sleep(time.Millisecond)
+			counter = value + 1
+		})
+	}
+	wg.Wait()
+	assert.Equal(20, counter)
+}
+
+func TestLeaseLost(t *testing.T) {
+	assert := require.New(t)
+	store := NewMemoryLeaseStore()
+	locker := NewLeaseLocker[string](store, WithLeaseTTL(30*time.Millisecond))
+
+	held, err := locker.Acquire(context.Background(), "device")
+	assert.NoError(err)
+	lease := held.(*Lease)
+
+	// the process pauses beyond the TTL, another replica takes the lease over
+	store.mu.Lock()
+	store.now = func() time.Time { return time.Now().Add(time.Minute) }
+	store.mu.Unlock()
+	token, err := store.Acquire(context.Background(), "device", "other", time.Hour)
+	assert.NoError(err)
+	assert.Greater(token, lease.Token())
+
+	select {
+	case <-lease.Lost():
+	case <-time.After(time.Second):
+		assert.Fail("lost lease wasn't noticed by the heartbeat")
+	}
+	held.Unlock()
+
+	// Test case 2: Unlocking the lost lease doesn't release the lease of the other replica
+	token, err = store.Acquire(context.Background(), "device", "third", time.Hour)
+	assert.NoError(err)
+	assert.Zero(token)
+}
+
+func TestLeaseTTLMinimum(t *testing.T) {
+	assert := require.New(t)
+
+	// TTLs rounding to a zero heartbeat or to zero milliseconds are raised, so the leases still work
+	for name, tc := range testStores(t) {
+		locker := NewLeaseLocker[string](tc.store, WithLeaseTTL(time.Nanosecond))
+		held, err := locker.Acquire(context.Background(), "device")
+		assert.NoError(err, name)
+		held.Unlock()
+	}
+}
@@ -4,11 +4,15 @@ package lock
 
 import (
 	"context"
+	"time"
 )
 
 // Lock represents an acquired lock that can be released
 type Lock interface {
 	Unlock()
+	// Token is the fencing token of the lock, it is higher than the tokens of all earlier locks of the same ID.
+	// Writes guarded by the lock pass it to the storage, which rejects writes of holders whose lock expired meanwhile.
+	Token() uint64
 }
 
 // Locker interface for distributed lock service could be done with redis
@@ -16,3 +20,16 @@ type Lock interface {
 type Locker[I comparable] interface {
 	Acquire(context.Context, I) (Lock, error)
 }
+
+// Held describes a held lock and the callers waiting for it
+type Held[I comparable] struct {
+	ID      I
+	Since   time.Time
+	Waiters int
+}
+
+// Inspector lists the held locks, e.g. to find the devices signing requests contend on
+type Inspector[I comparable] interface {
+	// Held returns the held locks, the longest held first
+	Held() []Held[I]
+}
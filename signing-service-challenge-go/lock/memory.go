@@ -2,17 +2,26 @@ package lock
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type memoryLocker[I comparable] struct {
 	mu       sync.Mutex
 	registry map[I]*lock
+	// waiters counts the callers waiting for the lock of each ID
+	waiters map[I]int
+	// tokens is shared by all IDs, so it also increases for each of them
+	tokens atomic.Uint64
 }
 
+// NewMemoryLocker creates a locker serializing callers within the process, it implements [Inspector].
 func NewMemoryLocker[I comparable]() Locker[I] {
 	return &memoryLocker[I]{
 		registry: make(map[I]*lock),
+		waiters:  make(map[I]int),
 	}
 }
 
@@ -25,11 +34,14 @@ func (m *memoryLocker[I]) Acquire(ctx context.Context, id I) (Lock, error) {
 	for {
 		if exists {
 			// Step 2: If lock exists, wait for it to be released or context to be cancelled
+			m.waiting(id, 1)
 			select {
 			case <-ctx.Done():
+				m.waiting(id, -1)
 				return nil, ctx.Err()
 			case <-l.wait:
 			}
+			m.waiting(id, -1)
 		}
 
 		// Step 3: Re-check if lock still exists after waiting
@@ -43,7 +55,9 @@ func (m *memoryLocker[I]) Acquire(ctx context.Context, id I) (Lock, error) {
 
 		// Step 5: Create new lock with cleanup function
 		l = &lock{
-			wait: make(chan struct{}), // Channel that will be closed when lock is released
+			wait:     make(chan struct{}), // Channel that will be closed when lock is released
+			acquired: time.Now(),
+			token:    m.tokens.Add(1),
 			remove: func() {
 				m.mu.Lock()
 				defer m.mu.Unlock()
@@ -60,9 +74,39 @@ func (m *memoryLocker[I]) Acquire(ctx context.Context, id I) (Lock, error) {
 	return l, nil
 }
 
+// waiting adds delta to the waiters of the ID
+func (m *memoryLocker[I]) waiting(id I, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.waiters[id] += delta
+	if m.waiters[id] <= 0 {
+		delete(m.waiters, id)
+	}
+}
+
+func (m *memoryLocker[I]) Held() []Held[I] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	held := make([]Held[I], 0, len(m.registry))
+	for id, l := range m.registry {
+		held = append(held, Held[I]{ID: id, Since: l.acquired, Waiters: m.waiters[id]})
+	}
+	slices.SortFunc(held, func(a, b Held[I]) int {
+		return a.Since.Compare(b.Since)
+	})
+	return held
+}
+
 type lock struct {
-	wait   chan struct{}
-	remove func()
+	wait     chan struct{}
+	acquired time.Time
+	token    uint64
+	remove   func()
+}
+
+func (l *lock) Token() uint64 {
+	return l.token
 }
 
 func (l *lock) Unlock() {
@@ -0,0 +1,23 @@
+package lock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLockerToken(t *testing.T) {
+	assert := require.New(t)
+	locker := NewMemoryLocker[string]()
+
+	// Test case 1: Every lock gets a higher token, also across IDs
+	var last uint64
+	for _, id := range []string{"a", "a", "b", "a"} {
+		held, err := locker.Acquire(context.Background(), id)
+		assert.NoError(err)
+		assert.Greater(held.Token(), last)
+		last = held.Token()
+		held.Unlock()
+	}
+}
@@ -3,40 +3,235 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
-	"log"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
 
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/admin"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/api"
+	appconfig "github.com/fiskaly/coding-challenges/signing-service-challenge/config"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain/deviceManager"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/grpcapi"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/health"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/metrics"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/outbox"
 	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/tracing"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/webhook"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
-var config = struct {
-	ListenAddress string
-}{}
+// minFreeDiskSpace below which the service reports itself as degraded
+const minFreeDiskSpace = 100 << 20
 
 func main() {
-	flag.StringVar(&config.ListenAddress, "listen-address", ":8080", "api listen address")
-	flag.Parse()
+	config, err := appconfig.Parse(os.Args[0], os.Args[1:], os.LookupEnv, os.Stdout)
+	switch {
+	case errors.Is(err, flag.ErrHelp), errors.Is(err, appconfig.ErrPrintConfig):
+		return
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Invalid configuration:", err)
+		os.Exit(2)
+	}
+
+	logHandler, logLevel := config.Log.Handler(os.Stdout)
+	slog.SetDefault(slog.New(logHandler))
+
+	if err := run(config, logLevel); err != nil {
+		slog.Error("service stopped", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("shutdown complete")
+}
+
+// run serves the apis until SIGINT or SIGTERM, or until one of the servers fails.
+// Errors are returned instead of exiting, so the deferred cleanup still runs.
+func run(config appconfig.Config, logLevel *slog.LevelVar) error {
+	// SIGINT and SIGTERM start a graceful shutdown, the servers drain before storage is closed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// a failing server shuts the others down the same way, with its error as cause
+	ctx, fail := context.WithCancelCause(ctx)
+	defer fail(nil)
 
-	loggerOptions := &slog.HandlerOptions{
-		Level: slog.LevelDebug,
+	shutdownTracing, err := tracing.Setup(context.Background(), config.TraceExporter)
+	if err != nil {
+		return fmt.Errorf("could not set up tracing: %w", err)
 	}
-	logger := slog.Handler(slog.NewTextHandler(os.Stdout, loggerOptions))
-	slog.SetDefault(slog.New(logger))
+	defer shutdownTracing(context.Background())
 
-	storage := persistence.NewMemoryStorage()
+	var storage persistence.Storage
+	switch config.Storage {
+	case appconfig.StorageMemory:
+		storage = persistence.NewMemoryStorage()
+	}
 	defer storage.Close()
+	if config.TraceExporter != tracing.ExporterNone {
+		storage = persistence.Instrument(storage, tracing.ObserveStorage)
+	}
+
+	var serviceMetrics *metrics.Metrics
+	if config.Metrics {
+		serviceMetrics = metrics.New()
+		storage = persistence.Instrument(storage, serviceMetrics.ObserveStorage)
+		serviceMetrics.CollectDevices(storage)
+	}
+
+	// deliver events from the outbox to the sinks in the background for as long as the server runs
+	sinks := []outbox.Sink{webhook.NewSink(storage)}
+	var healthChecks []api.Option
+	if config.EventLog {
+		sinks = append(sinks, outbox.NewLogSink(slog.Default()))
+	}
+	if config.EventFile != "" {
+		fileSink, err := outbox.NewFileSink(config.EventFile)
+		if err != nil {
+			return fmt.Errorf("could not open event file %s: %w", config.EventFile, err)
+		}
+		defer fileSink.Close()
+		sinks = append(sinks, fileSink)
+		// a full disk doesn't stop signing, but events can't be appended anymore
+		healthChecks = append(healthChecks, api.WithHealthCheck("disk", health.DiskSpace(filepath.Dir(config.EventFile), minFreeDiskSpace), health.NonCritical()))
+	}
+	// the dispatchers keep running until the servers are drained, so events of the last requests are still delivered
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+	}()
+	workers.Go(func() { outbox.NewDispatcher(storage, sinks).Run(workersCtx) })
+	webhookOptions := []webhook.Option{webhook.WithInterval(config.WebhookPoll), webhook.WithRetention(config.WebhookRetain)}
+	if config.WebhookPrivate {
+		webhookOptions = append(webhookOptions, webhook.WithPrivateTargets())
+	}
+	webhookDispatcher := webhook.NewDispatcher(storage, webhookOptions...)
+	workers.Go(func() { webhookDispatcher.Run(workersCtx) })
+	workers.Go(func() { webhookDispatcher.Sweep(workersCtx, webhook.DefaultSweepInterval) })
+
+	options := []api.Option{
+		api.WithIdempotencyTTL(config.IdempotencyTTL),
+		api.WithRateLimits(config.RateLimits),
+		api.WithErrorFormat(config.ErrorFormat),
+		api.WithTimeouts(config.Timeouts),
+		api.WithCryptoPolicy(config.Crypto),
+	}
+	options = append(options, healthChecks...)
+	if config.WebhookPrivate {
+		options = append(options, api.WithPrivateWebhookTargets())
+	}
+	if config.TLSEnabled() {
+		options = append(options, api.WithTLS(config.TLS))
+	}
+	if config.AdminKey != "" {
+		options = append(options, api.WithAuthentication(config.AdminKey))
+	} else {
+		slog.Warn("no admin key configured, authentication is disabled")
+	}
 
+	var locker lock.Locker[uuid.UUID]
+	switch config.Lock.Backend {
+	case appconfig.LockMemory:
+		locker = lock.NewMemoryLocker[uuid.UUID]()
+	case appconfig.LockRedis:
+		// the url was validated with the config
+		redisOptions, _ := redis.ParseURL(config.Lock.RedisURL)
+		redisClient := redis.NewClient(redisOptions)
+		defer redisClient.Close()
+		locker = lock.NewLeaseLocker[uuid.UUID](lock.NewRedisLeaseStore(redisClient, ""), lock.WithLeaseTTL(config.Lock.TTL))
+	}
+	// the locks are inspected and probed before they are wrapped, the wrappers only see acquisitions of requests
+	lockInspector, _ := locker.(lock.Inspector[uuid.UUID])
+	options = append(options, api.WithHealthCheck("lock", health.Locker(locker, uuid.Nil)))
+	if config.TraceExporter != tracing.ExporterNone {
+		locker = tracing.InstrumentLocker(locker)
+	}
+	if serviceMetrics != nil {
+		locker = metrics.InstrumentLocker(locker, serviceMetrics)
+		options = append(options, api.WithMetrics(serviceMetrics))
+		// without the admin listener the api serves them, so they are available with the defaults
+		if config.Admin.ListenAddress == "" {
+			options = append(options, api.WithMetricsEndpoint())
+		}
+	}
 	server := api.NewServer(
 		storage,
-		lock.NewMemoryLocker[uuid.UUID](),
+		locker,
+		options...,
 	)
 
-	if err := server.Run(config.ListenAddress); err != nil {
-		log.Fatal("Could not start server on ", config.ListenAddress)
+	// expired idempotency records are removed in the background, signing requests only read them
+	workers.Go(func() {
+		server.DeviceManager().SweepIdempotency(workersCtx, deviceManager.DefaultIdempotencySweepInterval)
+	})
+
+	// serve the grpc api next to the http api, both share the device service and the locks
+	var servers sync.WaitGroup
+	if config.GRPCAddress != "" {
+		grpcOptions := []grpcapi.Option{
+			grpcapi.WithShutdownTimeout(config.Timeouts.Shutdown),
+			grpcapi.WithRateLimiters(server.RateLimiters()),
+		}
+		if config.AdminKey != "" {
+			grpcOptions = append(grpcOptions, grpcapi.WithAuthentication(config.AdminKey))
+		}
+		if config.TLSEnabled() {
+			tlsConfig, err := config.TLS.ServerConfig()
+			if err != nil {
+				return fmt.Errorf("could not load tls configuration: %w", err)
+			}
+			grpcOptions = append(grpcOptions, grpcapi.WithTLS(tlsConfig))
+		}
+		grpcServer := grpcapi.NewServer(storage, server.DeviceManager(), locker, grpcOptions...)
+		servers.Go(func() {
+			stopOnFailure(ctx, fail, "grpc server on "+config.GRPCAddress, grpcServer.Run(ctx, config.GRPCAddress))
+		})
+	}
+
+	// serve the admin endpoints on their own listener, so profiles can't be requested by api clients
+	if config.Admin.ListenAddress != "" {
+		adminOptions := []admin.Option{
+			admin.WithLogLevel(logLevel),
+			admin.WithLocks(lockInspector),
+			admin.WithStorage(storage),
+		}
+		if serviceMetrics != nil {
+			adminOptions = append(adminOptions, admin.WithMetrics(serviceMetrics.Handler()))
+		}
+		adminServer := admin.NewServer(config.Admin.Username, config.Admin.Password, adminOptions...)
+		servers.Go(func() {
+			stopOnFailure(ctx, fail, "admin server on "+config.Admin.ListenAddress, adminServer.Run(ctx, config.Admin.ListenAddress))
+		})
+	}
+
+	stopOnFailure(ctx, fail, "server on "+config.ListenAddress, server.Run(ctx, config.ListenAddress))
+	servers.Wait()
+
+	// the cause is context.Canceled if the shutdown was started by a signal
+	if err := context.Cause(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// stopOnFailure shuts all servers down with the error of a server which stopped before the shutdown started.
+// Errors after the shutdown started, e.g. when draining timed out, are only logged, storage is still closed cleanly.
+func stopOnFailure(ctx context.Context, fail context.CancelCauseFunc, server string, err error) {
+	if err == nil {
+		return
+	}
+	if ctx.Err() == nil {
+		fail(fmt.Errorf("%s failed: %w", server, err))
+		return
 	}
+	slog.Error(server+" shutdown failed", "error", err)
 }
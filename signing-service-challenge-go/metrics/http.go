@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// unmatchedRoute labels requests which didn't match any route, so unknown paths can't create new series
+const unmatchedRoute = "unmatched"
+
+// Middleware counts requests and measures their latency by route pattern, method and status code.
+// It has to be used with a chi router, the route pattern is only known after routing.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		route := unmatchedRoute
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+			route = routeContext.RoutePattern()
+		}
+		labels := []string{route, r.Method, strconv.Itoa(recorder.Status())}
+		m.requests.WithLabelValues(labels...).Inc()
+		m.requestDuration.WithLabelValues(labels...).Observe(time.Since(started).Seconds())
+	})
+}
+
+// statusRecorder remembers the status code written by a handler.
+// Streaming handlers keep working, flushes are passed on to the wrapped writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Unwrap gives [http.ResponseController] access to the wrapped writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Status returns the written status code, handlers which didn't write anything respond with 200
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+)
+
+// InstrumentLocker measures how long acquiring locks of the locker takes, including failed attempts
+func InstrumentLocker[I comparable](locker lock.Locker[I], m *Metrics) lock.Locker[I] {
+	return &instrumentedLocker[I]{locker: locker, metrics: m}
+}
+
+type instrumentedLocker[I comparable] struct {
+	locker  lock.Locker[I]
+	metrics *Metrics
+}
+
+func (l *instrumentedLocker[I]) Acquire(ctx context.Context, id I) (lock.Lock, error) {
+	started := time.Now()
+	acquired, err := l.locker.Acquire(ctx, id)
+	result := "acquired"
+	if err != nil {
+		result = "failed"
+	}
+	l.metrics.lockWait.WithLabelValues(result).Observe(time.Since(started).Seconds())
+	return acquired, err
+}
@@ -0,0 +1,170 @@
+// Package metrics exposes the telemetry of the signing service in the Prometheus format.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "signing_service"
+
+// collectTimeout bounds the storage queries of a scrape
+const collectTimeout = 5 * time.Second
+
+// Metrics holds the collectors of the service, it implements [deviceManager.Metrics]
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	signing         *prometheus.HistogramVec
+	keyGeneration   *prometheus.HistogramVec
+	lockWait        *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+}
+
+// New creates the collectors of the service and of the Go runtime in a separate registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by route pattern, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		signing: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "signing_duration_seconds",
+			Help:      "Latency of creating a signature by signing algorithm.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}, []string{"algorithm"}),
+		keyGeneration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "key_generation_duration_seconds",
+			Help:      "Latency of generating a key pair by signing algorithm.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}, []string{"algorithm"}),
+		lockWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "lock_wait_duration_seconds",
+			Help:      "Time spent waiting for device locks by result, acquired or failed.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"result"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_errors_total",
+			Help:      "Number of failed storage operations by repository and operation, missing and conflicting records are not counted.",
+		}, []string{"repository", "operation"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.signing,
+		m.keyGeneration,
+		m.lockWait,
+		m.storageErrors,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveSigning records the duration of creating a signature
+func (m *Metrics) ObserveSigning(algorithm domain.SigningAlgorithm, duration time.Duration) {
+	m.signing.WithLabelValues(string(algorithm)).Observe(duration.Seconds())
+}
+
+// ObserveKeyGeneration records the duration of generating a key pair
+func (m *Metrics) ObserveKeyGeneration(algorithm domain.SigningAlgorithm, duration time.Duration) {
+	m.keyGeneration.WithLabelValues(string(algorithm)).Observe(duration.Seconds())
+}
+
+// ObserveStorage counts failed storage operations, it is a [persistence.Observer]
+func (m *Metrics) ObserveStorage(ctx context.Context, repository string, operation string) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		if err == nil || errors.Is(err, persistence.ErrNotFound) || errors.Is(err, persistence.ErrAlreadyExists) {
+			return
+		}
+		m.storageErrors.WithLabelValues(repository, operation).Inc()
+	}
+}
+
+// CollectDevices reports the number of devices by signing algorithm, counted in the storage on every scrape
+func (m *Metrics) CollectDevices(storage persistence.Storage) {
+	m.registry.MustRegister(&deviceCollector{
+		storage: storage,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "devices"),
+			"Number of devices by signing algorithm.",
+			[]string{"algorithm"},
+			nil,
+		),
+	})
+}
+
+type deviceCollector struct {
+	storage persistence.Storage
+	desc    *prometheus.Desc
+}
+
+func (c *deviceCollector) Describe(descriptions chan<- *prometheus.Desc) {
+	descriptions <- c.desc
+}
+
+func (c *deviceCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		slog.Error("failed counting devices", "error", err)
+		metrics <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for algorithm, count := range counts {
+		metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), string(algorithm))
+	}
+}
+
+// count sums the devices of all organizations, repositories only see a single organization at a time
+func (c *deviceCollector) count(ctx context.Context) (map[domain.SigningAlgorithm]int64, error) {
+	organizations, err := c.storage.Organizations().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[domain.SigningAlgorithm]int64{
+		domain.SigningAlgorithmEcc: 0,
+		domain.SigningAlgorithmRsa: 0,
+	}
+	for _, organization := range organizations {
+		ctx := domain.WithOrganization(ctx, organization.Id)
+		for algorithm := range counts {
+			count, err := c.storage.Devices().Count(ctx, domain.DeviceFilter{SigningAlgorithm: algorithm})
+			if err != nil {
+				return nil, err
+			}
+			counts[algorithm] += count
+		}
+	}
+	return counts, nil
+}
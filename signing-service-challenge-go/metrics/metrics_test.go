@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	assert := require.New(t)
+	m := New()
+
+	mux := chi.NewMux()
+	mux.Use(m.Middleware)
+	mux.Get("/device/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: foo\n\n"))
+		assert.NoError(http.NewResponseController(w).Flush())
+	})
+
+	for _, path := range []string{"/device/1", "/device/2", "/events", "/unknown"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Test case 1: Requests are counted by route pattern instead of path
+	assert.Equal(2.0, testutil.ToFloat64(m.requests.WithLabelValues("/device/{id}", http.MethodGet, "404")))
+	assert.Equal(1.0, testutil.ToFloat64(m.requests.WithLabelValues("/events", http.MethodGet, "200")))
+	assert.Equal(1.0, testutil.ToFloat64(m.requests.WithLabelValues(unmatchedRoute, http.MethodGet, "404")))
+	assert.Equal(3, testutil.CollectAndCount(m.requestDuration))
+
+	// Test case 2: Streaming responses are still flushed
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(recorder.Flushed)
+}
+
+func TestObserveStorage(t *testing.T) {
+	assert := require.New(t)
+	m := New()
+
+	_, done := m.ObserveStorage(context.Background(), "devices", "get")
+	done(persistence.ErrNotFound)
+	_, done = m.ObserveStorage(context.Background(), "devices", "get")
+	done(nil)
+	assert.Equal(0, testutil.CollectAndCount(m.storageErrors))
+
+	_, done = m.ObserveStorage(context.Background(), "devices", "update")
+	done(errors.New("connection lost"))
+	assert.Equal(1.0, testutil.ToFloat64(m.storageErrors.WithLabelValues("devices", "update")))
+}
+
+func TestCollectDevices(t *testing.T) {
+	assert := require.New(t)
+	m := New()
+	storage := persistence.NewMemoryStorage()
+	m.CollectDevices(storage)
+
+	ctx := context.Background()
+	organization := &domain.Organization{Id: uuid.New(), Name: "foo", CreatedAt: time.Now()}
+	assert.NoError(storage.Organizations().Create(ctx, organization))
+	for _, device := range []*domain.Device{
+		{Id: uuid.New(), OrganizationId: domain.DefaultOrganizationId, SigningAlgorithm: domain.SigningAlgorithmEcc},
+		{Id: uuid.New(), OrganizationId: organization.Id, SigningAlgorithm: domain.SigningAlgorithmEcc},
+		{Id: uuid.New(), OrganizationId: organization.Id, SigningAlgorithm: domain.SigningAlgorithmRsa},
+	} {
+		assert.NoError(storage.Devices().Create(domain.WithOrganization(ctx, device.OrganizationId), device))
+	}
+
+	families, err := m.registry.Gather()
+	assert.NoError(err)
+	counts := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "signing_service_devices" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			counts[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	assert.Equal(map[string]float64{"ECC": 2, "RSA": 1}, counts)
+}
+
+func TestInstrumentLocker(t *testing.T) {
+	assert := require.New(t)
+	m := New()
+	locker := InstrumentLocker(lock.NewMemoryLocker[int](), m)
+
+	held, err := locker.Acquire(context.Background(), 1)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = locker.Acquire(ctx, 1)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	held.Unlock()
+
+	assert.Equal(2, testutil.CollectAndCount(m.lockWait))
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"reflect"
 )
 
 var (
@@ -53,6 +54,11 @@ func (n Null[T]) SqlNull() sql.Null[T] {
 	}
 }
 
+// ValueType returns the type of the wrapped value, which is encoded in place of the Null unless it is empty.
+func (n Null[T]) ValueType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
 func (n Null[T]) IsZero() bool {
 	return !n.filled
 }
@@ -76,3 +82,47 @@ func (n *Null[T]) UnmarshalJSON(bytes []byte) error {
 	n.filled = true
 	return nil
 }
+
+var (
+	_ json.Unmarshaler = (*Patch[string])(nil)
+	_ json.Marshaler   = (*Patch[string])(nil)
+)
+
+// Patch extends [Null] with the information whether the field was present in the decoded document at all,
+// which allows distinguishing an absent field from a field explicitly set to null (JSON Merge Patch semantics).
+type Patch[T any] struct {
+	Null[T]
+	present bool
+}
+
+// Set returns a present patch carrying a value.
+func Set[T any](value T) Patch[T] {
+	return Patch[T]{
+		Null:    New(value),
+		present: true,
+	}
+}
+
+// Clear returns a present patch explicitly set to null.
+func Clear[T any]() Patch[T] {
+	return Patch[T]{
+		present: true,
+	}
+}
+
+func (p Patch[T]) Present() bool {
+	return p.present
+}
+
+func (p Patch[T]) IsZero() bool {
+	return !p.present
+}
+
+func (p Patch[T]) MarshalJSON() ([]byte, error) {
+	return p.Null.MarshalJSON()
+}
+
+func (p *Patch[T]) UnmarshalJSON(bytes []byte) error {
+	p.present = true
+	return p.Null.UnmarshalJSON(bytes)
+}
@@ -68,3 +68,61 @@ func TestNullJsonUnmarshal(t *testing.T) {
 		assert.Equal(0, s.value)
 	}
 }
+
+func TestPatchJsonUnmarshal(t *testing.T) {
+	assert := require.New(t)
+
+	type document struct {
+		Label Patch[string] `json:"label,omitzero"`
+	}
+
+	{
+		var d document
+		err := json.Unmarshal([]byte(`{}`), &d)
+		assert.NoError(err)
+		assert.False(d.Label.Present())
+		assert.False(d.Label.Filled())
+	}
+
+	{
+		var d document
+		err := json.Unmarshal([]byte(`{"label":null}`), &d)
+		assert.NoError(err)
+		assert.True(d.Label.Present())
+		assert.False(d.Label.Filled())
+	}
+
+	{
+		var d document
+		err := json.Unmarshal([]byte(`{"label":"foo"}`), &d)
+		assert.NoError(err)
+		assert.True(d.Label.Present())
+		assert.Equal("foo", d.Label.Some())
+	}
+}
+
+func TestPatchJsonMarshal(t *testing.T) {
+	assert := require.New(t)
+
+	type document struct {
+		Label Patch[string] `json:"label,omitzero"`
+	}
+
+	{
+		marshal, err := json.Marshal(document{})
+		assert.NoError(err)
+		assert.Equal(`{}`, string(marshal))
+	}
+
+	{
+		marshal, err := json.Marshal(document{Label: Clear[string]()})
+		assert.NoError(err)
+		assert.Equal(`{"label":null}`, string(marshal))
+	}
+
+	{
+		marshal, err := json.Marshal(document{Label: Set("foo")})
+		assert.NoError(err)
+		assert.Equal(`{"label":"foo"}`, string(marshal))
+	}
+}
@@ -0,0 +1,162 @@
+// Package openapi describes HTTP APIs as OpenAPI 3.1 documents, with schemas generated from Go types,
+// and validates responses against them.
+package openapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version of the OpenAPI specification the documents follow
+const Version = "3.1.0"
+
+// Document is the root of an OpenAPI description
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// SecurityRequirement maps security scheme names to the scopes required by an operation
+type SecurityRequirement map[string][]string
+
+// PathItem holds the operations of a path by lower case HTTP method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter locations
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// New creates an empty document.
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+	}
+}
+
+// AddOperation registers the operation for the method and path template, e.g. /device/{id}.
+func (d *Document) AddOperation(method string, path string, operation *Operation) {
+	item, found := d.Paths[path]
+	if !found {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = operation
+}
+
+// Find returns the operation matching the method and the concrete path of a request.
+func (d *Document) Find(method string, path string) (*Operation, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for template, item := range d.Paths {
+		if !matchPath(strings.Split(strings.Trim(template, "/"), "/"), segments) {
+			continue
+		}
+		if operation, found := (*item)[strings.ToLower(method)]; found {
+			return operation, true
+		}
+	}
+	return nil, false
+}
+
+func matchPath(template []string, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, part := range template {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Response returns the documented response for a status code,
+// falling back to the status range (e.g. 4XX) and the default response.
+func (o *Operation) Response(status int) (*Response, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if response, found := o.Responses[key]; found {
+			return response, true
+		}
+	}
+	return nil, false
+}
+
+// JSON returns the content of a single JSON media type.
+func JSON(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{
+		"application/json": {Schema: schema},
+	}
+}
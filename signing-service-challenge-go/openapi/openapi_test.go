@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/null"
+	"github.com/stretchr/testify/require"
+)
+
+type testColor string
+
+type testBase struct {
+	Id string `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Color    testColor          `json:"color"`
+	Label    null.Null[string]  `json:"label,omitzero"`
+	Parent   *testNode          `json:"parent"`
+	Children []testNode         `json:"children,omitempty"`
+	Note     null.Patch[string] `json:"note,omitzero"`
+	Updated  time.Time          `json:"updated"`
+	Ignored  string             `json:"-"`
+	internal string
+}
+
+func TestGenerator(t *testing.T) {
+	assert := require.New(t)
+	document := New(Info{Title: "test", Version: "v0"})
+	generator := NewGenerator(document)
+	generator.Enum(reflect.TypeFor[testColor](), testColor("red"), testColor("green"))
+
+	// Test case 1: Named structs are referenced
+	schema := generator.Schema(reflect.TypeFor[testNode]())
+	assert.Equal("#/components/schemas/testNode", schema.Ref)
+
+	// Test case 2: Properties follow encoding/json
+	encoded, err := json.Marshal(document.Components.Schemas)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"testColor": {"type": "string", "enum": ["red", "green"]},
+		"testNode": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "string"},
+				"color": {"$ref": "#/components/schemas/testColor"},
+				"label": {"type": "string"},
+				"parent": {"anyOf": [{"$ref": "#/components/schemas/testNode"}, {"type": "null"}]},
+				"children": {"type": "array", "items": {"$ref": "#/components/schemas/testNode"}},
+				"note": {"type": ["string", "null"]},
+				"updated": {"type": "string", "format": "date-time"}
+			},
+			"required": ["id", "color", "parent", "updated"],
+			"additionalProperties": false
+		}
+	}`, string(encoded))
+
+	// Test case 3: Encoded values match their schema
+	node := testNode{testBase: testBase{Id: "1"}, Color: "red", Label: null.New("root"), Note: null.Clear[string](), Updated: time.Now()}
+	node.Children = []testNode{{testBase: testBase{Id: "2"}, Color: "green", Parent: &testNode{Color: "red"}}}
+	encoded, err = json.Marshal(node)
+	assert.NoError(err)
+	assert.NoError(document.ValidateJSON(schema, encoded))
+}
+
+func TestValidateJSON(t *testing.T) {
+	assert := require.New(t)
+	document := New(Info{Title: "test", Version: "v0"})
+	minimum := 1.0
+	schema := Object(map[string]*Schema{
+		"count": {Type: Types{TypeInteger}, Minimum: &minimum},
+		"at":    {Type: Types{TypeString}, Format: "date-time"},
+		"tags":  {Type: Types{TypeArray}, Items: &Schema{Type: Types{TypeString}}},
+	}, "count")
+
+	assert.NoError(document.ValidateJSON(schema, []byte(`{"count":1,"at":"2024-01-01T00:00:00Z","tags":["a"]}`)))
+	for body, message := range map[string]string{
+		`{}`:                        "/: missing property count",
+		`{"count":1.5}`:             "/count: expected integer, got number",
+		`{"count":0}`:               "/count: 0 is less than 1",
+		`{"count":1,"at":"monday"}`: `/at: invalid date-time "monday"`,
+		`{"count":1,"tags":[1]}`:    "/tags/0: expected string, got number",
+		`{"count":1,"other":true}`:  "/other: not allowed",
+		`[]`:                        "/: expected object, got array",
+	} {
+		err := document.ValidateJSON(schema, []byte(body))
+		assert.EqualError(err, message, body)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	assert := require.New(t)
+	document := New(Info{Title: "test", Version: "v0"})
+	document.AddOperation(http.MethodGet, "/item/{id}", &Operation{
+		OperationId: "getItem",
+		Responses: map[string]*Response{
+			"200": {Description: "OK", Content: JSON(Object(map[string]*Schema{"id": {Type: Types{TypeString}}}, "id"))},
+			"204": {Description: "No Content"},
+			"4XX": {Description: "Client error", Content: map[string]*MediaType{"text/plain": {Schema: &Schema{Type: Types{TypeString}}}}},
+		},
+	})
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	// Test case 1: Operations are found by the concrete path
+	operation, found := document.Find(http.MethodGet, "/item/1")
+	assert.True(found)
+	assert.Equal("getItem", operation.OperationId)
+	_, found = document.Find(http.MethodGet, "/item/1/other")
+	assert.False(found)
+	_, found = document.Find(http.MethodPost, "/item/1")
+	assert.False(found)
+
+	// Test case 2: Responses are validated by status and content type
+	assert.NoError(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusOK, header, []byte(`{"id":"1"}`)))
+	assert.NoError(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusNoContent, nil, nil))
+	assert.NoError(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusNotFound, nil, []byte("not found")))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusOK, header, []byte(`{"id":1}`)))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusOK, header, nil))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusNoContent, header, []byte(`{}`)))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusNotFound, header, []byte(`{}`)))
+	assert.Error(document.ValidateResponse(http.MethodGet, "/item/1", http.StatusInternalServerError, nil, []byte("error")))
+
+	// Test case 3: Undocumented operations are not validated
+	assert.NoError(document.ValidateResponse(http.MethodGet, "/other", http.StatusTeapot, nil, nil))
+}
@@ -0,0 +1,266 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSON schema types
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+// Types is encoded as a single type or as a list of types
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Schema is the subset of JSON schema used to describe request and response bodies
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+
+	// closed marks the schema rejecting every value, used to forbid additional properties
+	closed bool
+}
+
+// schema is the plain representation of [Schema], without its MarshalJSON method
+type schema Schema
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.closed {
+		return []byte("false"), nil
+	}
+	return json.Marshal((*schema)(s))
+}
+
+// Object returns the schema of an object with the given properties and no additional properties.
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{
+		Type:                 Types{TypeObject},
+		Properties:           properties,
+		Required:             required,
+		AdditionalProperties: &Schema{closed: true},
+	}
+}
+
+// Nullable returns a schema also accepting null.
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" || len(s.AnyOf) > 0 || len(s.Type) == 0 {
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{TypeNull}}}}
+	}
+	nullable := *s
+	if !slices.Contains(nullable.Type, TypeNull) {
+		nullable.Type = append(slices.Clone(s.Type), TypeNull)
+	}
+	if len(nullable.Enum) > 0 {
+		nullable.Enum = append(slices.Clone(s.Enum), nil)
+	}
+	return &nullable
+}
+
+// nullable is implemented by wrappers encoding as their value or null, like null.Null
+type nullable interface {
+	ValueType() reflect.Type
+}
+
+// presence is implemented by wrappers tracking whether a field was given separately from being null, like null.Patch,
+// omitting them only omits absent fields
+type presence interface {
+	Present() bool
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	nullableType      = reflect.TypeFor[nullable]()
+	presenceType      = reflect.TypeFor[presence]()
+)
+
+var invalidNameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Generator derives schemas from Go types following the rules of encoding/json,
+// named struct and enum types are added to the components of the document and referenced.
+type Generator struct {
+	document *Document
+	names    map[reflect.Type]string
+}
+
+// NewGenerator creates a generator adding its components to the document.
+func NewGenerator(document *Document) *Generator {
+	return &Generator{
+		document: document,
+		names:    make(map[reflect.Type]string),
+	}
+}
+
+// Enum registers the allowed values of a type.
+func (g *Generator) Enum(t reflect.Type, values ...any) {
+	s := g.schema(t, false)
+	s.Enum = values
+	g.names[t] = g.addComponent(t, s)
+}
+
+// Schema returns the schema of a type.
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	return g.schema(t, true)
+}
+
+func (g *Generator) schema(t reflect.Type, reference bool) *Schema {
+	if name, found := g.names[t]; found {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	if t.Implements(nullableType) {
+		value := reflect.Zero(t).Interface().(nullable)
+		return Nullable(g.Schema(value.ValueType()))
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{TypeString}, Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{Description: "any json value"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: Types{TypeString}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return Nullable(g.Schema(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{TypeInteger}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Nullable(&Schema{Type: Types{TypeString}, Format: "byte"})
+		}
+		return Nullable(&Schema{Type: Types{TypeArray}, Items: g.Schema(t.Elem())})
+	case reflect.Array:
+		return &Schema{Type: Types{TypeArray}, Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return Nullable(&Schema{Type: Types{TypeObject}, AdditionalProperties: g.Schema(t.Elem())})
+	case reflect.Struct:
+		if !reference || t.Name() == "" {
+			return g.object(t)
+		}
+		// register the name first, so recursive types reference themselves
+		name := g.componentName(t)
+		g.names[t] = name
+		g.document.Components.Schemas[name] = g.object(t)
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := Object(make(map[string]*Schema))
+	g.fields(t, s)
+	return s
+}
+
+// fields adds the properties of a struct, fields of embedded structs are promoted like in encoding/json
+func (g *Generator) fields(t reflect.Type, s *Schema) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.fields(field.Type, s)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.Schema(field.Type)
+		omitted := slices.ContainsFunc(strings.Split(options, ","), func(option string) bool {
+			return option == "omitempty" || option == "omitzero"
+		})
+		if omitted {
+			// null, nil slices and nil maps are omitted instead of encoded as null
+			if !field.Type.Implements(presenceType) {
+				property = nonNullable(property)
+			}
+		} else {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = property
+	}
+}
+
+// nonNullable reverts [Nullable]
+func nonNullable(s *Schema) *Schema {
+	if len(s.AnyOf) == 2 && slices.Equal(s.AnyOf[1].Type, Types{TypeNull}) {
+		return s.AnyOf[0]
+	}
+	if !slices.Contains(s.Type, TypeNull) {
+		return s
+	}
+	nonNullable := *s
+	nonNullable.Type = slices.DeleteFunc(slices.Clone(s.Type), func(t string) bool { return t == TypeNull })
+	nonNullable.Enum = slices.DeleteFunc(slices.Clone(s.Enum), func(value any) bool { return value == nil })
+	return &nonNullable
+}
+
+func (g *Generator) componentName(t reflect.Type) string {
+	base := invalidNameCharacters.ReplaceAllString(t.Name(), "_")
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := g.document.Components.Schemas[name]; !taken {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+func (g *Generator) addComponent(t reflect.Type, s *Schema) string {
+	name := g.componentName(t)
+	g.document.Components.Schemas[name] = s
+	return name
+}
@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidateResponse checks a response to a request for the method and path against the document.
+// Responses of undocumented operations are not validated.
+func (d *Document) ValidateResponse(method string, path string, status int, header http.Header, body []byte) error {
+	operation, found := d.Find(method, path)
+	if !found {
+		return nil
+	}
+	response, found := operation.Response(status)
+	if !found {
+		return fmt.Errorf("%s %s: status %d is not documented", method, path, status)
+	}
+	if len(body) == 0 {
+		if len(response.Content) > 0 && status != http.StatusNoContent {
+			return fmt.Errorf("%s %s: status %d: missing body", method, path, status)
+		}
+		return nil
+	}
+	if len(response.Content) == 0 {
+		return fmt.Errorf("%s %s: status %d: body is not documented", method, path, status)
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		// like net/http, which detects the content type of responses without one
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%s %s: status %d: %w", method, path, status, err)
+	}
+	if _, found := response.Content[mediaType]; !found {
+		return fmt.Errorf("%s %s: status %d: content type %s is not documented", method, path, status, mediaType)
+	}
+
+	if !strings.HasSuffix(mediaType, "json") {
+		return nil
+	}
+	if err := d.ValidateJSON(response.Content[mediaType].Schema, body); err != nil {
+		return fmt.Errorf("%s %s: status %d: %w", method, path, status, err)
+	}
+	return nil
+}
+
+// ValidateJSON checks an encoded JSON value against a schema.
+func (d *Document) ValidateJSON(schema *Schema, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	return d.validate(schema, value, "")
+}
+
+func (d *Document) validate(schema *Schema, value any, pointer string) error {
+	if schema.closed {
+		return fmt.Errorf("%s: not allowed", location(pointer))
+	}
+	if schema.Ref != "" {
+		name, found := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		referenced, exists := d.Components.Schemas[name]
+		if !found || !exists {
+			return fmt.Errorf("%s: unresolved reference %s", location(pointer), schema.Ref)
+		}
+		return d.validate(referenced, value, pointer)
+	}
+	if len(schema.AnyOf) > 0 {
+		var errs []error
+		for _, option := range schema.AnyOf {
+			err := d.validate(option, value, pointer)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(allowed any) bool { return equal(allowed, value) }) {
+		return fmt.Errorf("%s: %v is not one of %v", location(pointer), value, schema.Enum)
+	}
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(t string) bool { return hasType(value, t) }) {
+		return fmt.Errorf("%s: expected %s, got %s", location(pointer), strings.Join(schema.Type, " or "), typeOf(value))
+	}
+
+	switch value := value.(type) {
+	case json.Number:
+		number, _ := value.Float64()
+		if schema.Minimum != nil && number < *schema.Minimum {
+			return fmt.Errorf("%s: %v is less than %v", location(pointer), number, *schema.Minimum)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			return fmt.Errorf("%s: %v is greater than %v", location(pointer), number, *schema.Maximum)
+		}
+	case string:
+		if err := validateFormat(schema.Format, value); err != nil {
+			return fmt.Errorf("%s: %w", location(pointer), err)
+		}
+	case []any:
+		if schema.Items == nil {
+			return nil
+		}
+		var errs []error
+		for i, item := range value {
+			errs = append(errs, d.validate(schema.Items, item, pointer+"/"+strconv.Itoa(i)))
+		}
+		return errors.Join(errs...)
+	case map[string]any:
+		var errs []error
+		for _, name := range schema.Required {
+			if _, found := value[name]; !found {
+				errs = append(errs, fmt.Errorf("%s: missing property %s", location(pointer), name))
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(value)) {
+			property, found := schema.Properties[name]
+			if !found {
+				property = schema.AdditionalProperties
+			}
+			if property != nil {
+				errs = append(errs, d.validate(property, value[name], pointer+"/"+escapePointer(name)))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func validateFormat(format string, value string) error {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return fmt.Errorf("invalid date-time %q", value)
+		}
+	case "uuid":
+		if err := uuid.Validate(value); err != nil {
+			return fmt.Errorf("invalid uuid %q", value)
+		}
+	}
+	return nil
+}
+
+func hasType(value any, t string) bool {
+	switch t {
+	case TypeNull:
+		return value == nil
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeNumber:
+		_, ok := value.(json.Number)
+		return ok
+	case TypeInteger:
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		float, err := number.Float64()
+		return err == nil && float == math.Trunc(float)
+	case TypeArray:
+		_, ok := value.([]any)
+		return ok
+	case TypeObject:
+		_, ok := value.(map[string]any)
+		return ok
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	for _, t := range []string{TypeNull, TypeBoolean, TypeString, TypeNumber, TypeArray, TypeObject} {
+		if hasType(value, t) {
+			return t
+		}
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// equal compares a decoded value with an enum value of a Go type, e.g. a named string type
+func equal(allowed any, value any) bool {
+	if allowed == nil || value == nil {
+		return allowed == value
+	}
+	encoded, err := json.Marshal(allowed)
+	if err != nil {
+		return false
+	}
+	actual, err := json.Marshal(value)
+	return err == nil && bytes.Equal(encoded, actual)
+}
+
+// escapePointer escapes a property name as a JSON pointer token (RFC 6901)
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func location(pointer string) string {
+	if pointer == "" {
+		return "/"
+	}
+	return pointer
+}
@@ -0,0 +1,143 @@
+// Package outbox delivers the events stored in the outbox of the storage to sinks like webhooks or files.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+)
+
+const (
+	// DefaultInterval is the time between two polls of the outbox
+	DefaultInterval = time.Second
+
+	// batchSize is the maximum number of entries delivered per poll
+	batchSize = 100
+	// maxBackoff is the longest delay between two attempts, entries are retried until all sinks succeed
+	maxBackoff = 5 * time.Minute
+)
+
+// Dispatcher delivers the pending entries of the outbox to all sinks.
+// An entry is removed once every sink received it, a sink failing is retried without delivering to the others again.
+type Dispatcher struct {
+	storage  persistence.Storage
+	sinks    []Sink
+	interval time.Duration
+}
+
+type Option func(*Dispatcher)
+
+// WithInterval sets the time between two polls of the outbox.
+func WithInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+func NewDispatcher(
+	storage persistence.Storage,
+	sinks []Sink,
+	options ...Option,
+) *Dispatcher {
+	d := &Dispatcher{
+		storage:  storage,
+		sinks:    sinks,
+		interval: DefaultInterval,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Run polls the outbox until the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil {
+			slog.Error("dispatching outbox failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch delivers every entry which is due and returns the number of entries delivered to all sinks.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	entries, err := d.storage.Outbox().Pending(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return completed, ctx.Err()
+		}
+		done, err := d.deliver(ctx, entry)
+		if err != nil {
+			return completed, err
+		}
+		if done {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// deliver passes the entry to the sinks which didn't receive it yet and reports whether all sinks succeeded
+func (d *Dispatcher) deliver(ctx context.Context, entry *domain.OutboxEntry) (bool, error) {
+	var failures error
+	for _, sink := range d.sinks {
+		if slices.Contains(entry.Delivered, sink.Name()) {
+			continue
+		}
+		if err := sink.Deliver(ctx, &entry.Event); err != nil {
+			slog.Error("delivering event failed", "sink", sink.Name(), "event", entry.Event.Id, "error", err)
+			failures = errors.Join(failures, err)
+			continue
+		}
+		entry.Delivered = append(entry.Delivered, sink.Name())
+	}
+
+	if failures == nil {
+		if err := d.storage.Outbox().Delete(ctx, entry.Event.Id); err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			slog.Error("failed deleting outbox entry", "error", err)
+			return false, err
+		}
+		return true, nil
+	}
+
+	entry.Attempts++
+	entry.LastError = failures.Error()
+	entry.NextAttemptAt = time.Now().Add(backoff(entry.Attempts))
+	if err := d.storage.Outbox().Update(ctx, entry); err != nil {
+		slog.Error("failed updating outbox entry", "error", err)
+		return false, err
+	}
+	return false, nil
+}
+
+// backoff doubles the delay with every failed attempt, starting at a second
+func backoff(attempts int) time.Duration {
+	delay := time.Second
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink remembers the events it received and fails while failures is positive
+type recordingSink struct {
+	name     string
+	failures int
+	events   []uuid.UUID
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Deliver(_ context.Context, event *domain.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, event.Id)
+	return nil
+}
+
+func appendEvent(assert *require.Assertions, storage persistence.Storage) *domain.Event {
+	event, err := domain.NewEvent(&domain.Device{Id: uuid.New()}, domain.EventSignatureCreated, map[string]int{"signature_counter": 1}, time.Now())
+	assert.NoError(err)
+	assert.NoError(storage.Outbox().Append(context.Background(), event))
+	return event
+}
+
+func TestDispatcher(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	storage := persistence.NewMemoryStorage()
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", failures: 1}
+	dispatcher := NewDispatcher(storage, []Sink{healthy, flaky})
+
+	first := appendEvent(assert, storage)
+	second := appendEvent(assert, storage)
+
+	// Test case 1: Events are delivered in order, entries of a failing sink are kept
+	completed, err := dispatcher.Dispatch(ctx)
+	assert.NoError(err)
+	assert.Equal(1, completed)
+	assert.Equal([]uuid.UUID{first.Id, second.Id}, healthy.events)
+	assert.Equal([]uuid.UUID{second.Id}, flaky.events)
+
+	pending, err := storage.Outbox().Pending(ctx, time.Now().Add(time.Hour), 0)
+	assert.NoError(err)
+	assert.Len(pending, 1)
+	assert.Equal(first.Id, pending[0].Event.Id)
+	assert.Equal([]string{"healthy"}, pending[0].Delivered)
+	assert.Equal(1, pending[0].Attempts)
+	assert.Equal("sink unavailable", pending[0].LastError)
+
+	// Test case 2: The entry is retried after the backoff, only with the failed sink
+	completed, err = dispatcher.Dispatch(ctx)
+	assert.NoError(err)
+	assert.Equal(0, completed)
+
+	pending[0].NextAttemptAt = time.Now()
+	assert.NoError(storage.Outbox().Update(ctx, pending[0]))
+	completed, err = dispatcher.Dispatch(ctx)
+	assert.NoError(err)
+	assert.Equal(1, completed)
+	assert.Equal([]uuid.UUID{first.Id, second.Id}, healthy.events)
+	assert.Equal([]uuid.UUID{second.Id, first.Id}, flaky.events)
+
+	pending, err = storage.Outbox().Pending(ctx, time.Now().Add(time.Hour), 0)
+	assert.NoError(err)
+	assert.Empty(pending)
+}
+
+func TestFileSink(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := NewFileSink(path)
+	assert.NoError(err)
+	defer sink.Close()
+
+	storage := persistence.NewMemoryStorage()
+	first := appendEvent(assert, storage)
+	second := appendEvent(assert, storage)
+	_, err = NewDispatcher(storage, []Sink{sink}).Dispatch(ctx)
+	assert.NoError(err)
+
+	file, err := os.Open(path)
+	assert.NoError(err)
+	defer file.Close()
+
+	var records []fileRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record fileRecord
+		assert.NoError(json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	assert.Len(records, 2)
+	assert.Equal(first.Id.String(), records[0].Id)
+	assert.Equal(second.Id.String(), records[1].Id)
+	assert.Equal(domain.EventSignatureCreated, records[0].Type)
+	assert.JSONEq(`{"signature_counter":1}`, string(records[0].Data))
+}
+
+func TestBackoff(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(time.Second, backoff(1))
+	assert.Equal(4*time.Second, backoff(3))
+	assert.Equal(maxBackoff, backoff(30))
+}
@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+)
+
+// Sink receives the events of the outbox.
+// Events are delivered at least once, sinks have to tolerate duplicates, e.g. by the event id.
+type Sink interface {
+	// Name identifies the sink, it is stored with the entries to remember which sinks received an event
+	Name() string
+	Deliver(ctx context.Context, event *domain.Event) error
+}
+
+// fileRecord is a line written by the [FileSink]
+type fileRecord struct {
+	Id             string           `json:"id"`
+	Type           domain.EventType `json:"type"`
+	OrganizationId string           `json:"organization_id"`
+	DeviceId       string           `json:"device_id"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Data           json.RawMessage  `json:"data"`
+}
+
+// LogSink writes events to the structured log.
+type LogSink struct {
+	logger *slog.Logger
+}
+
+func NewLogSink(logger *slog.Logger) *LogSink {
+	return &LogSink{
+		logger: logger,
+	}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Deliver(ctx context.Context, event *domain.Event) error {
+	s.logger.InfoContext(ctx, "event",
+		"id", event.Id,
+		"type", event.Type,
+		"organization", event.OrganizationId,
+		"device", event.DeviceId,
+		"data", string(event.Data),
+	)
+	return nil
+}
+
+// FileSink appends events as JSON lines to a file, e.g. to be shipped by a log collector.
+type FileSink struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileSink opens the file for appending, it is created if it doesn't exist.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{
+		file: file,
+	}, nil
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+func (s *FileSink) Deliver(_ context.Context, event *domain.Event) error {
+	line, err := json.Marshal(fileRecord{
+		Id:             event.Id.String(),
+		Type:           event.Type,
+		OrganizationId: event.OrganizationId.String(),
+		DeviceId:       event.DeviceId.String(),
+		CreatedAt:      event.CreatedAt,
+		Data:           event.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	// the entry is removed from the outbox after delivery, so the line has to be on disk by then
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,346 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// Observer is notified about every operation of a repository, e.g. to record metrics or traces.
+// It returns the context to run the operation with and a function which is called with its result.
+type Observer func(ctx context.Context, repository string, operation string) (context.Context, func(err error))
+
+// Instrument wraps storage, so every operation of its repositories is reported to the observer,
+// including the operations within transactions.
+func Instrument(storage Storage, observer Observer) Storage {
+	return &instrumentedStorage{storage: storage, observe: observer}
+}
+
+type instrumentedStorage struct {
+	storage Storage
+	observe Observer
+}
+
+func (s *instrumentedStorage) Devices() domain.DeviceRepository {
+	return &instrumentedDevices{s.storage.Devices(), s.observe}
+}
+
+func (s *instrumentedStorage) Signatures() domain.SignatureRepository {
+	return &instrumentedSignatures{s.storage.Signatures(), s.observe}
+}
+
+func (s *instrumentedStorage) Organizations() domain.OrganizationRepository {
+	return &instrumentedOrganizations{s.storage.Organizations(), s.observe}
+}
+
+func (s *instrumentedStorage) APIKeys() domain.APIKeyRepository {
+	return &instrumentedAPIKeys{s.storage.APIKeys(), s.observe}
+}
+
+func (s *instrumentedStorage) Idempotency() domain.IdempotencyRepository {
+	return &instrumentedIdempotency{s.storage.Idempotency(), s.observe}
+}
+
+func (s *instrumentedStorage) Webhooks() domain.WebhookRepository {
+	return &instrumentedWebhooks{s.storage.Webhooks(), s.observe}
+}
+
+func (s *instrumentedStorage) WebhookDeliveries() domain.WebhookDeliveryRepository {
+	return &instrumentedWebhookDeliveries{s.storage.WebhookDeliveries(), s.observe}
+}
+
+func (s *instrumentedStorage) Outbox() domain.OutboxRepository {
+	return &instrumentedOutbox{s.storage.Outbox(), s.observe}
+}
+
+func (s *instrumentedStorage) WithTransaction(ctx context.Context, fn func(ctx context.Context, s Storage) error) error {
+	ctx, done := s.observe(ctx, "storage", "transaction")
+	err := s.storage.WithTransaction(ctx, func(ctx context.Context, storage Storage) error {
+		return fn(ctx, Instrument(storage, s.observe))
+	})
+	done(err)
+	return err
+}
+
+func (s *instrumentedStorage) Health(ctx context.Context) error {
+	ctx, done := s.observe(ctx, "storage", "health")
+	err := s.storage.Health(ctx)
+	done(err)
+	return err
+}
+
+func (s *instrumentedStorage) Stats(ctx context.Context) (Stats, error) {
+	return observe(ctx, s.observe, "storage", "stats", s.storage.Stats)
+}
+
+func (s *instrumentedStorage) Close() error {
+	return s.storage.Close()
+}
+
+// observe runs an operation returning a value with the observer
+func observe[T any](ctx context.Context, observer Observer, repository string, operation string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, done := observer(ctx, repository, operation)
+	result, err := fn(ctx)
+	done(err)
+	return result, err
+}
+
+// observeErr runs an operation only returning an error with the observer
+func observeErr(ctx context.Context, observer Observer, repository string, operation string, fn func(ctx context.Context) error) error {
+	ctx, done := observer(ctx, repository, operation)
+	err := fn(ctx)
+	done(err)
+	return err
+}
+
+type instrumentedDevices struct {
+	repository domain.DeviceRepository
+	observe    Observer
+}
+
+func (r *instrumentedDevices) Create(ctx context.Context, device *domain.Device) error {
+	return observeErr(ctx, r.observe, "devices", "create", func(ctx context.Context) error {
+		return r.repository.Create(ctx, device)
+	})
+}
+
+func (r *instrumentedDevices) GetByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	return observe(ctx, r.observe, "devices", "get", func(ctx context.Context) (*domain.Device, error) {
+		return r.repository.GetByID(ctx, id)
+	})
+}
+
+func (r *instrumentedDevices) List(ctx context.Context, filter domain.DeviceFilter) ([]*domain.Device, error) {
+	return observe(ctx, r.observe, "devices", "list", func(ctx context.Context) ([]*domain.Device, error) {
+		return r.repository.List(ctx, filter)
+	})
+}
+
+func (r *instrumentedDevices) Update(ctx context.Context, device *domain.Device, fencingToken uint64) error {
+	return observeErr(ctx, r.observe, "devices", "update", func(ctx context.Context) error {
+		return r.repository.Update(ctx, device, fencingToken)
+	})
+}
+
+func (r *instrumentedDevices) Delete(ctx context.Context, id uuid.UUID) error {
+	return observeErr(ctx, r.observe, "devices", "delete", func(ctx context.Context) error {
+		return r.repository.Delete(ctx, id)
+	})
+}
+
+func (r *instrumentedDevices) Count(ctx context.Context, filter domain.DeviceFilter) (int64, error) {
+	return observe(ctx, r.observe, "devices", "count", func(ctx context.Context) (int64, error) {
+		return r.repository.Count(ctx, filter)
+	})
+}
+
+type instrumentedSignatures struct {
+	repository domain.SignatureRepository
+	observe    Observer
+}
+
+func (r *instrumentedSignatures) Create(ctx context.Context, signature *domain.Signature) error {
+	return observeErr(ctx, r.observe, "signatures", "create", func(ctx context.Context) error {
+		return r.repository.Create(ctx, signature)
+	})
+}
+
+func (r *instrumentedSignatures) List(ctx context.Context, deviceId uuid.UUID, afterCounter int, limit int) ([]*domain.Signature, error) {
+	return observe(ctx, r.observe, "signatures", "list", func(ctx context.Context) ([]*domain.Signature, error) {
+		return r.repository.List(ctx, deviceId, afterCounter, limit)
+	})
+}
+
+func (r *instrumentedSignatures) DeleteByDevice(ctx context.Context, deviceId uuid.UUID) error {
+	return observeErr(ctx, r.observe, "signatures", "delete_by_device", func(ctx context.Context) error {
+		return r.repository.DeleteByDevice(ctx, deviceId)
+	})
+}
+
+type instrumentedOrganizations struct {
+	repository domain.OrganizationRepository
+	observe    Observer
+}
+
+func (r *instrumentedOrganizations) Create(ctx context.Context, organization *domain.Organization) error {
+	return observeErr(ctx, r.observe, "organizations", "create", func(ctx context.Context) error {
+		return r.repository.Create(ctx, organization)
+	})
+}
+
+func (r *instrumentedOrganizations) GetByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	return observe(ctx, r.observe, "organizations", "get", func(ctx context.Context) (*domain.Organization, error) {
+		return r.repository.GetByID(ctx, id)
+	})
+}
+
+func (r *instrumentedOrganizations) List(ctx context.Context) ([]*domain.Organization, error) {
+	return observe(ctx, r.observe, "organizations", "list", func(ctx context.Context) ([]*domain.Organization, error) {
+		return r.repository.List(ctx)
+	})
+}
+
+func (r *instrumentedOrganizations) Delete(ctx context.Context, id uuid.UUID) error {
+	return observeErr(ctx, r.observe, "organizations", "delete", func(ctx context.Context) error {
+		return r.repository.Delete(ctx, id)
+	})
+}
+
+type instrumentedAPIKeys struct {
+	repository domain.APIKeyRepository
+	observe    Observer
+}
+
+func (r *instrumentedAPIKeys) Create(ctx context.Context, key *domain.APIKey) error {
+	return observeErr(ctx, r.observe, "api_keys", "create", func(ctx context.Context) error {
+		return r.repository.Create(ctx, key)
+	})
+}
+
+func (r *instrumentedAPIKeys) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	return observe(ctx, r.observe, "api_keys", "get", func(ctx context.Context) (*domain.APIKey, error) {
+		return r.repository.GetByID(ctx, id)
+	})
+}
+
+func (r *instrumentedAPIKeys) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	return observe(ctx, r.observe, "api_keys", "get_by_hash", func(ctx context.Context) (*domain.APIKey, error) {
+		return r.repository.GetByHash(ctx, hash)
+	})
+}
+
+func (r *instrumentedAPIKeys) List(ctx context.Context, organizationId uuid.UUID) ([]*domain.APIKey, error) {
+	return observe(ctx, r.observe, "api_keys", "list", func(ctx context.Context) ([]*domain.APIKey, error) {
+		return r.repository.List(ctx, organizationId)
+	})
+}
+
+func (r *instrumentedAPIKeys) Update(ctx context.Context, key *domain.APIKey) error {
+	return observeErr(ctx, r.observe, "api_keys", "update", func(ctx context.Context) error {
+		return r.repository.Update(ctx, key)
+	})
+}
+
+type instrumentedIdempotency struct {
+	repository domain.IdempotencyRepository
+	observe    Observer
+}
+
+func (r *instrumentedIdempotency) Get(ctx context.Context, deviceId uuid.UUID, key string) (*domain.IdempotencyRecord, error) {
+	return observe(ctx, r.observe, "idempotency", "get", func(ctx context.Context) (*domain.IdempotencyRecord, error) {
+		return r.repository.Get(ctx, deviceId, key)
+	})
+}
+
+func (r *instrumentedIdempotency) Save(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return observeErr(ctx, r.observe, "idempotency", "save", func(ctx context.Context) error {
+		return r.repository.Save(ctx, record)
+	})
+}
+
+func (r *instrumentedIdempotency) DeleteExpired(ctx context.Context, now time.Time) error {
+	return observeErr(ctx, r.observe, "idempotency", "delete_expired", func(ctx context.Context) error {
+		return r.repository.DeleteExpired(ctx, now)
+	})
+}
+
+func (r *instrumentedIdempotency) DeleteByDevice(ctx context.Context, deviceId uuid.UUID) error {
+	return observeErr(ctx, r.observe, "idempotency", "delete_by_device", func(ctx context.Context) error {
+		return r.repository.DeleteByDevice(ctx, deviceId)
+	})
+}
+
+type instrumentedWebhooks struct {
+	repository domain.WebhookRepository
+	observe    Observer
+}
+
+func (r *instrumentedWebhooks) Create(ctx context.Context, webhook *domain.Webhook) error {
+	return observeErr(ctx, r.observe, "webhooks", "create", func(ctx context.Context) error {
+		return r.repository.Create(ctx, webhook)
+	})
+}
+
+func (r *instrumentedWebhooks) GetByID(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	return observe(ctx, r.observe, "webhooks", "get", func(ctx context.Context) (*domain.Webhook, error) {
+		return r.repository.GetByID(ctx, id)
+	})
+}
+
+func (r *instrumentedWebhooks) List(ctx context.Context, organizationId uuid.UUID) ([]*domain.Webhook, error) {
+	return observe(ctx, r.observe, "webhooks", "list", func(ctx context.Context) ([]*domain.Webhook, error) {
+		return r.repository.List(ctx, organizationId)
+	})
+}
+
+func (r *instrumentedWebhooks) Delete(ctx context.Context, id uuid.UUID) error {
+	return observeErr(ctx, r.observe, "webhooks", "delete", func(ctx context.Context) error {
+		return r.repository.Delete(ctx, id)
+	})
+}
+
+type instrumentedWebhookDeliveries struct {
+	repository domain.WebhookDeliveryRepository
+	observe    Observer
+}
+
+func (r *instrumentedWebhookDeliveries) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return observeErr(ctx, r.observe, "webhook_deliveries", "create", func(ctx context.Context) error {
+		return r.repository.Create(ctx, delivery)
+	})
+}
+
+func (r *instrumentedWebhookDeliveries) List(ctx context.Context, webhookId uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	return observe(ctx, r.observe, "webhook_deliveries", "list", func(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+		return r.repository.List(ctx, webhookId, limit)
+	})
+}
+
+func (r *instrumentedWebhookDeliveries) Due(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	return observe(ctx, r.observe, "webhook_deliveries", "due", func(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+		return r.repository.Due(ctx, now, limit)
+	})
+}
+
+func (r *instrumentedWebhookDeliveries) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return observeErr(ctx, r.observe, "webhook_deliveries", "update", func(ctx context.Context) error {
+		return r.repository.Update(ctx, delivery)
+	})
+}
+
+func (r *instrumentedWebhookDeliveries) DeleteCompleted(ctx context.Context, before time.Time) error {
+	return observeErr(ctx, r.observe, "webhook_deliveries", "delete_completed", func(ctx context.Context) error {
+		return r.repository.DeleteCompleted(ctx, before)
+	})
+}
+
+type instrumentedOutbox struct {
+	repository domain.OutboxRepository
+	observe    Observer
+}
+
+func (r *instrumentedOutbox) Append(ctx context.Context, event *domain.Event) error {
+	return observeErr(ctx, r.observe, "outbox", "append", func(ctx context.Context) error {
+		return r.repository.Append(ctx, event)
+	})
+}
+
+func (r *instrumentedOutbox) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEntry, error) {
+	return observe(ctx, r.observe, "outbox", "pending", func(ctx context.Context) ([]*domain.OutboxEntry, error) {
+		return r.repository.Pending(ctx, now, limit)
+	})
+}
+
+func (r *instrumentedOutbox) Update(ctx context.Context, entry *domain.OutboxEntry) error {
+	return observeErr(ctx, r.observe, "outbox", "update", func(ctx context.Context) error {
+		return r.repository.Update(ctx, entry)
+	})
+}
+
+func (r *instrumentedOutbox) Delete(ctx context.Context, eventId uuid.UUID) error {
+	return observeErr(ctx, r.observe, "outbox", "delete", func(ctx context.Context) error {
+		return r.repository.Delete(ctx, eventId)
+	})
+}
@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"bytes"
 	"context"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -13,15 +15,27 @@ import (
 // TODO: in-memory persistence ...
 
 type MemoryStorage struct {
-	devices *deviceRepository
-	mu      sync.RWMutex
+	devices       *deviceRepository
+	organizations *organizationRepository
+	apiKeys       *apiKeyRepository
+	idempotency   *idempotencyRepository
+	webhooks      *webhookRepository
+	deliveries    *webhookDeliveryRepository
+	outbox        *outboxRepository
+	signatures    *signatureRepository
+	mu            sync.RWMutex
 }
 
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		devices: &deviceRepository{
-			data: make(map[uuid.UUID]*domain.Device),
-		},
+		devices:       newDeviceRepository(),
+		organizations: newOrganizationRepository(),
+		apiKeys:       newAPIKeyRepository(),
+		idempotency:   newIdempotencyRepository(),
+		webhooks:      newWebhookRepository(),
+		deliveries:    newWebhookDeliveryRepository(),
+		outbox:        newOutboxRepository(),
+		signatures:    newSignatureRepository(),
 	}
 }
 
@@ -29,6 +43,34 @@ func (m *MemoryStorage) Devices() domain.DeviceRepository {
 	return m.devices
 }
 
+func (m *MemoryStorage) Signatures() domain.SignatureRepository {
+	return m.signatures
+}
+
+func (m *MemoryStorage) Organizations() domain.OrganizationRepository {
+	return m.organizations
+}
+
+func (m *MemoryStorage) APIKeys() domain.APIKeyRepository {
+	return m.apiKeys
+}
+
+func (m *MemoryStorage) Idempotency() domain.IdempotencyRepository {
+	return m.idempotency
+}
+
+func (m *MemoryStorage) Webhooks() domain.WebhookRepository {
+	return m.webhooks
+}
+
+func (m *MemoryStorage) WebhookDeliveries() domain.WebhookDeliveryRepository {
+	return m.deliveries
+}
+
+func (m *MemoryStorage) Outbox() domain.OutboxRepository {
+	return m.outbox
+}
+
 func (m *MemoryStorage) WithTransaction(ctx context.Context, fn func(ctx context.Context, s Storage) error) error {
 	// For in-memory storage, we can implement simple locking
 	// In a real database implementation; this would start a DB transaction
@@ -43,16 +85,58 @@ func (m *MemoryStorage) Health(_ context.Context) error {
 	return nil
 }
 
+func (m *MemoryStorage) Stats(_ context.Context) (Stats, error) {
+	return Stats{
+		Backend: "memory",
+		Records: map[string]int{
+			"devices":            count(&m.devices.mu, func() int { return len(m.devices.data) }),
+			"signatures":         count(&m.signatures.mu, m.signatures.count),
+			"organizations":      count(&m.organizations.mu, func() int { return len(m.organizations.data) }),
+			"api_keys":           count(&m.apiKeys.mu, func() int { return len(m.apiKeys.data) }),
+			"idempotency":        count(&m.idempotency.mu, func() int { return len(m.idempotency.data) }),
+			"webhooks":           count(&m.webhooks.mu, func() int { return len(m.webhooks.data) }),
+			"webhook_deliveries": count(&m.deliveries.mu, func() int { return len(m.deliveries.data) }),
+			"outbox":             count(&m.outbox.mu, func() int { return len(m.outbox.data) }),
+		},
+	}, nil
+}
+
+// count reads the size of a repository under its read lock
+func count(mu *sync.RWMutex, size func() int) int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return size()
+}
+
 func (m *MemoryStorage) Close() error {
 	return nil
 }
 
 type deviceRepository struct {
 	data map[uuid.UUID]*domain.Device
-	mu   sync.RWMutex
+	// secondary indexes, so filtering by tags and metadata doesn't have to scan all devices
+	tags     map[string]map[uuid.UUID]struct{}
+	metadata map[string]map[uuid.UUID]struct{}
+	// tokens holds the highest fencing token each device was updated with
+	tokens map[uuid.UUID]uint64
+	mu     sync.RWMutex
+}
+
+func newDeviceRepository() *deviceRepository {
+	return &deviceRepository{
+		data:     make(map[uuid.UUID]*domain.Device),
+		tags:     make(map[string]map[uuid.UUID]struct{}),
+		metadata: make(map[string]map[uuid.UUID]struct{}),
+		tokens:   make(map[uuid.UUID]uint64),
+	}
 }
 
-func (r *deviceRepository) Create(_ context.Context, device *domain.Device) error {
+func (r *deviceRepository) Create(ctx context.Context, device *domain.Device) error {
+	organizationId, err := organizationScope(ctx)
+	if err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -60,10 +144,13 @@ func (r *deviceRepository) Create(_ context.Context, device *domain.Device) erro
 		return ErrInvalidInput
 	}
 
+	// device ids are unique across all organizations
 	if _, exists := r.data[device.Id]; exists {
 		return ErrAlreadyExists
 	}
 
+	device.OrganizationId = organizationId
+
 	now := time.Now()
 	if device.CreatedAt.IsZero() {
 		device.CreatedAt = now
@@ -71,36 +158,51 @@ func (r *deviceRepository) Create(_ context.Context, device *domain.Device) erro
 	device.UpdatedAt = now
 
 	r.data[device.Id] = device.Copy()
+	r.index(device)
 
 	return nil
 }
 
-func (r *deviceRepository) GetByID(_ context.Context, id uuid.UUID) (*domain.Device, error) {
+func (r *deviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	organizationId, err := organizationScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	device, exists := r.data[id]
-	if !exists {
+	if !exists || device.OrganizationId != organizationId {
 		return nil, ErrNotFound
 	}
 
 	return device.Copy(), nil
 }
 
-func (r *deviceRepository) List(_ context.Context, filter domain.DeviceFilter) ([]*domain.Device, error) {
+func (r *deviceRepository) List(ctx context.Context, filter domain.DeviceFilter) ([]*domain.Device, error) {
+	organizationId, err := organizationScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var devices []*domain.Device
 
-	for _, device := range r.data {
-		if r.matchesFilter(device, filter) {
+	for _, device := range r.candidates(filter) {
+		if r.matchesFilter(device, organizationId, filter) {
 			devices = append(devices, device.Copy())
 		}
 	}
 
+	// ordered by creation and id, so pages of the same filter don't overlap
 	sort.Slice(devices, func(i, j int) bool {
-		return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+		if !devices[i].CreatedAt.Equal(devices[j].CreatedAt) {
+			return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+		}
+		return bytes.Compare(devices[i].Id[:], devices[j].Id[:]) < 0
 	})
 
 	start := filter.Offset
@@ -116,44 +218,70 @@ func (r *deviceRepository) List(_ context.Context, filter domain.DeviceFilter) (
 	return devices[start:end], nil
 }
 
-func (r *deviceRepository) Update(_ context.Context, device *domain.Device) error {
+func (r *deviceRepository) Update(ctx context.Context, device *domain.Device, fencingToken uint64) error {
+	organizationId, err := organizationScope(ctx)
+	if err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	existing, exists := r.data[device.Id]
-	if !exists {
+	if !exists || existing.OrganizationId != organizationId {
 		return ErrNotFound
 	}
+	if fencingToken != 0 {
+		if fencingToken < r.tokens[device.Id] {
+			return ErrStaleToken
+		}
+		r.tokens[device.Id] = fencingToken
+	}
 
 	device.UpdatedAt = time.Now()
 	device.CreatedAt = existing.CreatedAt
+	device.OrganizationId = existing.OrganizationId
 
+	r.unindex(existing)
 	r.data[device.Id] = device.Copy()
+	r.index(device)
 
 	return nil
 }
 
-func (r *deviceRepository) Delete(_ context.Context, id uuid.UUID) error {
+func (r *deviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	organizationId, err := organizationScope(ctx)
+	if err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.data[id]
-	if !exists {
+	existing, exists := r.data[id]
+	if !exists || existing.OrganizationId != organizationId {
 		return ErrNotFound
 	}
 
+	r.unindex(existing)
 	delete(r.data, id)
+	delete(r.tokens, id)
 
 	return nil
 }
 
-func (r *deviceRepository) Count(_ context.Context, filter domain.DeviceFilter) (int64, error) {
+func (r *deviceRepository) Count(ctx context.Context, filter domain.DeviceFilter) (int64, error) {
+	organizationId, err := organizationScope(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	count := int64(0)
-	for _, device := range r.data {
-		if r.matchesFilter(device, filter) {
+	for _, device := range r.candidates(filter) {
+		if r.matchesFilter(device, organizationId, filter) {
 			count++
 		}
 	}
@@ -161,7 +289,18 @@ func (r *deviceRepository) Count(_ context.Context, filter domain.DeviceFilter)
 	return count, nil
 }
 
-func (r *deviceRepository) matchesFilter(device *domain.Device, filter domain.DeviceFilter) bool {
+func (r *deviceRepository) matchesFilter(device *domain.Device, organizationId uuid.UUID, filter domain.DeviceFilter) bool {
+	// Check organization scope, devices of other organizations are never visible
+	if device.OrganizationId != organizationId {
+		return false
+	}
+	if filter.OrganizationId.Valid && device.OrganizationId != filter.OrganizationId.UUID {
+		return false
+	}
+	if filter.SigningAlgorithm != "" && device.SigningAlgorithm != filter.SigningAlgorithm {
+		return false
+	}
+
 	// Check ID filter
 	if len(filter.IDs) > 0 {
 		found := false
@@ -176,5 +315,96 @@ func (r *deviceRepository) matchesFilter(device *domain.Device, filter domain.De
 		}
 	}
 
+	// Check tag filter
+	for _, tag := range filter.Tags {
+		if !slices.Contains(device.Tags, tag) {
+			return false
+		}
+	}
+
+	// Check metadata filter
+	for key, value := range filter.Metadata {
+		if v, exists := device.Metadata[key]; !exists || v != value {
+			return false
+		}
+	}
+
 	return true
 }
+
+// candidates narrows down the devices which can match the filter using the secondary indexes.
+// The result still has to be checked with matchesFilter.
+func (r *deviceRepository) candidates(filter domain.DeviceFilter) map[uuid.UUID]*domain.Device {
+	var sets []map[uuid.UUID]struct{}
+	for _, tag := range filter.Tags {
+		sets = append(sets, r.tags[tag])
+	}
+	for key, value := range filter.Metadata {
+		sets = append(sets, r.metadata[metadataIndexKey(key, value)])
+	}
+	if len(sets) == 0 {
+		return r.data
+	}
+
+	// iterate the smallest set, every candidate has to be in all of them anyway
+	smallest := sets[0]
+	for _, set := range sets[1:] {
+		if len(set) < len(smallest) {
+			smallest = set
+		}
+	}
+
+	candidates := make(map[uuid.UUID]*domain.Device, len(smallest))
+	for id := range smallest {
+		candidates[id] = r.data[id]
+	}
+	return candidates
+}
+
+func (r *deviceRepository) index(device *domain.Device) {
+	for _, tag := range device.Tags {
+		addToIndex(r.tags, tag, device.Id)
+	}
+	for key, value := range device.Metadata {
+		addToIndex(r.metadata, metadataIndexKey(key, value), device.Id)
+	}
+}
+
+func (r *deviceRepository) unindex(device *domain.Device) {
+	for _, tag := range device.Tags {
+		removeFromIndex(r.tags, tag, device.Id)
+	}
+	for key, value := range device.Metadata {
+		removeFromIndex(r.metadata, metadataIndexKey(key, value), device.Id)
+	}
+}
+
+// organizationScope returns the organization all device operations of the context are limited to
+func organizationScope(ctx context.Context) (uuid.UUID, error) {
+	organizationId, ok := domain.OrganizationFromContext(ctx)
+	if !ok {
+		return uuid.Nil, ErrMissingOrganization
+	}
+	return organizationId, nil
+}
+
+func metadataIndexKey(key, value string) string {
+	return key + "\x00" + value
+}
+
+func addToIndex(index map[string]map[uuid.UUID]struct{}, key string, id uuid.UUID) {
+	ids, exists := index[key]
+	if !exists {
+		ids = make(map[uuid.UUID]struct{})
+		index[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func removeFromIndex(index map[string]map[uuid.UUID]struct{}, key string, id uuid.UUID) {
+	ids := index[key]
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(index, key)
+	}
+}
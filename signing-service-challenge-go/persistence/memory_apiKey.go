@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+type apiKeyRepository struct {
+	data   map[uuid.UUID]*domain.APIKey
+	byHash map[string]uuid.UUID
+	mu     sync.RWMutex
+}
+
+func newAPIKeyRepository() *apiKeyRepository {
+	return &apiKeyRepository{
+		data:   make(map[uuid.UUID]*domain.APIKey),
+		byHash: make(map[string]uuid.UUID),
+	}
+}
+
+func (r *apiKeyRepository) Create(_ context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key == nil {
+		return ErrInvalidInput
+	}
+
+	if _, exists := r.data[key.Id]; exists {
+		return ErrAlreadyExists
+	}
+	if _, exists := r.byHash[key.Hash]; exists {
+		return ErrAlreadyExists
+	}
+
+	r.data[key.Id] = key.Copy()
+	r.byHash[key.Hash] = key.Id
+
+	return nil
+}
+
+func (r *apiKeyRepository) GetByID(_ context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.data[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return key.Copy(), nil
+}
+
+func (r *apiKeyRepository) GetByHash(_ context.Context, hash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.byHash[hash]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return r.data[id].Copy(), nil
+}
+
+func (r *apiKeyRepository) List(_ context.Context, organizationId uuid.UUID) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []*domain.APIKey
+	for _, key := range r.data {
+		if key.OrganizationId == organizationId {
+			keys = append(keys, key.Copy())
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(_ context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.data[key.Id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	// the secret can't be changed, a new key has to be created instead
+	key.Hash = existing.Hash
+	r.data[key.Id] = key.Copy()
+
+	return nil
+}
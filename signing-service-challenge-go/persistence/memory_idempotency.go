@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+type idempotencyKey struct {
+	deviceId uuid.UUID
+	key      string
+}
+
+type idempotencyRepository struct {
+	data map[idempotencyKey]domain.IdempotencyRecord
+	mu   sync.RWMutex
+}
+
+func newIdempotencyRepository() *idempotencyRepository {
+	return &idempotencyRepository{
+		data: make(map[idempotencyKey]domain.IdempotencyRecord),
+	}
+}
+
+func (r *idempotencyRepository) Get(_ context.Context, deviceId uuid.UUID, key string) (*domain.IdempotencyRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, exists := r.data[idempotencyKey{deviceId: deviceId, key: key}]
+	if !exists || record.Expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Save(_ context.Context, record *domain.IdempotencyRecord) error {
+	if record == nil {
+		return ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.data[idempotencyKey{deviceId: record.DeviceId, key: record.Key}] = *record
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(_ context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, record := range r.data {
+		if record.Expired(now) {
+			delete(r.data, key)
+		}
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteByDevice(_ context.Context, deviceId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.data {
+		if key.deviceId == deviceId {
+			delete(r.data, key)
+		}
+	}
+
+	return nil
+}
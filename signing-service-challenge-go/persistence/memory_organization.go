@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+type organizationRepository struct {
+	data map[uuid.UUID]*domain.Organization
+	mu   sync.RWMutex
+}
+
+func newOrganizationRepository() *organizationRepository {
+	// in a database the default organization would be created by a migration
+	now := time.Now()
+	return &organizationRepository{
+		data: map[uuid.UUID]*domain.Organization{
+			domain.DefaultOrganizationId: {
+				Id:        domain.DefaultOrganizationId,
+				Name:      "default",
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		},
+	}
+}
+
+func (r *organizationRepository) Create(_ context.Context, organization *domain.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if organization == nil {
+		return ErrInvalidInput
+	}
+
+	if _, exists := r.data[organization.Id]; exists {
+		return ErrAlreadyExists
+	}
+
+	now := time.Now()
+	if organization.CreatedAt.IsZero() {
+		organization.CreatedAt = now
+	}
+	organization.UpdatedAt = now
+
+	r.data[organization.Id] = organization.Copy()
+
+	return nil
+}
+
+func (r *organizationRepository) GetByID(_ context.Context, id uuid.UUID) (*domain.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	organization, exists := r.data[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return organization.Copy(), nil
+}
+
+func (r *organizationRepository) List(_ context.Context) ([]*domain.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	organizations := make([]*domain.Organization, 0, len(r.data))
+	for _, organization := range r.data {
+		organizations = append(organizations, organization.Copy())
+	}
+
+	sort.Slice(organizations, func(i, j int) bool {
+		return organizations[i].CreatedAt.Before(organizations[j].CreatedAt)
+	})
+
+	return organizations, nil
+}
+
+func (r *organizationRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[id]; !exists {
+		return ErrNotFound
+	}
+
+	delete(r.data, id)
+
+	return nil
+}
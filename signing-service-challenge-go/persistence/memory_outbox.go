@@ -0,0 +1,107 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+// outboxRecord keeps the position of an entry, so entries are returned in the order they were appended
+type outboxRecord struct {
+	sequence uint64
+	entry    *domain.OutboxEntry
+}
+
+type outboxRepository struct {
+	data     map[uuid.UUID]outboxRecord
+	sequence uint64
+	mu       sync.RWMutex
+}
+
+func newOutboxRepository() *outboxRepository {
+	return &outboxRepository{
+		data: make(map[uuid.UUID]outboxRecord),
+	}
+}
+
+func (r *outboxRepository) Append(_ context.Context, event *domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event == nil {
+		return ErrInvalidInput
+	}
+
+	if _, exists := r.data[event.Id]; exists {
+		return ErrAlreadyExists
+	}
+
+	r.sequence++
+	r.data[event.Id] = outboxRecord{
+		sequence: r.sequence,
+		entry: &domain.OutboxEntry{
+			Event:         *event.Copy(),
+			NextAttemptAt: event.CreatedAt,
+		},
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Pending(_ context.Context, now time.Time, limit int) ([]*domain.OutboxEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []outboxRecord
+	for _, record := range r.data {
+		if !record.entry.NextAttemptAt.After(now) {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].sequence < records[j].sequence
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	entries := make([]*domain.OutboxEntry, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, record.entry.Copy())
+	}
+
+	return entries, nil
+}
+
+func (r *outboxRepository) Update(_ context.Context, entry *domain.OutboxEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, exists := r.data[entry.Event.Id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	record.entry = entry.Copy()
+	r.data[entry.Event.Id] = record
+
+	return nil
+}
+
+func (r *outboxRepository) Delete(_ context.Context, eventId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[eventId]; !exists {
+		return ErrNotFound
+	}
+
+	delete(r.data, eventId)
+
+	return nil
+}
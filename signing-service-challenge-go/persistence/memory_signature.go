@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+type signatureRepository struct {
+	// signatures of a device are kept ordered by counter
+	data map[uuid.UUID][]domain.Signature
+	mu   sync.RWMutex
+}
+
+func newSignatureRepository() *signatureRepository {
+	return &signatureRepository{
+		data: make(map[uuid.UUID][]domain.Signature),
+	}
+}
+
+func (r *signatureRepository) Create(_ context.Context, signature *domain.Signature) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if signature == nil {
+		return ErrInvalidInput
+	}
+
+	signatures := r.data[signature.DeviceId]
+	if len(signatures) > 0 && signatures[len(signatures)-1].Counter >= signature.Counter {
+		return ErrAlreadyExists
+	}
+
+	r.data[signature.DeviceId] = append(signatures, *signature)
+
+	return nil
+}
+
+func (r *signatureRepository) List(_ context.Context, deviceId uuid.UUID, afterCounter int, limit int) ([]*domain.Signature, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	signatures := r.data[deviceId]
+	start := sort.Search(len(signatures), func(i int) bool {
+		return signatures[i].Counter > afterCounter
+	})
+	signatures = signatures[start:]
+	if limit > 0 && len(signatures) > limit {
+		signatures = signatures[:limit]
+	}
+
+	out := make([]*domain.Signature, 0, len(signatures))
+	for _, signature := range signatures {
+		out = append(out, &signature)
+	}
+
+	return out, nil
+}
+
+func (r *signatureRepository) DeleteByDevice(_ context.Context, deviceId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.data, deviceId)
+
+	return nil
+}
+
+// count returns the number of signatures of all devices, the caller has to hold the read lock
+func (r *signatureRepository) count() int {
+	total := 0
+	for _, signatures := range r.data {
+		total += len(signatures)
+	}
+	return total
+}
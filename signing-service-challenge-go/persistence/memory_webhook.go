@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/google/uuid"
+)
+
+type webhookRepository struct {
+	data map[uuid.UUID]*domain.Webhook
+	mu   sync.RWMutex
+}
+
+func newWebhookRepository() *webhookRepository {
+	return &webhookRepository{
+		data: make(map[uuid.UUID]*domain.Webhook),
+	}
+}
+
+func (r *webhookRepository) Create(_ context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if webhook == nil {
+		return ErrInvalidInput
+	}
+
+	if _, exists := r.data[webhook.Id]; exists {
+		return ErrAlreadyExists
+	}
+
+	r.data[webhook.Id] = webhook.Copy()
+
+	return nil
+}
+
+func (r *webhookRepository) GetByID(_ context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, exists := r.data[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return webhook.Copy(), nil
+}
+
+func (r *webhookRepository) List(_ context.Context, organizationId uuid.UUID) ([]*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var webhooks []*domain.Webhook
+	for _, webhook := range r.data {
+		if webhook.OrganizationId == organizationId {
+			webhooks = append(webhooks, webhook.Copy())
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[id]; !exists {
+		return ErrNotFound
+	}
+
+	delete(r.data, id)
+
+	return nil
+}
+
+type webhookDeliveryRepository struct {
+	data map[uuid.UUID]*domain.WebhookDelivery
+	mu   sync.RWMutex
+}
+
+func newWebhookDeliveryRepository() *webhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		data: make(map[uuid.UUID]*domain.WebhookDelivery),
+	}
+}
+
+func (r *webhookDeliveryRepository) Create(_ context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery == nil {
+		return ErrInvalidInput
+	}
+
+	if _, exists := r.data[delivery.Id]; exists {
+		return ErrAlreadyExists
+	}
+
+	now := time.Now()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	delivery.UpdatedAt = now
+
+	r.data[delivery.Id] = delivery.Copy()
+
+	return nil
+}
+
+func (r *webhookDeliveryRepository) List(_ context.Context, webhookId uuid.UUID, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range r.data {
+		if delivery.WebhookId == webhookId {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	return copyDeliveries(deliveries, limit), nil
+}
+
+func (r *webhookDeliveryRepository) Due(_ context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range r.data {
+		if delivery.Status == domain.DeliveryStatusPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+
+	return copyDeliveries(deliveries, limit), nil
+}
+
+func (r *webhookDeliveryRepository) Update(_ context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.data[delivery.Id]; !exists {
+		return ErrNotFound
+	}
+
+	delivery.UpdatedAt = time.Now()
+	r.data[delivery.Id] = delivery.Copy()
+
+	return nil
+}
+
+func (r *webhookDeliveryRepository) DeleteCompleted(_ context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, delivery := range r.data {
+		if delivery.Status != domain.DeliveryStatusPending && delivery.UpdatedAt.Before(before) {
+			delete(r.data, id)
+		}
+	}
+
+	return nil
+}
+
+// copyDeliveries copies at most limit deliveries, all if limit is zero
+func copyDeliveries(deliveries []*domain.WebhookDelivery, limit int) []*domain.WebhookDelivery {
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	copies := make([]*domain.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		copies = append(copies, delivery.Copy())
+	}
+	return copies
+}
@@ -11,14 +11,40 @@ var (
 	ErrNotFound      = errors.New("record not found")
 	ErrAlreadyExists = errors.New("record already exists")
 	ErrInvalidInput  = errors.New("invalid input")
+	// ErrMissingOrganization is returned when a tenant scoped operation is called without an organization in the context
+	ErrMissingOrganization = errors.New("organization scope missing")
+	// ErrStaleToken is returned when a write is fenced with a token lower than one seen before,
+	// the lock of the writer expired and another writer may have changed the record meanwhile
+	ErrStaleToken = errors.New("stale fencing token")
 )
 
 // Storage handles transactions and provides repository access
 type Storage interface {
+	// Devices is scoped to the organization carried in the context, see [domain.WithOrganization]
 	Devices() domain.DeviceRepository
+	// Signatures is not scoped, callers have to make sure the device is visible to the organization
+	Signatures() domain.SignatureRepository
+	Organizations() domain.OrganizationRepository
+	APIKeys() domain.APIKeyRepository
+	Idempotency() domain.IdempotencyRepository
+	Webhooks() domain.WebhookRepository
+	// WebhookDeliveries holds the events waiting for delivery to each webhook,
+	// delivered and failed entries are kept as delivery log for the retention of the dispatcher
+	WebhookDeliveries() domain.WebhookDeliveryRepository
+	// Outbox holds the events not yet delivered to all sinks, it has to be written in the transaction of the change
+	Outbox() domain.OutboxRepository
 
 	WithTransaction(ctx context.Context, fn func(ctx context.Context, s Storage) error) error
 
 	Health(ctx context.Context) error
+	// Stats counts the stored records, e.g. to diagnose the memory usage of the service
+	Stats(ctx context.Context) (Stats, error)
 	Close() error
 }
+
+// Stats describes the content of a storage
+type Stats struct {
+	Backend string
+	// Records counts the records of each repository, signatures are counted across all devices
+	Records map[string]int
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of requests after which idle buckets are removed
+const sweepInterval = 1024
+
+type memoryLimiter[K comparable] struct {
+	mu       sync.Mutex
+	rate     Rate
+	buckets  map[K]*bucket
+	requests int
+	now      func() time.Time
+}
+
+func NewMemoryLimiter[K comparable](rate Rate) Limiter[K] {
+	return &memoryLimiter[K]{
+		rate:    rate,
+		buckets: make(map[K]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *memoryLimiter[K]) Allow(key K) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	burst := float64(m.rate.Burst)
+
+	m.requests++
+	if m.requests%sweepInterval == 0 {
+		m.sweep(now)
+	}
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{tokens: burst, last: now}
+		m.buckets[key] = b
+	}
+
+	// refill the tokens accumulated since the last request
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*m.rate.PerSecond)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return m.result(b.tokens, allowed)
+}
+
+func (m *memoryLimiter[K]) Peek(key K) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	burst := float64(m.rate.Burst)
+	tokens := burst
+	if b, exists := m.buckets[key]; exists {
+		tokens = math.Min(burst, b.tokens+m.now().Sub(b.last).Seconds()*m.rate.PerSecond)
+	}
+	return m.result(tokens, tokens >= 1)
+}
+
+// result describes a bucket holding the tokens
+func (m *memoryLimiter[K]) result(tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     m.rate.Burst,
+		Remaining: int(tokens),
+		Reset:     m.duration(float64(m.rate.Burst) - tokens),
+	}
+	if !allowed {
+		result.RetryAfter = m.duration(1 - tokens)
+	}
+	return result
+}
+
+// sweep removes buckets which are completely refilled, they are indistinguishable from new ones
+func (m *memoryLimiter[K]) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*m.rate.PerSecond >= float64(m.rate.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// duration returns the time needed to refill the number of tokens
+func (m *memoryLimiter[K]) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / m.rate.PerSecond * float64(time.Second)))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
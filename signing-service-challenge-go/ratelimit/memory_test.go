@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Now()
+	limiter := NewMemoryLimiter[string](Rate{PerSecond: 2, Burst: 3}).(*memoryLimiter[string])
+	limiter.now = func() time.Time { return now }
+
+	// the burst is available immediately
+	for remaining := 2; remaining >= 0; remaining-- {
+		result := limiter.Allow("foo")
+		assert.True(result.Allowed)
+		assert.Equal(3, result.Limit)
+		assert.Equal(remaining, result.Remaining)
+	}
+
+	result := limiter.Allow("foo")
+	assert.False(result.Allowed)
+	assert.Equal(500*time.Millisecond, result.RetryAfter)
+	assert.Equal(1500*time.Millisecond, result.Reset)
+
+	// other keys have their own bucket
+	assert.True(limiter.Allow("bar").Allowed)
+
+	// tokens are refilled with the rate
+	now = now.Add(500 * time.Millisecond)
+	assert.True(limiter.Allow("foo").Allowed)
+	assert.False(limiter.Allow("foo").Allowed)
+
+	now = now.Add(time.Hour)
+	result = limiter.Allow("foo")
+	assert.True(result.Allowed)
+	assert.Equal(2, result.Remaining)
+
+	// peeking doesn't take tokens
+	for range 3 {
+		result = limiter.Peek("foo")
+		assert.True(result.Allowed)
+		assert.Equal(2, result.Remaining)
+	}
+	result = limiter.Peek("baz")
+	assert.Equal(3, result.Remaining)
+	assert.Zero(result.Reset)
+	assert.Len(limiter.buckets, 2)
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Now()
+	limiter := NewMemoryLimiter[int](Rate{PerSecond: 1, Burst: 1}).(*memoryLimiter[int])
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < sweepInterval-1; i++ {
+		limiter.Allow(i)
+	}
+	assert.Len(limiter.buckets, sweepInterval-1)
+
+	// all buckets are refilled after a second, so they are dropped by the next sweep
+	now = now.Add(time.Second)
+	limiter.Allow(-1)
+	assert.Len(limiter.buckets, 1)
+}
@@ -0,0 +1,70 @@
+// Package ratelimit provides token bucket rate limiting to protect the signing service from
+// clients exhausting shared resources like the CPU budget for RSA signatures.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rate configures a token bucket, it refills with PerSecond tokens per second up to Burst tokens
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Enabled reports whether the rate limits anything, a zero rate is unlimited
+func (r Rate) Enabled() bool {
+	return r.PerSecond > 0 && r.Burst > 0
+}
+
+// String formats the rate as "<per second>:<burst>", e.g. 10:20
+func (r Rate) String() string {
+	return strconv.FormatFloat(r.PerSecond, 'f', -1, 64) + ":" + strconv.Itoa(r.Burst)
+}
+
+// Set parses a rate formatted by String, so it can be used with flag.Var
+func (r *Rate) Set(value string) error {
+	perSecond, burst, found := strings.Cut(value, ":")
+	if !found {
+		return errors.New("rate must be formatted as <per second>:<burst>")
+	}
+	var err error
+	if r.PerSecond, err = strconv.ParseFloat(perSecond, 64); err != nil || r.PerSecond < 0 {
+		return fmt.Errorf("invalid rate per second %q", perSecond)
+	}
+	if r.Burst, err = strconv.Atoi(burst); err != nil || r.Burst < 0 {
+		return fmt.Errorf("invalid burst %q", burst)
+	}
+	return nil
+}
+
+// Result describes the state of a bucket after a request was taken from it
+type Result struct {
+	Allowed    bool          // Whether the request may proceed
+	Limit      int           // Size of the bucket
+	Remaining  int           // Requests which can be made immediately
+	RetryAfter time.Duration // Time until the next request is allowed, zero if allowed
+	Reset      time.Duration // Time until the bucket is completely refilled
+}
+
+// Limiter interface for rate limiting could be done with redis to share limits between replicas
+// Generic interface that can limit on any comparable type (e.g., client identity, device UUID)
+type Limiter[K comparable] interface {
+	Allow(K) Result
+	// Peek describes the bucket of the key without taking from it
+	Peek(K) Result
+}
+
+// Limiters are the buckets requests are taken from, nil limiters are unlimited.
+// They are shared by the HTTP and the gRPC API, so clients can't escape them by switching the transport.
+type Limiters struct {
+	Global    Limiter[struct{}]  // Shared by all requests
+	PerClient Limiter[string]    // Per API key, client certificate or remote address
+	PerDevice Limiter[uuid.UUID] // Per device, only applied to signing requests
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentLocker records a span for every lock acquisition, covering the time spent waiting
+func InstrumentLocker[I comparable](locker lock.Locker[I]) lock.Locker[I] {
+	return &instrumentedLocker[I]{locker: locker}
+}
+
+type instrumentedLocker[I comparable] struct {
+	locker lock.Locker[I]
+}
+
+func (l *instrumentedLocker[I]) Acquire(ctx context.Context, id I) (lock.Lock, error) {
+	ctx, span := tracer().Start(ctx, "lock.Acquire", trace.WithAttributes(attribute.String("lock.id", fmt.Sprint(id))))
+	acquired, err := l.locker.Acquire(ctx, id)
+	end(span, err)
+	return acquired, err
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ObserveStorage records a span per storage operation, it is a [persistence.Observer].
+// Only operations within a trace are recorded, so the polling of background workers doesn't start a trace every time.
+// Missing and conflicting records are expected outcomes and don't mark the span as failed.
+func ObserveStorage(ctx context.Context, repository string, operation string) (context.Context, func(error)) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, func(error) {}
+	}
+	ctx, span := tracer().Start(ctx, "storage "+repository+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("storage.repository", repository),
+			attribute.String("storage.operation", operation),
+		),
+	)
+	return ctx, func(err error) {
+		if errors.Is(err, persistence.ErrNotFound) || errors.Is(err, persistence.ErrAlreadyExists) {
+			span.SetAttributes(attribute.String("storage.result", err.Error()))
+			err = nil
+		}
+		end(span, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package tracing records OpenTelemetry spans of the signing service and exports them.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies the service in exported spans
+const ServiceName = "signing-service"
+
+const instrumentationName = "github.com/fiskaly/coding-challenges/signing-service-challenge/tracing"
+
+// Exporter selects where spans are sent, it can be used as flag
+type Exporter string
+
+const (
+	// ExporterNone doesn't record any spans
+	ExporterNone Exporter = "none"
+	// ExporterStdout writes spans as json to stdout, meant for local testing
+	ExporterStdout Exporter = "stdout"
+	// ExporterOTLP sends spans to an OTLP/HTTP collector, configured with the OTEL_EXPORTER_OTLP_* environment variables
+	ExporterOTLP Exporter = "otlp"
+)
+
+func (e *Exporter) String() string {
+	return string(*e)
+}
+
+func (e *Exporter) Set(value string) error {
+	switch exporter := Exporter(value); exporter {
+	case ExporterNone, ExporterStdout, ExporterOTLP:
+		*e = exporter
+		return nil
+	}
+	return fmt.Errorf("unknown trace exporter %q, expected %s, %s or %s", value, ExporterNone, ExporterStdout, ExporterOTLP)
+}
+
+// Setup installs the global tracer provider exporting to the exporter and the W3C trace context propagator.
+// The returned function flushes pending spans and has to be called before the process exits.
+func Setup(ctx context.Context, exporter Exporter) (func(context.Context) error, error) {
+	// incoming trace context is honored even when no spans are exported, so it reaches outgoing requests
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// tracer is looked up on every use, so spans follow the provider installed by [Setup]
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// end records the error of the operation on the span and ends it
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
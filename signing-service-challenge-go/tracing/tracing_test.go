@@ -0,0 +1,164 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/lock"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// recordSpans installs a tracer provider recording all spans until the test ends
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	assert := require.New(t)
+	recorder := recordSpans(t)
+
+	mux := chi.NewMux()
+	mux.Use(Middleware)
+	mux.Get("/device/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: foo\n\n"))
+		assert.NoError(http.NewResponseController(w).Flush())
+	})
+
+	// Test case 1: The trace context of the request is continued and the span is named after the route
+	request := httptest.NewRequest(http.MethodGet, "/device/1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	mux.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	assert.Len(spans, 1)
+	assert.Equal("GET /device/{id}", spans[0].Name())
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal("00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.True(spans[0].Parent().IsRemote())
+	assert.Equal(codes.Error, spans[0].Status().Code)
+	assert.Contains(spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+
+	// Test case 2: Streaming responses are still flushed
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(response.Flushed)
+	spans = recorder.Ended()
+	assert.Len(spans, 2)
+	assert.Equal(codes.Unset, spans[1].Status().Code)
+	assert.False(spans[1].Parent().IsValid())
+}
+
+func TestObserveStorage(t *testing.T) {
+	assert := require.New(t)
+	recorder := recordSpans(t)
+
+	// Test case 1: Operations outside of a trace aren't recorded
+	_, done := ObserveStorage(context.Background(), "outbox", "pending")
+	done(nil)
+	assert.Empty(recorder.Ended())
+
+	// Test case 2: Missing records don't mark the span as failed
+	ctx, span := tracer().Start(context.Background(), "request")
+	_, done = ObserveStorage(ctx, "devices", "get")
+	done(persistence.ErrNotFound)
+	_, done = ObserveStorage(ctx, "devices", "update")
+	done(errors.New("connection lost"))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(spans, 3)
+	assert.Equal("storage devices.get", spans[0].Name())
+	assert.Equal(codes.Unset, spans[0].Status().Code)
+	assert.Equal("storage devices.update", spans[1].Name())
+	assert.Equal(codes.Error, spans[1].Status().Code)
+}
+
+func TestInstrumentLocker(t *testing.T) {
+	assert := require.New(t)
+	recorder := recordSpans(t)
+	locker := InstrumentLocker(lock.NewMemoryLocker[int]())
+
+	held, err := locker.Acquire(context.Background(), 1)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = locker.Acquire(ctx, 1)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	held.Unlock()
+
+	spans := recorder.Ended()
+	assert.Len(spans, 2)
+	assert.Equal("lock.Acquire", spans[0].Name())
+	assert.Equal(codes.Unset, spans[0].Status().Code)
+	assert.Equal(codes.Error, spans[1].Status().Code)
+}
+
+// fakeStream is a server stream only providing its context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	assert := require.New(t)
+	recorder := recordSpans(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	info := &grpc.StreamServerInfo{FullMethod: "/signing.v0.SigningService/ListSignatures"}
+	err := StreamServerInterceptor(nil, fakeStream{ctx: ctx}, info, func(_ any, stream grpc.ServerStream) error {
+		// the handler sees the span of the stream
+		assert.True(trace.SpanFromContext(stream.Context()).IsRecording())
+		return status.Error(grpccodes.NotFound, "device not found")
+	})
+	assert.Equal(grpccodes.NotFound, status.Code(err))
+
+	spans := recorder.Ended()
+	assert.Len(spans, 1)
+	assert.Equal(info.FullMethod, spans[0].Name())
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(codes.Error, spans[0].Status().Code)
+	assert.Contains(spans[0].Attributes(), attribute.String("rpc.grpc.status_code", "NotFound"))
+}
+
+func TestExporter(t *testing.T) {
+	assert := require.New(t)
+
+	var exporter Exporter
+	assert.NoError(exporter.Set("stdout"))
+	assert.Equal(ExporterStdout, exporter)
+	assert.Error(exporter.Set("jaeger"))
+}
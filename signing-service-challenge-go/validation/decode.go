@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmpty        = errors.New("request body must not be empty")
+	ErrTrailingData = errors.New("request body must contain a single json value")
+)
+
+// Decode decodes the single JSON value of r into v. Unknown fields and values of the wrong type
+// are returned as [Errors], trailing data is rejected with [ErrTrailingData].
+func Decode(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+
+	err := decoder.Decode(&json.RawMessage{})
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil
+	case errors.As(err, &tooLarge):
+		return err
+	}
+	return ErrTrailingData
+}
+
+// decodeError turns errors of the decoder which can be attributed to a field into [Errors]
+func decodeError(err error) error {
+	if errors.Is(err, io.EOF) {
+		return ErrEmpty
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		tokens := make([]any, 0, strings.Count(typeErr.Field, ".")+1)
+		for _, token := range strings.Split(typeErr.Field, ".") {
+			tokens = append(tokens, token)
+		}
+		return Errors{{Pointer: Pointer(tokens...), Message: "must be " + jsonType(typeErr.Type)}}
+	}
+
+	// the decoder has no typed error for unknown fields
+	if quoted, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		if field, err := strconv.Unquote(quoted); err == nil {
+			return Errors{{Pointer: Pointer(field), Message: "unknown field"}}
+		}
+	}
+	return err
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLabelLength is the maximum number of characters of labels and names
+const MaxLabelLength = 128
+
+// Label checks that a label or name has 1 to [MaxLabelLength] printable characters
+func Label(value string) error {
+	return Text(value, 1, MaxLabelLength)
+}
+
+// Text checks that value is valid utf-8 of min to max printable characters.
+// Control characters like line breaks are rejected, they break logs and tables the value is shown in.
+func Text(value string, min int, max int) error {
+	if !utf8.ValidString(value) {
+		return errors.New("must be valid utf-8")
+	}
+	if length := utf8.RuneCountInString(value); length < min || length > max {
+		if min == 0 {
+			return fmt.Errorf("must be at most %d characters long", max)
+		}
+		return fmt.Errorf("must be between %d and %d characters long", min, max)
+	}
+	if strings.IndexFunc(value, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return errors.New("must only contain printable characters")
+	}
+	return nil
+}
+
+// MaxSize checks that value has at most max bytes
+func MaxSize(value string, max int) error {
+	if len(value) > max {
+		return fmt.Errorf("must be at most %d bytes", max)
+	}
+	return nil
+}
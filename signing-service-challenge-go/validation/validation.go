@@ -0,0 +1,114 @@
+// Package validation collects the errors of request fields, so clients learn about all rejected fields at once
+// and where they are located in the request body.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a rejected field of a request
+type FieldError struct {
+	// Pointer locates the field in the request body, see RFC 6901
+	Pointer string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+	return e.Pointer + ": " + e.Message
+}
+
+// Errors are all rejected fields of a request
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validator collects field errors, the zero value is ready to use
+type Validator struct {
+	errors Errors
+}
+
+// Check records err for the field at pointer, nil errors are ignored.
+// Errors joined with [errors.Join] are recorded one by one, errors which are already
+// field errors keep their pointer relative to pointer.
+func (v *Validator) Check(pointer string, err error) {
+	if err == nil {
+		return
+	}
+	var fieldErrs Errors
+	var fieldErr FieldError
+	switch {
+	case errors.As(err, &fieldErrs):
+		for _, fieldErr := range fieldErrs {
+			v.errors = append(v.errors, FieldError{Pointer: pointer + fieldErr.Pointer, Message: fieldErr.Message})
+		}
+	case errors.As(err, &fieldErr):
+		v.errors = append(v.errors, FieldError{Pointer: pointer + fieldErr.Pointer, Message: fieldErr.Message})
+	default:
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				v.Check(pointer, err)
+			}
+			return
+		}
+		v.errors = append(v.errors, FieldError{Pointer: pointer, Message: err.Error()})
+	}
+}
+
+// Assert records the message for the field at pointer, unless ok is true
+func (v *Validator) Assert(ok bool, pointer string, message string) {
+	if !ok {
+		v.errors = append(v.errors, FieldError{Pointer: pointer, Message: message})
+	}
+}
+
+// Err returns the collected field errors ordered by their pointer, or nil if all fields are valid
+func (v *Validator) Err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	errs := slices.Clone(v.errors)
+	slices.SortStableFunc(errs, func(a, b FieldError) int {
+		return strings.Compare(a.Pointer, b.Pointer)
+	})
+	return errs
+}
+
+// Field locates err at the field at pointer, it is nil for nil errors. See [Validator.Check].
+func Field(pointer string, err error) error {
+	var v Validator
+	v.Check(pointer, err)
+	return v.Err()
+}
+
+// Pointer builds a JSON pointer from reference tokens, e.g. Pointer("metadata", "region") is /metadata/region.
+// Tokens are escaped, integers are array indices.
+func Pointer(tokens ...any) string {
+	var pointer strings.Builder
+	for _, token := range tokens {
+		pointer.WriteByte('/')
+		switch token := token.(type) {
+		case string:
+			pointer.WriteString(pointerEscaper.Replace(token))
+		case int:
+			pointer.WriteString(strconv.Itoa(token))
+		default:
+			pointer.WriteString(pointerEscaper.Replace(fmt.Sprint(token)))
+		}
+	}
+	return pointer.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
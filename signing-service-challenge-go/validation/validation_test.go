@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator(t *testing.T) {
+	assert := require.New(t)
+
+	var v Validator
+	assert.NoError(v.Err())
+
+	v.Check("/name", nil)
+	v.Assert(true, "/name", "unused")
+	assert.NoError(v.Err())
+
+	v.Check("/tags", Errors{{Pointer: "/1", Message: "invalid tag"}, {Pointer: "", Message: "too many tags"}})
+	v.Check("/id", errors.Join(errors.New("too short"), errors.New("invalid character")))
+	v.Check("/certificates", FieldError{Pointer: "/0", Message: "invalid fingerprint"})
+	v.Assert(false, "/count", "must be positive")
+
+	var errs Errors
+	assert.ErrorAs(v.Err(), &errs)
+	assert.Equal(Errors{
+		{Pointer: "/certificates/0", Message: "invalid fingerprint"},
+		{Pointer: "/count", Message: "must be positive"},
+		{Pointer: "/id", Message: "too short"},
+		{Pointer: "/id", Message: "invalid character"},
+		{Pointer: "/tags", Message: "too many tags"},
+		{Pointer: "/tags/1", Message: "invalid tag"},
+	}, errs)
+	assert.Equal("/certificates/0: invalid fingerprint\n/count: must be positive", errs[:2].Error())
+
+	assert.NoError(Field("/name", nil))
+	assert.Equal(Errors{{Pointer: "/name", Message: "invalid"}}, Field("/name", errors.New("invalid")))
+}
+
+func TestPointer(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("", Pointer())
+	assert.Equal("/metadata/region", Pointer("metadata", "region"))
+	assert.Equal("/tags/2", Pointer("tags", 2))
+	assert.Equal("/a~1b/c~0d", Pointer("a/b", "c~d"))
+}
+
+func TestDecode(t *testing.T) {
+	assert := require.New(t)
+
+	type dto struct {
+		Name     string            `json:"name"`
+		Count    int               `json:"count"`
+		Metadata map[string]string `json:"metadata"`
+	}
+
+	// Test case 1: A single valid value is decoded
+	var out dto
+	assert.NoError(Decode(strings.NewReader(`{"name": "foo", "count": 1}`+"\n"), &out))
+	assert.Equal(dto{Name: "foo", Count: 1}, out)
+
+	// Test case 2: Errors of fields point to the field
+	for body, expected := range map[string]Errors{
+		`{"name": 1}`:                    {{Pointer: "/name", Message: "must be a string"}},
+		`{"count": "1"}`:                 {{Pointer: "/count", Message: "must be an integer"}},
+		`{"metadata": {"region": true}}`: {{Pointer: "/metadata/region", Message: "must be a string"}},
+		`{"name": "foo", "nmae": "bar"}`: {{Pointer: "/nmae", Message: "unknown field"}},
+	} {
+		var errs Errors
+		assert.ErrorAs(Decode(strings.NewReader(body), &out), &errs, body)
+		assert.Equal(expected, errs, body)
+	}
+
+	// Test case 3: Empty bodies, trailing data and syntax errors are rejected
+	assert.ErrorIs(Decode(strings.NewReader(""), &out), ErrEmpty)
+	assert.ErrorIs(Decode(strings.NewReader(`{"name": "foo"} {}`), &out), ErrTrailingData)
+	assert.ErrorIs(Decode(strings.NewReader(`{"name": "foo"} x`), &out), ErrTrailingData)
+	assert.Error(Decode(strings.NewReader(`{"name": `), &out))
+
+	// Test case 4: Exceeding the size limit is reported as such
+	var tooLarge *http.MaxBytesError
+	body := http.MaxBytesReader(nil, io.NopCloser(strings.NewReader(`{"name": "`+strings.Repeat("a", 100)+`"}`)), 10)
+	assert.ErrorAs(Decode(body, &out), &tooLarge)
+}
+
+func TestText(t *testing.T) {
+	assert := require.New(t)
+
+	assert.NoError(Label("Kasse 1 – Café"))
+	assert.EqualError(Label(""), "must be between 1 and 128 characters long")
+	assert.EqualError(Label(strings.Repeat("ä", MaxLabelLength+1)), "must be between 1 and 128 characters long")
+	assert.EqualError(Label("line\nbreak"), "must only contain printable characters")
+	assert.EqualError(Label("\xff"), "must be valid utf-8")
+	assert.EqualError(Text(strings.Repeat("a", 3), 0, 2), "must be at most 2 characters long")
+
+	assert.NoError(MaxSize("abc", 3))
+	assert.EqualError(MaxSize("abcd", 3), "must be at most 3 bytes")
+}
@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultInterval is the time between two polls of the outbox
+	DefaultInterval = time.Second
+	// DefaultMaxAttempts is the number of attempts after which a delivery is given up
+	DefaultMaxAttempts = 10
+	// DefaultTimeout is the time a receiver has to respond
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetention is how long delivered and failed deliveries are kept in the delivery log
+	DefaultRetention = 7 * 24 * time.Hour
+	// DefaultSweepInterval is how often deliveries beyond the retention are removed
+	DefaultSweepInterval = time.Minute
+
+	// batchSize is the maximum number of deliveries sent per poll
+	batchSize = 100
+)
+
+// Payload is the JSON body posted to webhooks
+type Payload struct {
+	Id             uuid.UUID        `json:"id"`
+	Type           domain.EventType `json:"type"`
+	OrganizationId uuid.UUID        `json:"organization_id"`
+	DeviceId       uuid.UUID        `json:"device_id"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Data           json.RawMessage  `json:"data"`
+}
+
+// Dispatcher delivers the pending entries of the webhook outbox and retries failed deliveries.
+type Dispatcher struct {
+	storage        persistence.Storage
+	client         *http.Client
+	privateTargets bool
+	interval       time.Duration
+	maxAttempts    int
+	backoff        func(attempt int) time.Duration
+	retention      time.Duration
+}
+
+type Option func(*Dispatcher)
+
+// WithHTTPClient sets the client used to post the events, it has to refuse private targets itself, see [PublicAddress].
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithPrivateTargets allows deliveries to private, loopback and link-local addresses, e.g. for development.
+func WithPrivateTargets() Option {
+	return func(d *Dispatcher) {
+		d.privateTargets = true
+	}
+}
+
+// WithInterval sets the time between two polls of the outbox.
+func WithInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+// WithMaxAttempts sets the number of attempts after which a delivery is given up.
+func WithMaxAttempts(attempts int) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = attempts
+	}
+}
+
+// WithBackoff sets the delay before the next attempt after the given number of failed attempts.
+func WithBackoff(backoff func(attempt int) time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.backoff = backoff
+	}
+}
+
+// ExponentialBackoff doubles the delay with every failed attempt, starting at 5 seconds up to an hour.
+func ExponentialBackoff(attempt int) time.Duration {
+	const (
+		initial = 5 * time.Second
+		maximum = time.Hour
+	)
+	delay := initial
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= maximum {
+			return maximum
+		}
+	}
+	return delay
+}
+
+// WithRetention sets how long delivered and failed deliveries are kept in the delivery log.
+func WithRetention(retention time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.retention = retention
+	}
+}
+
+func NewDispatcher(
+	storage persistence.Storage,
+	options ...Option,
+) *Dispatcher {
+	d := &Dispatcher{
+		storage:     storage,
+		interval:    DefaultInterval,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     ExponentialBackoff,
+		retention:   DefaultRetention,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	if d.client == nil {
+		d.client = newClient(d.privateTargets)
+	}
+	return d
+}
+
+// Run polls the outbox until the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil {
+			slog.Error("dispatching webhooks failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep removes delivered and failed deliveries beyond the retention every interval until the context is done.
+func (d *Dispatcher) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := d.storage.WebhookDeliveries().DeleteCompleted(ctx, time.Now().Add(-d.retention)); err != nil && ctx.Err() == nil {
+			slog.Error("deleting completed webhook deliveries failed", "error", err)
+		}
+	}
+}
+
+// Dispatch makes one attempt for every delivery which is due and returns the number of attempts made.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	deliveries, err := d.storage.WebhookDeliveries().Due(ctx, time.Now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if err := d.deliver(ctx, delivery); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver posts the event of the delivery to its webhook and records the attempt
+func (d *Dispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	now := time.Now()
+	attempt := domain.DeliveryAttempt{AttemptedAt: now}
+
+	webhook, err := d.storage.Webhooks().GetByID(ctx, delivery.WebhookId)
+	switch {
+	case errors.Is(err, persistence.ErrNotFound):
+		attempt.Error = "webhook was deleted"
+		delivery.Status = domain.DeliveryStatusFailed
+	case err != nil:
+		slog.Error("failed fetching webhook", "error", err)
+		return err
+	default:
+		attempt.StatusCode, err = d.post(ctx, webhook, &delivery.Event, now)
+		attempt.Duration = time.Since(now)
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	switch {
+	case delivery.Status == domain.DeliveryStatusFailed:
+	case attempt.Error == "":
+		delivery.Status = domain.DeliveryStatusDelivered
+	case len(delivery.Attempts) >= d.maxAttempts:
+		slog.Warn("giving up webhook delivery", "delivery", delivery.Id, "webhook", delivery.WebhookId, "error", attempt.Error)
+		delivery.Status = domain.DeliveryStatusFailed
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(len(delivery.Attempts)))
+	}
+
+	if err := d.storage.WebhookDeliveries().Update(ctx, delivery); err != nil {
+		slog.Error("failed updating webhook delivery", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// post sends the signed event to the webhook, any response outside of 2xx is an error
+func (d *Dispatcher) post(ctx context.Context, webhook *domain.Webhook, event *domain.Event, now time.Time) (int, error) {
+	body, err := json.Marshal(Payload{
+		Id:             event.Id,
+		Type:           event.Type,
+		OrganizationId: event.OrganizationId,
+		DeviceId:       event.DeviceId,
+		CreatedAt:      event.CreatedAt,
+		Data:           event.Data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IdHeader, event.Id.String())
+	request.Header.Set(EventHeader, string(event.Type))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, now, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("receiver responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,59 @@
+// Package webhook delivers device events to the HTTP endpoints subscribed to them.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	IdHeader        = "Webhook-Id"        // Id of the event, receivers use it to drop duplicate deliveries
+	EventHeader     = "Webhook-Event"     // Type of the event
+	TimestampHeader = "Webhook-Timestamp" // Unix time the request was signed at
+	SignatureHeader = "Webhook-Signature" // HMAC of the timestamp and body, see [Sign]
+)
+
+// signatureVersion is prefixed to signatures, so the scheme can be changed without breaking receivers
+const signatureVersion = "v1="
+
+// DefaultTolerance is the maximum age of a delivery accepted by [Verify]
+const DefaultTolerance = 5 * time.Minute
+
+// Sign computes the signature of a payload as hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+// The timestamp is part of the signature, so a captured request can't be replayed later.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a delivery received at now.
+// Receivers should call it before trusting the payload.
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook timestamp")
+	}
+	timestamp := time.Unix(unix, 0)
+	if now.Sub(timestamp).Abs() > tolerance {
+		return errors.New("webhook timestamp outside of tolerance")
+	}
+
+	expected := Sign(secret, timestamp, body)
+	// the header may carry several signatures while the secret is changed
+	for signature := range strings.FieldsSeq(header.Get(SignatureHeader)) {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return errors.New("invalid webhook signature")
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+	header := http.Header{}
+	header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(SignatureHeader, Sign("secret", now, body))
+
+	assert.NoError(Verify("secret", header, body, now.Add(time.Minute), DefaultTolerance))
+	assert.Error(Verify("other", header, body, now, DefaultTolerance))
+	assert.Error(Verify("secret", header, []byte(`{"id":"2"}`), now, DefaultTolerance))
+	assert.Error(Verify("secret", header, body, now.Add(time.Hour), DefaultTolerance))
+
+	// the timestamp is signed, it can't be moved forward to replay a request
+	header.Set(TimestampHeader, strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+	assert.Error(Verify("secret", header, body, now.Add(time.Hour), DefaultTolerance))
+
+	// while the secret is changed, either signature is accepted
+	header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(SignatureHeader, Sign("old", now, body)+" "+Sign("secret", now, body))
+	assert.NoError(Verify("secret", header, body, now, DefaultTolerance))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(5*time.Second, ExponentialBackoff(1))
+	assert.Equal(10*time.Second, ExponentialBackoff(2))
+	assert.Equal(40*time.Second, ExponentialBackoff(4))
+	assert.Equal(time.Hour, ExponentialBackoff(20))
+}
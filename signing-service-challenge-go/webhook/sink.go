@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/domain"
+	"github.com/fiskaly/coding-challenges/signing-service-challenge/persistence"
+	"github.com/google/uuid"
+)
+
+// Sink is an outbox sink enqueueing a delivery for every webhook subscribed to an event.
+// The deliveries are sent by the [Dispatcher], so a slow receiver doesn't hold up the outbox.
+type Sink struct {
+	storage persistence.Storage
+}
+
+func NewSink(storage persistence.Storage) *Sink {
+	return &Sink{
+		storage: storage,
+	}
+}
+
+func (s *Sink) Name() string {
+	return "webhook"
+}
+
+func (s *Sink) Deliver(ctx context.Context, event *domain.Event) error {
+	return s.storage.WithTransaction(ctx, func(ctx context.Context, storage persistence.Storage) error {
+		webhooks, err := storage.Webhooks().List(ctx, event.OrganizationId)
+		if err != nil {
+			slog.Error("failed fetching webhooks", "error", err)
+			return err
+		}
+
+		for _, webhook := range webhooks {
+			if !webhook.Subscribed(event.Type) {
+				continue
+			}
+			err := storage.WebhookDeliveries().Create(ctx, &domain.WebhookDelivery{
+				// the id is derived from the event, so an event delivered twice by the outbox is enqueued once
+				Id:             uuid.NewSHA1(event.Id, webhook.Id[:]),
+				OrganizationId: event.OrganizationId,
+				WebhookId:      webhook.Id,
+				Event:          *event,
+				Status:         domain.DeliveryStatusPending,
+				NextAttemptAt:  event.CreatedAt,
+			})
+			if err != nil && !errors.Is(err, persistence.ErrAlreadyExists) {
+				slog.Error("enqueueing webhook delivery failed", "error", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
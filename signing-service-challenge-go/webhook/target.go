@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateTarget is returned for webhook targets which aren't public, e.g. loopback, private or link-local addresses.
+// They are refused unless allowed, otherwise webhooks could be used to reach internal services.
+var ErrPrivateTarget = errors.New("url must not point to a private, loopback or link-local address")
+
+// sharedAddressSpace is used for carrier-grade NAT and internally by some cloud providers
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddress tells whether webhooks may be delivered to the address
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// ValidateTarget refuses urls whose host is localhost or an address which isn't public.
+// Host names are only resolved on delivery, the client of the [Dispatcher] checks the addresses they resolve to.
+func ValidateTarget(target string) error {
+	endpoint, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	host := strings.ToLower(strings.TrimSuffix(endpoint.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !PublicAddress(addr) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// refusePrivate is a dialer control refusing connections to addresses which aren't public.
+// It runs after the host name was resolved, so names resolving to internal addresses are refused as well.
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !PublicAddress(addrPort.Addr()) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// newClient returns the client deliveries are posted with, it refuses private targets unless they are allowed
+func newClient(privateTargets bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !privateTargets {
+		dialer.Control = refusePrivate
+		// a proxy would connect to the target on behalf of the dispatcher, without the address being checked
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: DefaultTimeout, Transport: transport}
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTarget(t *testing.T) {
+	assert := require.New(t)
+
+	for _, target := range []string{
+		"https://example.com/hook",
+		"https://93.184.215.14/hook",
+		"http://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:8080/hook",
+	} {
+		assert.NoError(ValidateTarget(target), target)
+	}
+
+	for _, target := range []string{
+		"http://localhost:8080/hook",
+		"http://api.localhost./hook",
+		"http://127.0.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://10.1.2.3/hook",
+		"http://172.16.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://100.100.100.200/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fe80::1%25eth0]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:10.0.0.1]/hook",
+		"http://224.0.0.1/hook",
+	} {
+		assert.ErrorIs(ValidateTarget(target), ErrPrivateTarget, target)
+	}
+}